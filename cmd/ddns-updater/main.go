@@ -0,0 +1,153 @@
+// Command ddns-updater keeps A and AAAA rrsets of a host with a changing public address
+// up to date in a zone of the Selectel Domains API V2.
+//
+// The address is detected by an echo endpoint over HTTP, a local network interface
+// or the output of a command. The status of the updater is served as JSON with -status.
+//
+// Usage:
+//
+//	SELECTEL_TOKEN=... ddns-updater -zone example.com -host office -ipv6 \
+//	  -source http -url4 https://api.ipify.org -url6 https://api6.ipify.org \
+//	  -debounce 10m -status 127.0.0.1:8053
+//	SELECTEL_TOKEN=... ddns-updater -zone example.com -host lab -source interface -interface eth0
+//	SELECTEL_TOKEN=... ddns-updater -zone example.com -host lab -source command -command "ip -brief addr show ppp0"
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/ddns"
+)
+
+const (
+	defaultEndpoint = "https://api.selectel.ru/domains/v2"
+	userAgent       = "domains-go/ddns-updater"
+)
+
+func main() {
+	endpoint := flag.String("endpoint", defaultEndpoint, "Domains API V2 endpoint")
+	zoneName := flag.String("zone", "", "name of the zone of the host")
+	host := flag.String("host", "", "name of the host, relative to the zone or absolute")
+	ipv4 := flag.Bool("ipv4", true, "keep the A rrset up to date")
+	ipv6 := flag.Bool("ipv6", false, "keep the AAAA rrset up to date")
+	sourceName := flag.String("source", "http", "address source: http, interface or command")
+	url4 := flag.String("url4", "https://api.ipify.org", "echo endpoint for IPv4 addresses of the http source")
+	url6 := flag.String("url6", "https://api6.ipify.org", "echo endpoint for IPv6 addresses of the http source")
+	iface := flag.String("interface", "", "network interface of the interface source")
+	command := flag.String("command", "", "command line of the command source")
+	ttl := flag.Int("ttl", 0, "TTL of rrsets, TTLs of existing rrsets are kept if 0")
+	interval := flag.Duration("interval", 5*time.Minute, "delay between checks")
+	debounce := flag.Duration("debounce", 0, "how long a changed address has to be seen before updating")
+	maxBackoff := flag.Duration("max-backoff", 30*time.Minute, "upper limit of the delay after failed checks, no limit if 0")
+	statusAddr := flag.String("status", "", "address to serve the status on, e.g. 127.0.0.1:8053")
+	once := flag.Bool("once", false, "check once and exit")
+	flag.Parse()
+
+	token := os.Getenv("SELECTEL_TOKEN")
+	if token == "" {
+		log.Fatal("SELECTEL_TOKEN environment variable is required")
+	}
+	if *zoneName == "" || *host == "" {
+		log.Fatal("-zone and -host are required")
+	}
+	source, err := newSource(*sourceName, *url4, *url6, *iface, *command)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var types []v2.RecordType
+	if *ipv4 {
+		types = append(types, v2.A)
+	}
+	if *ipv6 {
+		types = append(types, v2.AAAA)
+	}
+	if len(types) == 0 {
+		log.Fatal("at least one of -ipv4 and -ipv6 is required")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	headers := http.Header{}
+	headers.Add("X-Auth-Token", token)
+	headers.Add("User-Agent", userAgent)
+	client := v2.NewClient(*endpoint, &http.Client{}, headers)
+	zone, err := findZone(ctx, client, *zoneName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	updater := ddns.NewUpdater(client, source, zone.ID, zone.FQDN(*host))
+	updater.Types = types
+	updater.TTL = *ttl
+	updater.Interval = *interval
+	updater.Debounce = *debounce
+	updater.MaxBackoff = *maxBackoff
+	updater.ErrorLog = log.Default()
+
+	if *once {
+		if err := updater.Check(ctx); err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+	if *statusAddr != "" {
+		server := &http.Server{Addr: *statusAddr, Handler: updater, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatal(err)
+			}
+		}()
+		defer server.Close()
+	}
+	log.Printf("keeping %s %v up to date every %s", zone.FQDN(*host), types, *interval)
+	updater.Run(ctx)
+}
+
+func newSource(name, url4, url6, iface, command string) (ddns.Source, error) {
+	switch name {
+	case "http":
+		return &ddns.HTTPSource{IPv4URL: url4, IPv6URL: url6, Client: &http.Client{Timeout: 30 * time.Second}}, nil
+	case "interface":
+		if iface == "" {
+			return nil, errors.New("-interface is required for the interface source")
+		}
+
+		return &ddns.InterfaceSource{Name: iface}, nil
+	case "command":
+		fields := strings.Fields(command)
+		if len(fields) == 0 {
+			return nil, errors.New("-command is required for the command source")
+		}
+
+		return &ddns.CommandSource{Name: fields[0], Args: fields[1:]}, nil
+	default:
+		return nil, fmt.Errorf("unknown source %q", name)
+	}
+}
+
+func findZone(ctx context.Context, client v2.DNSClient[v2.Zone, v2.RRSet], name string) (*v2.Zone, error) {
+	zones, err := v2.ListAllZones[v2.Zone](ctx, client, &map[string]string{"filter": strings.TrimSuffix(name, ".")})
+	if err != nil {
+		return nil, fmt.Errorf("list zones: %w", err)
+	}
+	for _, zone := range zones {
+		if v2.NormalizeName(zone.Name) == v2.NormalizeName(name) {
+			return zone, nil
+		}
+	}
+
+	return nil, fmt.Errorf("zone %s not found", name)
+}
@@ -0,0 +1,134 @@
+// Command dnsupdate-gateway serves RFC 2136 dynamic DNS updates for zones
+// managed through the Selectel Domains API V2.
+//
+// Usage:
+//
+//	SELECTEL_TOKEN=... dnsupdate-gateway \
+//	  -listen :53 \
+//	  -zone example.com.=<zone id> \
+//	  -tsig ddns-key.:hmac-sha256:<base64 secret>
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/miekg/dns"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/dnsupdate"
+)
+
+const (
+	defaultEndpoint = "https://api.selectel.ru/domains/v2"
+	userAgent       = "domains-go/dnsupdate-gateway"
+)
+
+// listFlag collects values of a flag that can be repeated.
+type listFlag []string
+
+func (f *listFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *listFlag) Set(value string) error {
+	*f = append(*f, value)
+
+	return nil
+}
+
+func main() {
+	var zoneFlags, tsigFlags listFlag
+	listen := flag.String("listen", ":53", "address to serve DNS on (UDP and TCP)")
+	endpoint := flag.String("endpoint", defaultEndpoint, "Domains API V2 endpoint")
+	requireTSIG := flag.Bool("require-tsig", true, "refuse updates without a TSIG signature")
+	flag.Var(&zoneFlags, "zone", "zone to serve in name=zone-id form, can be repeated")
+	flag.Var(&tsigFlags, "tsig", "TSIG key in name:algorithm:secret form, can be repeated")
+	flag.Parse()
+
+	token := os.Getenv("SELECTEL_TOKEN")
+	if token == "" {
+		log.Fatal("SELECTEL_TOKEN environment variable is required")
+	}
+	zones, err := parseZones(zoneFlags)
+	if err != nil {
+		log.Fatal(err)
+	}
+	secrets, keyNames, err := parseTSIGKeys(tsigFlags)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	headers := http.Header{}
+	headers.Add("X-Auth-Token", token)
+	headers.Add("User-Agent", userAgent)
+	client := v2.NewClient(*endpoint, &http.Client{}, headers)
+
+	gateway := dnsupdate.NewGateway(client, zones)
+	gateway.RequireTSIG = *requireTSIG
+	gateway.ErrorLog = log.Default()
+
+	errs := make(chan error, 2)
+	for _, network := range []string{"udp", "tcp"} {
+		server := &dns.Server{
+			Addr:          *listen,
+			Net:           network,
+			Handler:       gateway,
+			MsgAcceptFunc: dnsupdate.AcceptFunc,
+			TsigSecret:    secrets,
+		}
+		go func() {
+			errs <- server.ListenAndServe()
+		}()
+	}
+	log.Printf("serving dynamic updates for %d zones on %s (TSIG keys: %s)",
+		len(zones), *listen, strings.Join(keyNames, ", "))
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case err := <-errs:
+		log.Fatal(err)
+	case <-signals:
+	}
+}
+
+func parseZones(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("at least one -zone is required")
+	}
+	zones := make(map[string]string, len(values))
+	for _, value := range values {
+		name, zoneID, ok := strings.Cut(value, "=")
+		if !ok || name == "" || zoneID == "" {
+			return nil, fmt.Errorf("invalid -zone %q, expected name=zone-id", value)
+		}
+		zones[dns.Fqdn(name)] = zoneID
+	}
+
+	return zones, nil
+}
+
+func parseTSIGKeys(values []string) (map[string]string, []string, error) {
+	secrets := make(map[string]string, len(values))
+	names := make([]string, 0, len(values))
+	for _, value := range values {
+		parts := strings.SplitN(value, ":", 3)
+		if len(parts) != 3 {
+			return nil, nil, fmt.Errorf("invalid -tsig %q, expected name:algorithm:secret", value)
+		}
+		if algorithm := dns.Fqdn(strings.ToLower(parts[1])); algorithm != dns.HmacSHA256 &&
+			algorithm != dns.HmacSHA512 && algorithm != dns.HmacSHA1 && algorithm != dns.HmacSHA384 {
+			return nil, nil, fmt.Errorf("unsupported TSIG algorithm %q", parts[1])
+		}
+		secrets[dns.Fqdn(parts[0])] = parts[2]
+		names = append(names, dns.Fqdn(parts[0])+" ("+parts[1]+")")
+	}
+
+	return secrets, names, nil
+}
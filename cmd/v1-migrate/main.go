@@ -0,0 +1,106 @@
+// Command v1-migrate moves domains and records of the legacy Selectel Domains API V1
+// to zones and rrsets of the Selectel Domains API V2.
+//
+// By default the migration plan is printed as JSON and nothing is changed.
+//
+// Usage:
+//
+//	SELECTEL_V1_TOKEN=... SELECTEL_TOKEN=... v1-migrate \
+//	  -domain example.com \
+//	  -apply
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/selectel/domains-go/pkg/migrate"
+	v1 "github.com/selectel/domains-go/pkg/v1"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+)
+
+const (
+	defaultV1Endpoint = "https://api.selectel.ru/domains/v1"
+	defaultEndpoint   = "https://api.selectel.ru/domains/v2"
+	userAgent         = "domains-go/v1-migrate"
+)
+
+// listFlag collects values of a flag that can be repeated.
+type listFlag []string
+
+func (f *listFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *listFlag) Set(value string) error {
+	*f = append(*f, value)
+
+	return nil
+}
+
+func main() {
+	var domains listFlag
+	v1Endpoint := flag.String("v1-endpoint", defaultV1Endpoint, "Domains API V1 endpoint")
+	endpoint := flag.String("endpoint", defaultEndpoint, "Domains API V2 endpoint")
+	apply := flag.Bool("apply", false, "apply the plan instead of printing it")
+	overwrite := flag.Bool("overwrite", false, "update existing V2 rrsets that differ from the plan")
+	force := flag.Bool("force", false, "apply zones that have conflicts")
+	flag.Var(&domains, "domain", "domain to migrate, can be repeated; all domains are migrated if omitted")
+	flag.Parse()
+
+	v1Token := os.Getenv("SELECTEL_V1_TOKEN")
+	if v1Token == "" {
+		log.Fatal("SELECTEL_V1_TOKEN environment variable is required")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	v1Client := v1.NewDomainsClientV1(v1Token, *v1Endpoint)
+	plan, err := migrate.NewPlan(ctx, v1Client, domains)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, zone := range plan.Zones {
+		for _, conflict := range zone.Conflicts {
+			log.Printf("%s: %s", zone.Name, conflict)
+		}
+	}
+	if !*apply {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(plan); err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	token := os.Getenv("SELECTEL_TOKEN")
+	if token == "" {
+		log.Fatal("SELECTEL_TOKEN environment variable is required to apply the plan")
+	}
+	headers := http.Header{}
+	headers.Add("X-Auth-Token", token)
+	headers.Add("User-Agent", userAgent)
+	client := v2.NewClient(*endpoint, &http.Client{}, headers)
+
+	report, err := migrate.Apply(ctx, client, plan, &migrate.ApplyOpts{Overwrite: *overwrite, Force: *force})
+	if report != nil {
+		for _, zone := range report.Zones {
+			fmt.Printf("%s: created=%t rrsets created=%d updated=%d unchanged=%d skipped=%d\n",
+				zone.Name, zone.Created, zone.RRSetsCreated, zone.RRSetsUpdated, zone.RRSetsUnchanged, len(zone.Skipped))
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,153 @@
+// Command zone-lint checks rrsets of a zone of the Selectel Domains API V2 for common DNS mistakes.
+//
+// The zone is read from the API or, offline, from a zone file of a backup snapshot.
+// The command exits with status 1 when findings reach the -fail-on severity.
+//
+// Usage:
+//
+//	SELECTEL_TOKEN=... zone-lint -zone example.com -format json
+//	zone-lint -file backups/20260101T000000.000Z/example.com.json \
+//	  -rule ttl-outlier=off \
+//	  -rule disabled-only=error
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/backup"
+	"github.com/selectel/domains-go/pkg/v2/lint"
+)
+
+const (
+	defaultEndpoint = "https://api.selectel.ru/domains/v2"
+	userAgent       = "domains-go/zone-lint"
+)
+
+// ruleFlag collects rule=severity overrides of a flag that can be repeated.
+type ruleFlag map[string]lint.Severity
+
+func (f ruleFlag) String() string {
+	parts := make([]string, 0, len(f))
+	for id, severity := range f {
+		parts = append(parts, id+"="+severity.String())
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func (f ruleFlag) Set(value string) error {
+	id, name, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("rule=severity expected, got %q", value)
+	}
+	severity, err := lint.ParseSeverity(name)
+	if err != nil {
+		return err
+	}
+	f[id] = severity
+
+	return nil
+}
+
+func main() {
+	rules := ruleFlag{}
+	endpoint := flag.String("endpoint", defaultEndpoint, "Domains API V2 endpoint")
+	zoneName := flag.String("zone", "", "name of the zone to read from the API")
+	file := flag.String("file", "", "zone file of a backup snapshot to read instead of the API")
+	format := flag.String("format", "text", "output format: text or json")
+	failOn := flag.String("fail-on", "error", "lowest severity that fails the check: info, warning or error")
+	ttlFactor := flag.Float64("ttl-factor", 0, "how many times a TTL may differ from the zone median")
+	flag.Var(rules, "rule", "rule=severity override, can be repeated; severity off disables the rule")
+	flag.Parse()
+
+	threshold, err := lint.ParseSeverity(*failOn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	name, rrsets, err := readZone(*endpoint, *zoneName, *file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	report, err := lint.Lint(name, rrsets, &lint.Config{Rules: rules, TTLOutlierFactor: *ttlFactor})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	case "text":
+		err = report.WriteText(os.Stdout)
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if report.Failed(threshold) {
+		os.Exit(1)
+	}
+}
+
+func readZone(endpoint, zoneName, file string) (string, []*v2.RRSet, error) {
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", nil, fmt.Errorf("read zone file: %w", err)
+		}
+		var zoneBackup backup.ZoneBackup
+		if err := json.Unmarshal(data, &zoneBackup); err != nil {
+			return "", nil, fmt.Errorf("decode zone file: %w", err)
+		}
+		rrsets := make([]*v2.RRSet, 0, len(zoneBackup.RRSets))
+		for i := range zoneBackup.RRSets {
+			rrsets = append(rrsets, &zoneBackup.RRSets[i])
+		}
+
+		return zoneBackup.Zone.Name, rrsets, nil
+	}
+	if zoneName == "" {
+		return "", nil, errors.New("either -zone or -file is required")
+	}
+	token := os.Getenv("SELECTEL_TOKEN")
+	if token == "" {
+		return "", nil, errors.New("SELECTEL_TOKEN environment variable is required to read the zone from the API")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	headers := http.Header{}
+	headers.Add("X-Auth-Token", token)
+	headers.Add("User-Agent", userAgent)
+	client := v2.NewClient(endpoint, &http.Client{}, headers)
+	zones, err := v2.ListAllZones[v2.Zone](ctx, client, &map[string]string{"filter": strings.TrimSuffix(zoneName, ".")})
+	if err != nil {
+		return "", nil, fmt.Errorf("list zones: %w", err)
+	}
+	for _, zone := range zones {
+		if strings.EqualFold(strings.TrimSuffix(zone.Name, "."), strings.TrimSuffix(zoneName, ".")) {
+			rrsets, err := v2.ListAllRRSets[v2.RRSet](ctx, client, zone.ID, nil)
+			if err != nil {
+				return "", nil, fmt.Errorf("list rrsets: %w", err)
+			}
+
+			return zone.Name, rrsets, nil
+		}
+	}
+
+	return "", nil, fmt.Errorf("zone %s not found", zoneName)
+}
@@ -2,11 +2,19 @@ module github.com/selectel/domains-go
 
 go 1.20
 
+require (
+	github.com/jarcoal/httpmock v1.3.1
+	github.com/miekg/dns v1.1.58
+	github.com/stretchr/testify v1.8.4
+	golang.org/x/net v0.20.0
+)
+
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/jarcoal/httpmock v1.3.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/stretchr/objx v0.5.0 // indirect
-	github.com/stretchr/testify v1.8.4 // indirect
+	golang.org/x/mod v0.14.0 // indirect
+	golang.org/x/sys v0.16.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	golang.org/x/tools v0.17.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,18 @@
+/*
+Package idn converts internationalized domain names between Unicode and the
+ASCII Compatible Encoding under IDNA2008 rules.
+
+The v1 and v2 clients convert names and record targets to ASCII before
+sending them, so Cyrillic names such as "пример.рф" can be passed as is, and
+return API data in ASCII with accessor methods for the Unicode forms.
+
+Example of converting a name
+
+  ascii, err := idn.ToASCII("почта.пример.рф.")
+  if err != nil {
+    log.Fatal(err)
+  }
+  fmt.Println(ascii)                // xn--80a1acny.xn--e1afmkfd.xn--p1ai.
+  fmt.Println(idn.ToUnicode(ascii)) // почта.пример.рф.
+*/
+package idn
@@ -0,0 +1,140 @@
+package idn
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// acePrefix represents the prefix of ASCII Compatible Encoding labels.
+const acePrefix = "xn--"
+
+var ErrInvalidName = errors.New("invalid internationalized domain name")
+
+// Labels are mapped as user input is, e.g. lowercased, by the lookup profile
+// and then checked by the registration profile and against the code points
+// IDNA2008 allows, which rules out symbols such as emoji UTS #46 accepts.
+var (
+	lookupProfile = idna.New(
+		idna.MapForLookup(),
+		idna.BidiRule(),
+		idna.Transitional(false),
+		idna.StrictDomainName(false),
+	)
+	registrationProfile = idna.New(
+		idna.ValidateForRegistration(),
+		idna.StrictDomainName(false),
+	)
+)
+
+// ToASCII converts Unicode labels of the name to ACE labels, e.g. "пример.рф." to "xn--e1afmkfd.xn--p1ai.".
+// ASCII labels are kept as is, except for ACE labels, which are validated and lowercased.
+// The trailing dot and a leading wildcard label are preserved.
+func ToASCII(name string) (string, error) {
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		switch {
+		case label == "" || label == "*":
+		case !isASCII(label) || hasACEPrefix(label):
+			ascii, err := labelToASCII(label)
+			if err != nil {
+				return "", fmt.Errorf("%w: %s: %w", ErrInvalidName, name, err)
+			}
+			labels[i] = ascii
+		}
+	}
+
+	return strings.Join(labels, "."), nil
+}
+
+// ToUnicode converts ACE labels of the name to Unicode, e.g. "xn--e1afmkfd.xn--p1ai." to "пример.рф.".
+// Labels that are not valid ACE labels are kept as is.
+func ToUnicode(name string) string {
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		if !hasACEPrefix(label) {
+			continue
+		}
+		if converted, err := lookupProfile.ToUnicode(label); err == nil {
+			labels[i] = converted
+		}
+	}
+
+	return strings.Join(labels, ".")
+}
+
+// Validate checks that the name can be converted to ASCII.
+func Validate(name string) error {
+	_, err := ToASCII(name)
+
+	return err
+}
+
+// ContentToASCII converts the target name of CNAME, ALIAS, NS, MX and SRV record content
+// in the presentation format, e.g. "10 mail.пример.рф.", to ASCII. Content of other types is kept as is.
+func ContentToASCII(recordType, content string) (string, error) {
+	return convertTarget(recordType, content, ToASCII)
+}
+
+// ContentToUnicode converts the target name of CNAME, ALIAS, NS, MX and SRV record content to Unicode.
+func ContentToUnicode(recordType, content string) string {
+	converted, _ := convertTarget(recordType, content, func(name string) (string, error) {
+		return ToUnicode(name), nil
+	})
+
+	return converted
+}
+
+// convertTarget applies convert to the target field of the content.
+func convertTarget(recordType, content string, convert func(string) (string, error)) (string, error) {
+	position := map[string]int{"CNAME": 0, "ALIAS": 0, "NS": 0, "MX": 1, "SRV": 3}
+	i, ok := position[strings.ToUpper(recordType)]
+	fields := strings.Fields(content)
+	if !ok || len(fields) <= i {
+		return content, nil
+	}
+	target, err := convert(fields[i])
+	if err != nil {
+		return "", err
+	}
+	fields[i] = target
+
+	return strings.Join(fields, " "), nil
+}
+
+// labelToASCII maps and validates a Unicode or ACE label and returns its ACE form.
+func labelToASCII(label string) (string, error) {
+	mapped, err := lookupProfile.ToUnicode(label)
+	if err != nil {
+		return "", fmt.Errorf("map label: %w", err)
+	}
+	for _, r := range mapped {
+		if r != '-' && !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r) {
+			return "", fmt.Errorf("code point %U is not allowed by IDNA2008", r)
+		}
+	}
+	ascii, err := registrationProfile.ToASCII(mapped)
+	if err != nil {
+		return "", fmt.Errorf("validate label: %w", err)
+	}
+
+	return ascii, nil
+}
+
+func hasACEPrefix(label string) bool {
+	return len(label) >= len(acePrefix) && strings.EqualFold(label[:len(acePrefix)], acePrefix)
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
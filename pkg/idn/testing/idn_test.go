@@ -0,0 +1,69 @@
+package testing
+
+import (
+	"testing"
+
+	"github.com/selectel/domains-go/pkg/idn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToASCII(t *testing.T) {
+	t.Parallel()
+	cases := map[string]string{
+		"почта.пример.рф.":       "xn--80a1acny.xn--e1afmkfd.xn--p1ai.",
+		"Пример.РФ":              "xn--e1afmkfd.xn--p1ai",
+		"*.пример.рф.":           "*.xn--e1afmkfd.xn--p1ai.",
+		"_dmarc.пример.рф.":      "_dmarc.xn--e1afmkfd.xn--p1ai.",
+		"XN--E1AFMKFD.xn--p1ai.": "xn--e1afmkfd.xn--p1ai.",
+		"Example.com.":           "Example.com.",
+		"":                       "",
+	}
+	for name, expected := range cases {
+		actual, err := idn.ToASCII(name)
+
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, actual, name)
+	}
+}
+
+func TestToASCII_invalid(t *testing.T) {
+	t.Parallel()
+	for _, name := range []string{"☃.example.com.", "😀.рф", "xn--a.example.com."} {
+		_, err := idn.ToASCII(name)
+
+		assert.ErrorIs(t, err, idn.ErrInvalidName, name)
+		assert.ErrorIs(t, idn.Validate(name), idn.ErrInvalidName, name)
+	}
+}
+
+func TestToUnicode(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "почта.пример.рф.", idn.ToUnicode("xn--80a1acny.xn--e1afmkfd.xn--p1ai."))
+	assert.Equal(t, "xn--a.example.com.", idn.ToUnicode("xn--a.example.com."))
+	assert.Equal(t, "example.com", idn.ToUnicode("example.com"))
+}
+
+func TestContent(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		recordType string
+		unicode    string
+		ascii      string
+	}{
+		{"CNAME", "www.пример.рф.", "www.xn--e1afmkfd.xn--p1ai."},
+		{"MX", "10 почта.пример.рф.", "10 xn--80a1acny.xn--e1afmkfd.xn--p1ai."},
+		{"SRV", "10 5 5060 sip.пример.рф.", "10 5 5060 sip.xn--e1afmkfd.xn--p1ai."},
+		{"TXT", `"пример.рф"`, `"пример.рф"`},
+	}
+	for _, c := range cases {
+		ascii, err := idn.ContentToASCII(c.recordType, c.unicode)
+
+		require.NoError(t, err, c.recordType)
+		assert.Equal(t, c.ascii, ascii, c.recordType)
+		assert.Equal(t, c.unicode, idn.ContentToUnicode(c.recordType, ascii), c.recordType)
+	}
+
+	_, err := idn.ContentToASCII("NS", "☃.example.com.")
+	assert.ErrorIs(t, err, idn.ErrInvalidName)
+}
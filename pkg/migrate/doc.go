@@ -0,0 +1,26 @@
+/*
+Package migrate moves domains and records of the Selectel Domains API V1
+to zones and rrsets of the Selectel Domains API V2.
+
+A migration is done in two steps: NewPlan reads V1 data and converts it,
+Apply creates the planned zones and rrsets. Plans can be marshalled to JSON
+to be reviewed before they are applied.
+
+Example of migrating a single domain
+
+  plan, err := migrate.NewPlan(ctx, v1Client, []string{"example.com"})
+  if err != nil {
+    log.Fatal(err)
+  }
+  for _, zone := range plan.Zones {
+    for _, conflict := range zone.Conflicts {
+      fmt.Println(conflict)
+    }
+  }
+  report, err := migrate.Apply(ctx, v2Client, plan, &migrate.ApplyOpts{Overwrite: false, Force: false})
+  if err != nil {
+    log.Fatal(err)
+  }
+  fmt.Printf("%+v\n", report)
+*/
+package migrate
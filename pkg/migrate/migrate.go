@@ -0,0 +1,197 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	v1 "github.com/selectel/domains-go/pkg/v1"
+	"github.com/selectel/domains-go/pkg/v1/domain"
+	"github.com/selectel/domains-go/pkg/v1/record"
+	"github.com/selectel/domains-go/pkg/v1/v2compat"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+)
+
+var ErrConflicts = errors.New("zone plan has conflicts")
+
+type (
+	// Plan describes V2 zones and rrsets built from V1 domains and records.
+	Plan struct {
+		Zones []ZonePlan `json:"zones"`
+	}
+
+	// ZonePlan describes a single zone of a plan.
+	ZonePlan struct {
+		DomainID  int                 `json:"domain_id"`
+		Name      string              `json:"name"`
+		RRSets    []v2.RRSet          `json:"rrsets"`
+		Conflicts []v2compat.Conflict `json:"conflicts,omitempty"`
+	}
+
+	// ApplyOpts represents options of applying a plan.
+	ApplyOpts struct {
+		// Overwrite updates existing rrsets whose contents differ from the plan.
+		// Such rrsets are left untouched and reported as skipped otherwise.
+		Overwrite bool
+
+		// Force applies zones that have conflicts.
+		// Zones with conflicts are skipped with ErrConflicts otherwise.
+		Force bool
+	}
+
+	// ApplyReport describes changes made by applying a plan.
+	ApplyReport struct {
+		Zones []ZoneReport
+	}
+
+	// ZoneReport describes changes made to a single zone.
+	ZoneReport struct {
+		Name            string
+		ZoneID          string
+		Created         bool
+		RRSetsCreated   int
+		RRSetsUpdated   int
+		RRSetsUnchanged int
+		// Skipped lists existing rrsets that differ from the plan and weren't overwritten.
+		Skipped []string
+		Err     error
+	}
+)
+
+// NewPlan reads the domains and their records through the V1 API and groups them into V2 rrsets.
+// All domains are read if domainNames is empty.
+func NewPlan(ctx context.Context, client *v1.ServiceClient, domainNames []string) (*Plan, error) {
+	var domains []*domain.View
+	if len(domainNames) == 0 {
+		var err error
+		domains, _, err = domain.List(ctx, client)
+		if err != nil {
+			return nil, fmt.Errorf("list domains: %w", err)
+		}
+	}
+	for _, name := range domainNames {
+		view, _, err := domain.GetByName(ctx, client, name)
+		if err != nil {
+			return nil, fmt.Errorf("get domain %s: %w", name, err)
+		}
+		domains = append(domains, view)
+	}
+
+	plan := &Plan{Zones: make([]ZonePlan, 0, len(domains))}
+	for _, view := range domains {
+		records, _, err := record.ListByDomainID(ctx, client, view.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list records of domain %s: %w", view.Name, err)
+		}
+		rrsets, conflicts := v2compat.Group(records)
+		zonePlan := ZonePlan{
+			DomainID:  view.ID,
+			Name:      v2compat.Name(view.Name),
+			RRSets:    make([]v2.RRSet, 0, len(rrsets)),
+			Conflicts: conflicts,
+		}
+		for _, rrset := range rrsets {
+			zonePlan.RRSets = append(zonePlan.RRSets, *rrset)
+		}
+		plan.Zones = append(plan.Zones, zonePlan)
+	}
+
+	return plan, nil
+}
+
+// Apply creates zones and rrsets of the plan through the V2 API.
+// Existing zones are reused and existing rrsets are matched by name and type.
+// A failure in one zone doesn't stop others from being applied,
+// errors of all zones are returned joined and are also available in the report.
+func Apply(
+	ctx context.Context, manager v2.DNSManager[v2.Zone, v2.RRSet], plan *Plan, opts *ApplyOpts,
+) (*ApplyReport, error) {
+	if opts == nil {
+		opts = &ApplyOpts{Overwrite: false, Force: false}
+	}
+	zones, err := v2.ListAllZones[v2.Zone](ctx, manager, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list zones: %w", err)
+	}
+	existing := make(map[string]*v2.Zone, len(zones))
+	for _, zone := range zones {
+		existing[v2compat.Name(zone.Name)] = zone
+	}
+
+	report := &ApplyReport{Zones: make([]ZoneReport, 0, len(plan.Zones))}
+	var errs []error
+	for i := range plan.Zones {
+		zonePlan := &plan.Zones[i]
+		//nolint: exhaustruct
+		zoneReport := ZoneReport{Name: zonePlan.Name}
+		if len(zonePlan.Conflicts) > 0 && !opts.Force {
+			err = fmt.Errorf("%w: %d conflicts", ErrConflicts, len(zonePlan.Conflicts))
+		} else {
+			err = applyZone(ctx, manager, zonePlan, existing[v2compat.Name(zonePlan.Name)], opts, &zoneReport)
+		}
+		if err != nil {
+			zoneReport.Err = err
+			errs = append(errs, fmt.Errorf("zone %s: %w", zonePlan.Name, err))
+		}
+		report.Zones = append(report.Zones, zoneReport)
+	}
+
+	return report, errors.Join(errs...)
+}
+
+func applyZone(
+	ctx context.Context,
+	manager v2.DNSManager[v2.Zone, v2.RRSet],
+	zonePlan *ZonePlan,
+	zone *v2.Zone,
+	opts *ApplyOpts,
+	report *ZoneReport,
+) error {
+	if zone == nil {
+		//nolint: exhaustruct
+		created, err := manager.CreateZone(ctx, &v2.Zone{Name: zonePlan.Name})
+		if err != nil {
+			return fmt.Errorf("create zone: %w", err)
+		}
+		zone = created
+		report.Created = true
+	}
+	report.ZoneID = zone.ID
+
+	current, err := v2.ListAllRRSets[v2.RRSet](ctx, manager, zone.ID, nil)
+	if err != nil {
+		return fmt.Errorf("list rrsets: %w", err)
+	}
+	currentIndex := make(map[string]*v2.RRSet, len(current))
+	for _, rrset := range current {
+		currentIndex[rrsetKey(rrset)] = rrset
+	}
+
+	for i := range zonePlan.RRSets {
+		rrset := &zonePlan.RRSets[i]
+		existing, ok := currentIndex[rrsetKey(rrset)]
+		switch {
+		case !ok:
+			if _, err := manager.CreateRRSet(ctx, zone.ID, rrset); err != nil {
+				return fmt.Errorf("create rrset %s %s: %w", rrset.Name, rrset.Type, err)
+			}
+			report.RRSetsCreated++
+		case existing.TTL == rrset.TTL && reflect.DeepEqual(existing.Records, rrset.Records):
+			report.RRSetsUnchanged++
+		case !opts.Overwrite:
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s %s", rrset.Name, rrset.Type))
+		default:
+			if err := manager.UpdateRRSet(ctx, zone.ID, existing.ID, rrset); err != nil {
+				return fmt.Errorf("update rrset %s %s: %w", rrset.Name, rrset.Type, err)
+			}
+			report.RRSetsUpdated++
+		}
+	}
+
+	return nil
+}
+
+func rrsetKey(rrset *v2.RRSet) string {
+	return v2compat.Name(rrset.Name) + " " + string(rrset.Type)
+}
@@ -0,0 +1,160 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/selectel/domains-go/pkg/migrate"
+	"github.com/selectel/domains-go/pkg/testutils"
+	v1 "github.com/selectel/domains-go/pkg/v1"
+	"github.com/selectel/domains-go/pkg/v1/v2compat"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testDomainID   = 123
+	testDomainName = "testdomain.xyz"
+)
+
+const testDomainResponseRaw = `
+{
+   "id" : 123,
+   "name" : "testdomain.xyz"
+}
+`
+
+const testRecordsResponseRaw = `
+[
+   {"id": 1, "type": "NS", "name": "testdomain.xyz", "ttl": 86400, "content": "ns1.selectel.org"},
+   {"id": 2, "type": "SOA", "name": "testdomain.xyz", "ttl": 86400, "content": "ns1.selectel.org"},
+   {"id": 3, "type": "A", "name": "www.testdomain.xyz", "ttl": 60, "content": "10.0.0.1"},
+   {"id": 4, "type": "A", "name": "www.testdomain.xyz", "ttl": 60, "content": "10.0.0.2"},
+   {"id": 5, "type": "MX", "name": "testdomain.xyz", "ttl": 60, "content": "mx.testdomain.xyz", "priority": 10}
+]
+`
+
+type (
+	MigrateSuite struct {
+		suite.Suite
+		env      *testutils.TestEnv
+		api      *testutils.FakeAPI
+		v1Client *v1.ServiceClient
+	}
+)
+
+//nolint:paralleltest
+func TestMigrate(t *testing.T) {
+	suite.Run(t, new(MigrateSuite))
+}
+
+func (s *MigrateSuite) SetupTest() {
+	s.env = testutils.SetupTestEnv()
+	s.api = testutils.NewFakeAPI()
+	s.v1Client = v1.NewDomainsClientV1(testutils.Token, s.env.Server.URL+"/v1")
+	called := false
+	testutils.HandleReqWithoutBody(s.T(), &testutils.HandleReqOpts{
+		Mux:         s.env.Mux,
+		URL:         "/v1/" + testDomainName,
+		RawResponse: testDomainResponseRaw,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &called,
+	})
+	testutils.HandleReqWithoutBody(s.T(), &testutils.HandleReqOpts{
+		Mux:         s.env.Mux,
+		URL:         fmt.Sprintf("/v1/%d/records/", testDomainID),
+		RawResponse: testRecordsResponseRaw,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &called,
+	})
+}
+
+func (s *MigrateSuite) TearDownTest() {
+	s.env.TearDownTestEnv()
+	s.api.Close()
+}
+
+func (s *MigrateSuite) newPlan() *migrate.Plan {
+	plan, err := migrate.NewPlan(context.Background(), s.v1Client, []string{testDomainName})
+	s.Require().NoError(err)
+
+	return plan
+}
+
+func (s *MigrateSuite) TestNewPlan() {
+	plan := s.newPlan()
+
+	s.Require().Len(plan.Zones, 1)
+	zone := plan.Zones[0]
+	s.Equal(testDomainID, zone.DomainID)
+	s.Equal("testdomain.xyz.", zone.Name)
+	s.Empty(zone.Conflicts)
+	s.Require().Len(zone.RRSets, 3)
+	s.Equal(v2.MX, zone.RRSets[0].Type)
+	s.Equal("10 mx.testdomain.xyz.", zone.RRSets[0].Records[0].Content)
+	s.Equal(v2.NS, zone.RRSets[1].Type)
+	s.Equal("www.testdomain.xyz.", zone.RRSets[2].Name)
+	s.Len(zone.RRSets[2].Records, 2)
+}
+
+func (s *MigrateSuite) TestApply_creates_zone() {
+	plan := s.newPlan()
+
+	report, err := migrate.Apply(context.Background(), s.api.Client(), plan, nil)
+
+	s.Require().NoError(err)
+	s.Require().Len(report.Zones, 1)
+	s.True(report.Zones[0].Created)
+	s.Equal(3, report.Zones[0].RRSetsCreated)
+	s.Len(s.api.RRSets(report.Zones[0].ZoneID), 3)
+
+	// Applying the plan again changes nothing.
+	report, err = migrate.Apply(context.Background(), s.api.Client(), plan, nil)
+
+	s.Require().NoError(err)
+	s.False(report.Zones[0].Created)
+	s.Equal(3, report.Zones[0].RRSetsUnchanged)
+}
+
+func (s *MigrateSuite) TestApply_skips_differing_rrsets_without_overwrite() {
+	zone := s.api.AddZone("testdomain.xyz.")
+	//nolint: exhaustruct
+	s.api.AddRRSet(zone.ID, v2.RRSet{
+		Name: "testdomain.xyz.", Type: v2.NS, TTL: 86400,
+		Records: []v2.RecordItem{{Content: "a.ns.selectel.ru.", Disabled: false}},
+	})
+	plan := s.newPlan()
+
+	report, err := migrate.Apply(context.Background(), s.api.Client(), plan, nil)
+
+	s.Require().NoError(err)
+	s.Equal([]string{"testdomain.xyz. NS"}, report.Zones[0].Skipped)
+	s.Equal(2, report.Zones[0].RRSetsCreated)
+
+	report, err = migrate.Apply(context.Background(), s.api.Client(), plan, &migrate.ApplyOpts{
+		Overwrite: true,
+		Force:     false,
+	})
+
+	s.Require().NoError(err)
+	s.Equal(1, report.Zones[0].RRSetsUpdated)
+}
+
+func (s *MigrateSuite) TestApply_refuses_conflicts() {
+	plan := s.newPlan()
+	plan.Zones[0].Conflicts = []v2compat.Conflict{{
+		Kind:   v2compat.ConflictTTL,
+		Name:   "www.testdomain.xyz.",
+		Type:   "A",
+		Detail: "records have TTLs 60 and 300, using the lowest",
+	}}
+
+	_, err := migrate.Apply(context.Background(), s.api.Client(), plan, nil)
+
+	s.ErrorIs(err, migrate.ErrConflicts)
+	s.Empty(s.api.Zones())
+}
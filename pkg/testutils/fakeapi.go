@@ -0,0 +1,487 @@
+package testutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	v2 "github.com/selectel/domains-go/pkg/v2"
+)
+
+// FaultFunc decides whether the fake API should fail the request.
+// It returns the HTTP status code to reply with or zero to serve the request normally.
+type FaultFunc func(r *http.Request) int
+
+// FakeAPI represents an in-memory implementation of the Selectel Domains API V2
+// that can be used to test code working with the v2 client.
+type FakeAPI struct {
+	// Server represents the HTTP server serving the fake API.
+	Server *httptest.Server
+
+	// PageSize limits the number of items returned by list requests.
+	// Zero means all items are returned at once.
+	PageSize int
+
+	mu       sync.Mutex
+	lastID   int
+	zones    []*v2.Zone
+	rrsets   map[string][]*v2.RRSet
+	faults   []FaultFunc
+	requests []string
+}
+
+// NewFakeAPI starts a new fake Domains API V2 server.
+func NewFakeAPI() *FakeAPI {
+	//nolint: exhaustruct
+	api := &FakeAPI{rrsets: make(map[string][]*v2.RRSet)}
+	api.Server = httptest.NewServer(http.HandlerFunc(api.serveHTTP))
+
+	return api
+}
+
+// Close shuts the fake API server down.
+func (api *FakeAPI) Close() {
+	api.Server.Close()
+}
+
+// Client returns a v2 client configured to work with the fake API.
+func (api *FakeAPI) Client() v2.DNSClient[v2.Zone, v2.RRSet] {
+	return v2.NewClient(api.Server.URL, api.Server.Client(), http.Header{})
+}
+
+// InjectFault registers a function that can fail incoming requests.
+func (api *FakeAPI) InjectFault(fault FaultFunc) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.faults = append(api.faults, fault)
+}
+
+// ClearFaults removes all registered faults.
+func (api *FakeAPI) ClearFaults() {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.faults = nil
+}
+
+// Requests returns "METHOD path" of every request served so far.
+func (api *FakeAPI) Requests() []string {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	return append([]string(nil), api.requests...)
+}
+
+// AddZone creates a zone in the fake API storage.
+func (api *FakeAPI) AddZone(name string) *v2.Zone {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	zone := *api.addZone(name)
+
+	return &zone
+}
+
+// AddRRSet creates an rrset of the zone in the fake API storage.
+func (api *FakeAPI) AddRRSet(zoneID string, rrset v2.RRSet) *v2.RRSet {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	created := api.addRRSet(zoneID, rrset)
+	result := *created
+
+	return &result
+}
+
+// NewRRSet returns an rrset with a TTL of 60 seconds and enabled records of the contents.
+func NewRRSet(name string, recordType v2.RecordType, contents ...string) *v2.RRSet {
+	//nolint: exhaustruct
+	rrset := &v2.RRSet{Name: name, Type: recordType, TTL: 60}
+	for _, content := range contents {
+		rrset.Records = append(rrset.Records, v2.RecordItem{Content: content, Disabled: false})
+	}
+
+	return rrset
+}
+
+// Zones returns copies of all stored zones.
+func (api *FakeAPI) Zones() []v2.Zone {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	zones := make([]v2.Zone, 0, len(api.zones))
+	for _, zone := range api.zones {
+		zones = append(zones, *zone)
+	}
+
+	return zones
+}
+
+// RRSets returns copies of all rrsets stored for the zone.
+func (api *FakeAPI) RRSets(zoneID string) []v2.RRSet {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	rrsets := make([]v2.RRSet, 0, len(api.rrsets[zoneID]))
+	for _, rrset := range api.rrsets[zoneID] {
+		rrsets = append(rrsets, copyRRSet(rrset))
+	}
+
+	return rrsets
+}
+
+func (api *FakeAPI) nextID() string {
+	api.lastID++
+
+	return fmt.Sprintf("00000000-0000-0000-0000-%012d", api.lastID)
+}
+
+func (api *FakeAPI) addZone(name string) *v2.Zone {
+	now := time.Now().UTC().Truncate(time.Second)
+	//nolint: exhaustruct
+	zone := &v2.Zone{
+		ID:        api.nextID(),
+		ProjectID: "fake-project",
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	api.zones = append(api.zones, zone)
+
+	return zone
+}
+
+func (api *FakeAPI) addRRSet(zoneID string, rrset v2.RRSet) *v2.RRSet {
+	created := copyRRSet(&rrset)
+	created.ID = api.nextID()
+	created.ZoneID = zoneID
+	api.rrsets[zoneID] = append(api.rrsets[zoneID], &created)
+
+	return &created
+}
+
+func (api *FakeAPI) findZone(zoneID string) *v2.Zone {
+	for _, zone := range api.zones {
+		if zone.ID == zoneID {
+			return zone
+		}
+	}
+
+	return nil
+}
+
+func (api *FakeAPI) findRRSet(zoneID, rrsetID string) *v2.RRSet {
+	for _, rrset := range api.rrsets[zoneID] {
+		if rrset.ID == rrsetID {
+			return rrset
+		}
+	}
+
+	return nil
+}
+
+func (api *FakeAPI) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.requests = append(api.requests, r.Method+" "+r.URL.Path)
+
+	for _, fault := range api.faults {
+		if status := fault(r); status != 0 {
+			writeFakeError(w, status, "injected_fault")
+
+			return
+		}
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) == 0 || parts[0] != "zones" {
+		writeFakeError(w, http.StatusNotFound, "not_found")
+
+		return
+	}
+	switch {
+	case len(parts) == 1:
+		api.serveZones(w, r)
+	case len(parts) == 2:
+		api.serveZone(w, r, parts[1])
+	case len(parts) == 3 && (parts[2] == "state" || parts[2] == "protection"):
+		api.serveZoneFlag(w, r, parts[1], parts[2])
+	case len(parts) == 3 && parts[2] == "rrset":
+		api.serveRRSets(w, r, parts[1])
+	case len(parts) == 4 && parts[2] == "rrset":
+		api.serveRRSet(w, r, parts[1], parts[3])
+	default:
+		writeFakeError(w, http.StatusNotFound, "not_found")
+	}
+}
+
+func (api *FakeAPI) serveZones(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		var zones []*v2.Zone
+		for _, zone := range api.zones {
+			if name := r.URL.Query().Get("filter"); name != "" && !strings.Contains(zone.Name, name) {
+				continue
+			}
+			zones = append(zones, zone)
+		}
+		writeFakePage(w, r, zones, api.PageSize)
+	case http.MethodPost:
+		var form struct {
+			Name string `json:"name"`
+		}
+		if !readFakeBody(w, r, &form) {
+			return
+		}
+		if form.Name == "" {
+			writeFakeError(w, http.StatusBadRequest, "bad_request")
+
+			return
+		}
+		for _, zone := range api.zones {
+			if strings.EqualFold(zone.Name, form.Name) {
+				writeFakeError(w, http.StatusConflict, "conflict")
+
+				return
+			}
+		}
+		writeFakeJSON(w, http.StatusOK, api.addZone(form.Name))
+	default:
+		writeFakeError(w, http.StatusMethodNotAllowed, "method_not_allowed")
+	}
+}
+
+func (api *FakeAPI) serveZone(w http.ResponseWriter, r *http.Request, zoneID string) {
+	zone := api.findZone(zoneID)
+	if zone == nil {
+		writeFakeError(w, http.StatusNotFound, "zone_not_found")
+
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeFakeJSON(w, http.StatusOK, zone)
+	case http.MethodPatch:
+		var form struct {
+			Comment string `json:"comment"`
+		}
+		if !readFakeBody(w, r, &form) {
+			return
+		}
+		zone.Comment = form.Comment
+		zone.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		if zone.Protected {
+			writeFakeError(w, http.StatusBadRequest, "zone_protected")
+
+			return
+		}
+		for i := range api.zones {
+			if api.zones[i] == zone {
+				api.zones = append(api.zones[:i], api.zones[i+1:]...)
+
+				break
+			}
+		}
+		delete(api.rrsets, zoneID)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeFakeError(w, http.StatusMethodNotAllowed, "method_not_allowed")
+	}
+}
+
+func (api *FakeAPI) serveZoneFlag(w http.ResponseWriter, r *http.Request, zoneID, flag string) {
+	zone := api.findZone(zoneID)
+	if zone == nil {
+		writeFakeError(w, http.StatusNotFound, "zone_not_found")
+
+		return
+	}
+	if r.Method != http.MethodPatch {
+		writeFakeError(w, http.StatusMethodNotAllowed, "method_not_allowed")
+
+		return
+	}
+	var form struct {
+		Disabled  bool `json:"disabled"`
+		Protected bool `json:"protected"`
+	}
+	if !readFakeBody(w, r, &form) {
+		return
+	}
+	if flag == "state" {
+		zone.Disabled = form.Disabled
+	} else {
+		zone.Protected = form.Protected
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (api *FakeAPI) serveRRSets(w http.ResponseWriter, r *http.Request, zoneID string) {
+	zone := api.findZone(zoneID)
+	if zone == nil {
+		writeFakeError(w, http.StatusNotFound, "zone_not_found")
+
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		types := query["rrset_types"]
+		var rrsets []*v2.RRSet
+		for _, rrset := range api.rrsets[zoneID] {
+			if name := query.Get("name"); name != "" && !strings.EqualFold(rrset.Name, name) {
+				continue
+			}
+			if len(types) > 0 && !containsFold(types, string(rrset.Type)) {
+				continue
+			}
+			rrsets = append(rrsets, rrset)
+		}
+		writeFakePage(w, r, rrsets, api.PageSize)
+	case http.MethodPost:
+		var form v2.RRSet
+		if !readFakeBody(w, r, &form) {
+			return
+		}
+		if form.Name == "" || form.Type == "" || len(form.Records) == 0 {
+			writeFakeError(w, http.StatusBadRequest, "bad_request")
+
+			return
+		}
+		if !inZone(form.Name, zone.Name) {
+			writeFakeError(w, http.StatusBadRequest, "rrset_name_out_of_zone")
+
+			return
+		}
+		for _, rrset := range api.rrsets[zoneID] {
+			if strings.EqualFold(rrset.Name, form.Name) && rrset.Type == form.Type {
+				writeFakeError(w, http.StatusConflict, "rrset_already_exists")
+
+				return
+			}
+		}
+		writeFakeJSON(w, http.StatusOK, api.addRRSet(zoneID, form))
+	default:
+		writeFakeError(w, http.StatusMethodNotAllowed, "method_not_allowed")
+	}
+}
+
+func (api *FakeAPI) serveRRSet(w http.ResponseWriter, r *http.Request, zoneID, rrsetID string) {
+	rrset := api.findRRSet(zoneID, rrsetID)
+	if rrset == nil {
+		writeFakeError(w, http.StatusNotFound, "rrset_not_found")
+
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeFakeJSON(w, http.StatusOK, rrset)
+	case http.MethodPatch:
+		var form v2.RRSet
+		if !readFakeBody(w, r, &form) {
+			return
+		}
+		if len(form.Records) == 0 {
+			writeFakeError(w, http.StatusBadRequest, "bad_request")
+
+			return
+		}
+		rrset.TTL = form.TTL
+		rrset.Records = form.Records
+		rrset.Comment = form.Comment
+		rrset.ManagedBy = form.ManagedBy
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		rrsets := api.rrsets[zoneID]
+		for i := range rrsets {
+			if rrsets[i] == rrset {
+				api.rrsets[zoneID] = append(rrsets[:i], rrsets[i+1:]...)
+
+				break
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeFakeError(w, http.StatusMethodNotAllowed, "method_not_allowed")
+	}
+}
+
+func writeFakePage[T any](w http.ResponseWriter, r *http.Request, items []*T, pageSize int) {
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || (pageSize > 0 && limit > pageSize) {
+		limit = pageSize
+	}
+	total := len(items)
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+	var nextOffset *int
+	if end < total {
+		nextOffset = &end
+	}
+	page := items[offset:end]
+	if page == nil {
+		page = []*T{}
+	}
+	writeFakeJSON(w, http.StatusOK, map[string]interface{}{
+		"count":       total,
+		"next_offset": nextOffset,
+		"result":      page,
+	})
+}
+
+func readFakeBody(w http.ResponseWriter, r *http.Request, to interface{}) bool {
+	body, err := io.ReadAll(r.Body)
+	if err == nil {
+		err = json.Unmarshal(body, to)
+	}
+	if err != nil {
+		writeFakeError(w, http.StatusBadRequest, "bad_request")
+
+		return false
+	}
+
+	return true
+}
+
+func writeFakeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeFakeError(w http.ResponseWriter, status int, msg string) {
+	writeFakeJSON(w, status, map[string]string{"error": msg})
+}
+
+func copyRRSet(rrset *v2.RRSet) v2.RRSet {
+	result := *rrset
+	result.Records = append([]v2.RecordItem(nil), rrset.Records...)
+
+	return result
+}
+
+func inZone(name, zoneName string) bool {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	zoneName = strings.ToLower(strings.TrimSuffix(zoneName, "."))
+
+	return name == zoneName || strings.HasSuffix(name, "."+zoneName)
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,11 +1,10 @@
 package v1
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
 	"io"
-	"io/ioutil"
 	"net"
 	"net/http"
 	"time"
@@ -66,6 +65,9 @@ type ServiceClient struct {
 
 	// isOpenstackToken defines if passed token should be treated as OpenStack token.
 	isOpenstackToken bool
+
+	// requestTimeout limits the duration of a single request in addition to the HTTPClient timeout.
+	requestTimeout time.Duration
 }
 
 // NewDomainsClientV1 initializes a new client for the Domains API V1.
@@ -129,9 +131,17 @@ func newHTTPTransport() *http.Transport {
 
 // DoRequest performs the HTTP request with the current ServiceClient's HTTPClient.
 // Authentication and optional headers will be added automatically.
+// The response body is read completely and closed before DoRequest returns,
+// so the request is bound to ctx and the per-request timeout for its whole duration.
 func (client *ServiceClient) DoRequest(ctx context.Context, method, path string, body io.Reader) (*ResponseResult, error) {
+	if client.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, client.requestTimeout)
+		defer cancel()
+	}
+
 	// Prepare an HTTP request with the provided context.
-	request, err := http.NewRequest(method, path, body)
+	request, err := http.NewRequestWithContext(ctx, method, path, body)
 	if err != nil {
 		return nil, err
 	}
@@ -146,7 +156,6 @@ func (client *ServiceClient) DoRequest(ctx context.Context, method, path string,
 	if body != nil {
 		request.Header.Set("Content-Type", "application/json")
 	}
-	request = request.WithContext(ctx)
 
 	// Send the HTTP request and populate the ResponseResult.
 	response, err := client.HTTPClient.Do(request)
@@ -154,6 +163,14 @@ func (client *ServiceClient) DoRequest(ctx context.Context, method, path string,
 		return nil, err
 	}
 
+	// Buffer the response body, so the connection is released even if the caller doesn't extract the result.
+	responseBody, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(responseBody))
+
 	responseResult := &ResponseResult{
 		Response: response,
 	}
@@ -177,78 +194,85 @@ func (client *ServiceClient) WithOSToken() *ServiceClient {
 	return &clientCopy
 }
 
+// WithRequestTimeout returns copy of original client where every request is limited by the timeout.
+// Zero timeout means requests are only limited by the HTTPClient timeout and the provided context.
+func (client *ServiceClient) WithRequestTimeout(timeout time.Duration) *ServiceClient {
+	clientCopy := *client
+	clientCopy.requestTimeout = timeout
+	return &clientCopy
+}
+
 // ResponseResult represents a result of an HTTP request.
 // It embeds standard http.Response and adds custom API error representations.
 type ResponseResult struct {
 	*http.Response
 
-	*ErrNotFound
+	// ErrNotFound contains the parsed body of a 404 response.
+	ErrNotFound *ErrorBody
 
-	*ErrGeneric
+	// ErrGeneric contains the parsed body of other error responses.
+	ErrGeneric *ErrorBody
 
 	// Err contains an error that can be provided to a caller.
+	// It is an *APIError for error responses.
 	Err error
 }
 
-// ErrNotFound represents 404 status code error of an HTTP response.
-type ErrNotFound struct {
-	Error string `json:"error"`
-}
-
-// ErrGeneric represents a generic error of an HTTP response.
-type ErrGeneric struct {
+// ErrorBody represents the body of an error HTTP response.
+type ErrorBody struct {
 	Error string `json:"error"`
 }
 
 // ExtractResult allows to provide an object into which ResponseResult body will be extracted.
 func (result *ResponseResult) ExtractResult(to interface{}) error {
-	body, err := ioutil.ReadAll(result.Body)
+	defer result.Body.Close()
+	body, err := io.ReadAll(result.Body)
 	if err != nil {
 		return err
 	}
-	defer result.Body.Close()
 
 	return json.Unmarshal(body, to)
 }
 
 // ExtractRaw extracts ResponseResult body into the slice of bytes without unmarshalling.
 func (result *ResponseResult) ExtractRaw() ([]byte, error) {
-	bytes, err := ioutil.ReadAll(result.Body)
+	defer result.Body.Close()
+	body, err := io.ReadAll(result.Body)
 	if err != nil {
 		return nil, err
 	}
-	defer result.Body.Close()
 
-	return bytes, nil
+	return body, nil
 }
 
 // extractErr populates an error message and error structure in the ResponseResult body.
 func (result *ResponseResult) extractErr() error {
-	body, err := ioutil.ReadAll(result.Body)
+	defer result.Body.Close()
+	body, err := io.ReadAll(result.Body)
 	if err != nil {
 		return err
 	}
-	defer result.Body.Close()
 
+	apiErr := &APIError{
+		StatusCode: result.StatusCode,
+		Body:       body,
+	}
+	result.Err = apiErr
 	if len(body) == 0 {
-		result.Err = fmt.Errorf("domains-go: got the %d status code from the server", result.StatusCode)
 		return nil
 	}
 
-	switch result.StatusCode {
-	case http.StatusNotFound:
-		err = json.Unmarshal(body, &result.ErrNotFound)
-	default:
-		err = json.Unmarshal(body, &result.ErrGeneric)
-	}
-	if err != nil {
-		result.Err = fmt.Errorf("domains-go: got invalid response from the server, status code %d",
-			result.StatusCode)
+	errBody := &ErrorBody{}
+	if err := json.Unmarshal(body, errBody); err != nil {
+		apiErr.malformed = true
 		return nil
 	}
-
-	result.Err = fmt.Errorf("domains-go: got the %d status code from the server: %s",
-		result.StatusCode, string(body))
+	apiErr.Message = errBody.Error
+	if result.StatusCode == http.StatusNotFound {
+		result.ErrNotFound = errBody
+	} else {
+		result.ErrGeneric = errBody
+	}
 
 	return nil
 }
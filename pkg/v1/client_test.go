@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -335,6 +338,52 @@ func TestDoRequestInvalidResponseFromServer(t *testing.T) {
 	}
 }
 
+func TestDoRequestAPIError(t *testing.T) {
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+	testEnv.Mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprint(w, `{"error": "domain_not_found"}`)
+	})
+	testEnv.Mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprint(w, `{"error": "bad request"}`)
+	})
+
+	client := NewDomainsClientV1(testutils.Token, testEnv.Server.URL)
+	ctx := context.Background()
+
+	response, err := client.DoRequest(ctx, http.MethodGet, testEnv.Server.URL+"/missing", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !errors.Is(response.Err, ErrNotFound) {
+		t.Fatalf("got %v error, want ErrNotFound", response.Err)
+	}
+	var apiErr *APIError
+	if !errors.As(response.Err, &apiErr) {
+		t.Fatalf("got %T error, want *APIError", response.Err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Message != "domain_not_found" {
+		t.Fatalf("got %d status code and %q message, want 404 and 'domain_not_found'",
+			apiErr.StatusCode, apiErr.Message)
+	}
+	if string(apiErr.Body) != `{"error": "domain_not_found"}` {
+		t.Fatalf("got %q body", apiErr.Body)
+	}
+
+	response, err = client.DoRequest(ctx, http.MethodGet, testEnv.Server.URL+"/broken", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if errors.Is(response.Err, ErrNotFound) {
+		t.Fatal("400 error shouldn't match ErrNotFound")
+	}
+	if response.Err.Error() != `domains-go: got the 400 status code from the server: {"error": "bad request"}` {
+		t.Fatalf("got %s error message", response.Err.Error())
+	}
+}
+
 func TestClientWithOSToken(t *testing.T) {
 	token := testutils.Token
 	client := NewDomainsClientV1(token, "http://example.org")
@@ -350,3 +399,126 @@ func TestClientWithOSToken(t *testing.T) {
 		t.Fatal("OSClient should have value of .isOpenstackToken = true")
 	}
 }
+
+func TestDoRequestCanceledContext(t *testing.T) {
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+	released := make(chan struct{})
+	defer close(released)
+	testEnv.Mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-released:
+		}
+	})
+
+	endpoint := testEnv.Server.URL + "/"
+	client := NewDomainsClientV1(testutils.Token, endpoint)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := client.DoRequest(ctx, http.MethodGet, endpoint, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v error, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("request wasn't aborted after cancellation, took %s", elapsed)
+	}
+}
+
+func TestDoRequestWithRequestTimeout(t *testing.T) {
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+	released := make(chan struct{})
+	defer close(released)
+	testEnv.Mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-released:
+		}
+	})
+	testEnv.Mux.HandleFunc("/fast", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"id": 1}`)
+	})
+
+	client := NewDomainsClientV1(testutils.Token, testEnv.Server.URL).WithRequestTimeout(50 * time.Millisecond)
+
+	ctx := context.Background()
+	_, err := client.DoRequest(ctx, http.MethodGet, testEnv.Server.URL+"/slow", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v error, want context.DeadlineExceeded", err)
+	}
+
+	// The body stays readable after the request timeout has been released.
+	response, err := client.DoRequest(ctx, http.MethodGet, testEnv.Server.URL+"/fast", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	var result struct {
+		ID int `json:"id"`
+	}
+	if err := response.ExtractResult(&result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ID != 1 {
+		t.Fatalf("got %d id, want 1", result.ID)
+	}
+}
+
+func TestDoRequestClosesResponseBody(t *testing.T) {
+	body := &trackingBody{Reader: strings.NewReader(`{"error": "generic error"}`)}
+	client := &ServiceClient{
+		HTTPClient: &http.Client{
+			Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusBadRequest, Body: body, Header: http.Header{}}, nil
+			}),
+		},
+		Token:     testutils.Token,
+		UserAgent: testutils.UserAgent,
+	}
+
+	response, err := client.DoRequest(context.Background(), http.MethodGet, "http://example.org/", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !body.closed {
+		t.Fatal("response body wasn't closed")
+	}
+	if response.ErrGeneric.Error != "generic error" {
+		t.Fatalf("got %s error message, want 'generic error'", response.ErrGeneric.Error)
+	}
+}
+
+func TestClientWithRequestTimeout(t *testing.T) {
+	client := NewDomainsClientV1(testutils.Token, "http://example.org")
+	timeoutClient := client.WithRequestTimeout(time.Second)
+
+	if client == timeoutClient {
+		t.Fatal(".WithRequestTimeout() should create copy and point to different instance")
+	}
+	if client.requestTimeout != 0 {
+		t.Fatal("initial client should have zero .requestTimeout")
+	}
+	if timeoutClient.requestTimeout != time.Second {
+		t.Fatalf("got %s .requestTimeout, want 1s", timeoutClient.requestTimeout)
+	}
+}
+
+type roundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+type trackingBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *trackingBody) Close() error {
+	b.closed = true
+	return nil
+}
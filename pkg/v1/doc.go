@@ -1,4 +1,18 @@
 /*
 Package v1 provides a library to work with the Selectel Domains API V1.
+
+Error responses are returned as *APIError values that carry the status code,
+the parsed error message and the raw response body.
+
+Example of checking that a domain doesn't exist
+
+  _, _, err := domain.GetByName(ctx, serviceClient, domainName)
+  if errors.Is(err, v1.ErrNotFound) {
+    fmt.Println("domain not found")
+  }
+  var apiErr *v1.APIError
+  if errors.As(err, &apiErr) {
+    fmt.Println(apiErr.StatusCode, apiErr.Message)
+  }
 */
 package v1
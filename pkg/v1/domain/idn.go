@@ -0,0 +1,10 @@
+package domain
+
+import (
+	"github.com/selectel/domains-go/pkg/idn"
+)
+
+// UnicodeName returns the domain name with ACE labels converted to Unicode, e.g. "пример.рф".
+func (result *View) UnicodeName() string {
+	return idn.ToUnicode(result.Name)
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/selectel/domains-go/pkg/idn"
 	v1 "github.com/selectel/domains-go/pkg/v1"
 )
 
@@ -34,6 +35,10 @@ func GetByID(ctx context.Context, client *v1.ServiceClient, domainID int) (*View
 
 // GetByName returns a single domain by its domain name.
 func GetByName(ctx context.Context, client *v1.ServiceClient, domainName string) (*View, *v1.ResponseResult, error) {
+	domainName, err := idn.ToASCII(domainName)
+	if err != nil {
+		return nil, nil, err
+	}
 	url := strings.Join([]string{client.Endpoint, domainName}, "/")
 	responseResult, err := client.DoRequest(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -76,7 +81,13 @@ func List(ctx context.Context, client *v1.ServiceClient) ([]*View, *v1.ResponseR
 
 // Create requests a creation of a new domain.
 func Create(ctx context.Context, client *v1.ServiceClient, opts *CreateOpts) (*View, *v1.ResponseResult, error) {
-	requestBody, err := json.Marshal(opts)
+	name, err := idn.ToASCII(opts.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	asciiOpts := *opts
+	asciiOpts.Name = name
+	requestBody, err := json.Marshal(&asciiOpts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -144,6 +144,9 @@ var testCreateDomainWithBindZoneOpts = &domain.CreateOpts{
 	BindZone: "@ IN SOA ns.test.org. support.selectel.ru. (2020050349 10800 3600 604800 300)",
 }
 
+// testErrNotFoundResponseRaw represents a raw response with a not found error.
+const testErrNotFoundResponseRaw = `{"error":"domain_not_found"}`
+
 // testErrGenericResponseRaw represents a raw response with an error in the generic format.
 const testErrGenericResponseRaw = `{"error":"bad gateway"}`
 
@@ -172,3 +175,15 @@ const testListInvalidResponseRaw = `
 	},
 ]
 `
+
+// testCreateIDNDomainOptsRaw represents marshalled options with an internationalized name for the Create request.
+const testCreateIDNDomainOptsRaw = `
+{
+	"name": "xn--e1afmkfd.xn--p1ai"
+}
+`
+
+// testCreateIDNDomainOpts represents options with an internationalized name for the Create request.
+var testCreateIDNDomainOpts = &domain.CreateOpts{
+	Name: "пример.рф",
+}
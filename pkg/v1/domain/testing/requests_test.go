@@ -2,11 +2,13 @@ package testing
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"reflect"
 	"testing"
 
+	"github.com/selectel/domains-go/pkg/idn"
 	"github.com/selectel/domains-go/pkg/testutils"
 	v1 "github.com/selectel/domains-go/pkg/v1"
 	"github.com/selectel/domains-go/pkg/v1/domain"
@@ -244,6 +246,45 @@ func TestGetByNameHTTPError(t *testing.T) {
 	}
 }
 
+func TestGetByNameNotFoundError(t *testing.T) {
+	endpointCalled := false
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         fmt.Sprintf("/v1/%s", testDomainName),
+		RawResponse: testErrNotFoundResponseRaw,
+		Method:      http.MethodGet,
+		Status:      http.StatusNotFound,
+		CallFlag:    &endpointCalled,
+	})
+
+	ctx := context.Background()
+	testClient := &v1.ServiceClient{
+		HTTPClient: &http.Client{},
+		Token:      testutils.Token,
+		Endpoint:   testEnv.Server.URL + "/v1",
+		UserAgent:  testutils.UserAgent,
+	}
+
+	_, _, err := domain.GetByName(ctx, testClient, testDomainName)
+
+	if !endpointCalled {
+		t.Fatal("endpoint wasn't called")
+	}
+	if !errors.Is(err, v1.ErrNotFound) {
+		t.Fatalf("expected v1.ErrNotFound, but got %v", err)
+	}
+	var apiErr *v1.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *v1.APIError, but got %T", err)
+	}
+	if apiErr.Message != "domain_not_found" {
+		t.Fatalf("expected 'domain_not_found' message, but got %q", apiErr.Message)
+	}
+}
+
 func TestGetByNameTimeoutError(t *testing.T) {
 	testEnv := testutils.SetupTestEnv()
 	testEnv.Server.Close()
@@ -853,3 +894,66 @@ func TestDeleteDomainTimeoutError(t *testing.T) {
 		t.Fatal("expected error from the Delete method")
 	}
 }
+
+func TestCreateIDNDomain(t *testing.T) {
+	endpointCalled := false
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testutils.HandleReqWithBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/v1/",
+		RawResponse: testCreateDomainResponseRaw,
+		RawRequest:  testCreateIDNDomainOptsRaw,
+		Method:      http.MethodPost,
+		Status:      http.StatusOK,
+		CallFlag:    &endpointCalled,
+	})
+
+	ctx := context.Background()
+	testClient := &v1.ServiceClient{
+		HTTPClient: &http.Client{},
+		Token:      testutils.Token,
+		Endpoint:   testEnv.Server.URL + "/v1",
+		UserAgent:  testutils.UserAgent,
+	}
+
+	_, _, err := domain.Create(ctx, testClient, testCreateIDNDomainOpts)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !endpointCalled {
+		t.Fatal("endpoint wasn't called")
+	}
+	if testCreateIDNDomainOpts.Name != "пример.рф" {
+		t.Fatalf("expected options to be left intact, but got %s", testCreateIDNDomainOpts.Name)
+	}
+}
+
+func TestCreateIDNDomainInvalidName(t *testing.T) {
+	ctx := context.Background()
+	testClient := &v1.ServiceClient{
+		HTTPClient: &http.Client{},
+		Token:      testutils.Token,
+		Endpoint:   "http://localhost/v1",
+		UserAgent:  testutils.UserAgent,
+	}
+
+	_, httpResponse, err := domain.Create(ctx, testClient, &domain.CreateOpts{Name: "☃.example.com"})
+
+	if !errors.Is(err, idn.ErrInvalidName) {
+		t.Fatalf("expected %v, but got %v", idn.ErrInvalidName, err)
+	}
+	if httpResponse != nil {
+		t.Fatal("expected no HTTP response for an invalid name")
+	}
+}
+
+func TestViewUnicodeName(t *testing.T) {
+	view := &domain.View{Name: "xn--e1afmkfd.xn--p1ai"}
+
+	if actual := view.UnicodeName(); actual != "пример.рф" {
+		t.Fatalf("expected пример.рф, but got %s", actual)
+	}
+}
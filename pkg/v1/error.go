@@ -0,0 +1,43 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrNotFound can be matched with errors.Is against errors returned for 404 responses.
+var ErrNotFound = errors.New("domains-go: object not found")
+
+// APIError represents an error response of the Domains API V1.
+type APIError struct {
+	// StatusCode contains the HTTP status code of the response.
+	StatusCode int
+
+	// Message contains the error message parsed from the response body.
+	// It is empty if the body has no message.
+	Message string
+
+	// Body contains the raw response body.
+	Body []byte
+
+	// malformed defines if the response body couldn't be parsed.
+	malformed bool
+}
+
+// Error returns the error description including the raw response body.
+func (e *APIError) Error() string {
+	switch {
+	case len(e.Body) == 0:
+		return fmt.Sprintf("domains-go: got the %d status code from the server", e.StatusCode)
+	case e.malformed:
+		return fmt.Sprintf("domains-go: got invalid response from the server, status code %d", e.StatusCode)
+	default:
+		return fmt.Sprintf("domains-go: got the %d status code from the server: %s", e.StatusCode, string(e.Body))
+	}
+}
+
+// Is reports whether the error matches ErrNotFound.
+func (e *APIError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,56 @@
+package record
+
+import (
+	"github.com/selectel/domains-go/pkg/idn"
+)
+
+// UnicodeName returns the record name with ACE labels converted to Unicode.
+func (result *View) UnicodeName() string {
+	return idn.ToUnicode(result.Name)
+}
+
+// UnicodeContent returns the record content with the target name of CNAME, ALIAS, NS
+// and MX records converted to Unicode.
+func (result *View) UnicodeContent() string {
+	if !hasNameContent(result.Type) {
+		return result.Content
+	}
+
+	return idn.ToUnicode(result.Content)
+}
+
+// UnicodeTarget returns the SRV record target with ACE labels converted to Unicode.
+func (result *View) UnicodeTarget() string {
+	return idn.ToUnicode(result.Target)
+}
+
+// asciiNames converts the name, the content of CNAME, ALIAS, NS and MX records
+// and the SRV target to ASCII.
+func asciiNames(recordType Type, name, content, target string) (string, string, string, error) {
+	name, err := idn.ToASCII(name)
+	if err != nil {
+		return "", "", "", err
+	}
+	if hasNameContent(recordType) {
+		content, err = idn.ToASCII(content)
+		if err != nil {
+			return "", "", "", err
+		}
+	}
+	target, err = idn.ToASCII(target)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return name, content, target, nil
+}
+
+// hasNameContent tells whether the content of records of the type is a domain name.
+func hasNameContent(recordType Type) bool {
+	switch recordType {
+	case TypeCNAME, TypeALIAS, TypeNS, TypeMX:
+		return true
+	default:
+		return false
+	}
+}
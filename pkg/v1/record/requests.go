@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/selectel/domains-go/pkg/idn"
 	v1 "github.com/selectel/domains-go/pkg/v1"
 )
 
@@ -62,6 +63,10 @@ func ListByDomainID(ctx context.Context, client *v1.ServiceClient, domainID int)
 
 // ListByDomainName returns a list of domain records by domain name.
 func ListByDomainName(ctx context.Context, client *v1.ServiceClient, domainName string) ([]*View, *v1.ResponseResult, error) {
+	domainName, err := idn.ToASCII(domainName)
+	if err != nil {
+		return nil, nil, err
+	}
 	url := strings.Join([]string{
 		client.Endpoint,
 		domainName,
@@ -86,7 +91,13 @@ func ListByDomainName(ctx context.Context, client *v1.ServiceClient, domainName
 
 // Create requests a creation of a new domain record.
 func Create(ctx context.Context, client *v1.ServiceClient, domainID int, opts *CreateOpts) (*View, *v1.ResponseResult, error) {
-	requestBody, err := json.Marshal(opts)
+	name, content, target, err := asciiNames(opts.Type, opts.Name, opts.Content, opts.Target)
+	if err != nil {
+		return nil, nil, err
+	}
+	asciiOpts := *opts
+	asciiOpts.Name, asciiOpts.Content, asciiOpts.Target = name, content, target
+	requestBody, err := json.Marshal(&asciiOpts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -133,7 +144,13 @@ func Delete(ctx context.Context, client *v1.ServiceClient, domainID, recordID in
 
 // Update requests domain record updating.
 func Update(ctx context.Context, client *v1.ServiceClient, domainID, recordID int, opts *UpdateOpts) (*View, *v1.ResponseResult, error) {
-	requestBody, err := json.Marshal(opts)
+	name, content, target, err := asciiNames(opts.Type, opts.Name, opts.Content, opts.Target)
+	if err != nil {
+		return nil, nil, err
+	}
+	asciiOpts := *opts
+	asciiOpts.Name, asciiOpts.Content, asciiOpts.Target = name, content, target
+	requestBody, err := json.Marshal(&asciiOpts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -330,3 +330,27 @@ const testListRecordsInvalidResponseRaw = `
    "name" : "share.testdomain.xyz",
 }]
 `
+
+// testCreateIDNRecordOptsRaw represents a raw request options with internationalized names for Create request.
+const testCreateIDNRecordOptsRaw = `
+{
+   "name": "_sip._tcp.xn--e1afmkfd.xn--p1ai",
+   "type": "SRV",
+   "ttl": 60,
+   "priority": 10,
+   "weight": 5,
+   "port": 5060,
+   "target": "sip.xn--e1afmkfd.xn--p1ai"
+}
+`
+
+// testCreateIDNRecordOpts represents an unmarshalled testCreateIDNRecordOptsRaw with Unicode names.
+var testCreateIDNRecordOpts = &record.CreateOpts{
+	Name:     "_sip._tcp.пример.рф",
+	Type:     record.TypeSRV,
+	TTL:      60,
+	Priority: testutils.IntPtr(10),
+	Weight:   testutils.IntPtr(5),
+	Port:     testutils.IntPtr(5060),
+	Target:   "sip.пример.рф",
+}
@@ -2,11 +2,13 @@ package testing
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"reflect"
 	"testing"
 
+	"github.com/selectel/domains-go/pkg/idn"
 	"github.com/selectel/domains-go/pkg/testutils"
 	v1 "github.com/selectel/domains-go/pkg/v1"
 	"github.com/selectel/domains-go/pkg/v1/record"
@@ -860,3 +862,80 @@ func TestUpdateRecordUnmarshallError(t *testing.T) {
 		t.Fatal("expected error from the Update method")
 	}
 }
+
+func TestCreateIDNRecord(t *testing.T) {
+	endpointCalled := false
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testutils.HandleReqWithBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         fmt.Sprintf("/v1/%d/records/", testDomainID),
+		RawResponse: testCreateRecordResponseRaw,
+		RawRequest:  testCreateIDNRecordOptsRaw,
+		Method:      http.MethodPost,
+		Status:      http.StatusOK,
+		CallFlag:    &endpointCalled,
+	})
+
+	ctx := context.Background()
+	testClient := &v1.ServiceClient{
+		HTTPClient: &http.Client{},
+		Token:      testutils.Token,
+		Endpoint:   testEnv.Server.URL + "/v1",
+		UserAgent:  testutils.UserAgent,
+	}
+
+	_, _, err := record.Create(ctx, testClient, testDomainID, testCreateIDNRecordOpts)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !endpointCalled {
+		t.Fatal("endpoint wasn't called")
+	}
+}
+
+func TestUpdateRecordInvalidName(t *testing.T) {
+	ctx := context.Background()
+	testClient := &v1.ServiceClient{
+		HTTPClient: &http.Client{},
+		Token:      testutils.Token,
+		Endpoint:   "http://localhost/v1",
+		UserAgent:  testutils.UserAgent,
+	}
+	opts := &record.UpdateOpts{
+		Name:    "www.пример.рф",
+		Type:    record.TypeCNAME,
+		TTL:     60,
+		Content: "☃.example.com",
+	}
+
+	_, httpResponse, err := record.Update(ctx, testClient, testDomainID, testRecordID, opts)
+
+	if !errors.Is(err, idn.ErrInvalidName) {
+		t.Fatalf("expected %v, but got %v", idn.ErrInvalidName, err)
+	}
+	if httpResponse != nil {
+		t.Fatal("expected no HTTP response for an invalid name")
+	}
+}
+
+func TestViewUnicodeNames(t *testing.T) {
+	view := &record.View{
+		Name:    "www.xn--e1afmkfd.xn--p1ai",
+		Type:    record.TypeMX,
+		Content: "mx.xn--e1afmkfd.xn--p1ai",
+		Target:  "sip.xn--e1afmkfd.xn--p1ai",
+	}
+
+	if actual := view.UnicodeName(); actual != "www.пример.рф" {
+		t.Fatalf("expected www.пример.рф, but got %s", actual)
+	}
+	if actual := view.UnicodeContent(); actual != "mx.пример.рф" {
+		t.Fatalf("expected mx.пример.рф, but got %s", actual)
+	}
+	if actual := view.UnicodeTarget(); actual != "sip.пример.рф" {
+		t.Fatalf("expected sip.пример.рф, but got %s", actual)
+	}
+}
@@ -0,0 +1,429 @@
+package v2compat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/selectel/domains-go/pkg/v1"
+	"github.com/selectel/domains-go/pkg/v1/domain"
+	"github.com/selectel/domains-go/pkg/v1/record"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/rrconv"
+)
+
+// rrsetIDSeparator separates the type and the name in synthetic rrset ids.
+const rrsetIDSeparator = ":"
+
+var (
+	ErrUnsupported   = errors.New("operation is not supported by the V1 API")
+	ErrAlreadyExists = errors.New("rrset already exists")
+)
+
+// Adapter implements v2.DNSManager on top of the Domains API V1.
+// Domains are exposed as zones with their numeric ids as strings,
+// records are grouped into rrsets with synthetic "TYPE:name" ids.
+// SOA records aren't exposed, zone state, protection and comments aren't supported.
+type Adapter struct {
+	client *v1.ServiceClient
+}
+
+var _ v2.DNSManager[v2.Zone, v2.RRSet] = (*Adapter)(nil)
+
+// NewAdapter returns an adapter using the V1 client.
+func NewAdapter(client *v1.ServiceClient) *Adapter {
+	return &Adapter{client: client}
+}
+
+// RRSetID returns the synthetic id of the rrset with the name and type.
+func RRSetID(name string, recordType v2.RecordType) string {
+	return string(recordType) + rrsetIDSeparator + Name(name)
+}
+
+// GetZone returns the domain with the id as a zone.
+func (a *Adapter) GetZone(ctx context.Context, zoneID string, _ *map[string]string) (*v2.Zone, error) {
+	domainID, err := parseZoneID(zoneID)
+	if err != nil {
+		return nil, err
+	}
+	view, _, err := domain.GetByID(ctx, a.client, domainID)
+	if err != nil {
+		return nil, convertErr(err)
+	}
+
+	return toZone(view), nil
+}
+
+// ListZones returns domains as zones.
+// The "filter" option matches a part of the name, "offset" and "limit" are applied locally.
+func (a *Adapter) ListZones(ctx context.Context, options *map[string]string) (v2.Listable[v2.Zone], error) {
+	views, _, err := domain.List(ctx, a.client)
+	if err != nil {
+		return nil, convertErr(err)
+	}
+	filter := option(options, "filter")
+	zones := make([]*v2.Zone, 0, len(views))
+	for _, view := range views {
+		if filter != "" && !strings.Contains(view.Name, strings.TrimSuffix(filter, ".")) {
+			continue
+		}
+		zones = append(zones, toZone(view))
+	}
+
+	return paginate(zones, options), nil
+}
+
+// CreateZone creates a domain with the name of the zone.
+func (a *Adapter) CreateZone(ctx context.Context, zone v2.Creatable) (*v2.Zone, error) {
+	var form struct {
+		Name string `json:"name"`
+	}
+	if err := decodeForm(zone.CreationForm, &form); err != nil {
+		return nil, err
+	}
+	//nolint: exhaustruct
+	view, _, err := domain.Create(ctx, a.client, &domain.CreateOpts{Name: strings.TrimSuffix(form.Name, ".")})
+	if err != nil {
+		return nil, convertErr(err)
+	}
+
+	return toZone(view), nil
+}
+
+// DeleteZone deletes the domain with the id.
+func (a *Adapter) DeleteZone(ctx context.Context, zoneID string) error {
+	domainID, err := parseZoneID(zoneID)
+	if err != nil {
+		return err
+	}
+	_, err = domain.Delete(ctx, a.client, domainID)
+
+	return convertErr(err)
+}
+
+// UpdateZoneState returns ErrUnsupported.
+func (a *Adapter) UpdateZoneState(_ context.Context, _ string, _ bool) error {
+	return fmt.Errorf("%w: zone state", ErrUnsupported)
+}
+
+// UpdateZoneComment returns ErrUnsupported.
+func (a *Adapter) UpdateZoneComment(_ context.Context, _ string, _ string) error {
+	return fmt.Errorf("%w: zone comment", ErrUnsupported)
+}
+
+// UpdateProtectionState returns ErrUnsupported.
+func (a *Adapter) UpdateProtectionState(_ context.Context, _ string, _ bool) error {
+	return fmt.Errorf("%w: zone protection", ErrUnsupported)
+}
+
+// CreateRRSet creates a record for every item of the rrset.
+// It fails with ErrAlreadyExists if the domain already has records with the same name and type.
+func (a *Adapter) CreateRRSet(ctx context.Context, zoneID string, rrset v2.Creatable) (*v2.RRSet, error) {
+	//nolint: exhaustruct
+	form := &v2.RRSet{}
+	if err := decodeForm(rrset.CreationForm, form); err != nil {
+		return nil, err
+	}
+	domainID, err := parseZoneID(zoneID)
+	if err != nil {
+		return nil, err
+	}
+	rrsetID := RRSetID(form.Name, form.Type)
+	views, err := a.rrsetRecords(ctx, domainID, rrsetID)
+	if err != nil {
+		return nil, err
+	}
+	if len(views) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrAlreadyExists, rrsetID)
+	}
+	for _, item := range form.Records {
+		if err := a.createRecord(ctx, domainID, form, item); err != nil {
+			return nil, err
+		}
+	}
+
+	return a.GetRRSet(ctx, zoneID, rrsetID)
+}
+
+// GetRRSet returns records with the name and type encoded in the rrset id as an rrset.
+func (a *Adapter) GetRRSet(ctx context.Context, zoneID, rrsetID string) (*v2.RRSet, error) {
+	domainID, err := parseZoneID(zoneID)
+	if err != nil {
+		return nil, err
+	}
+	views, err := a.rrsetRecords(ctx, domainID, rrsetID)
+	if err != nil {
+		return nil, err
+	}
+	rrsets, _ := Group(views)
+	if len(rrsets) == 0 {
+		return nil, v2.ErrNotFound
+	}
+
+	return withIDs(rrsets[0], zoneID), nil
+}
+
+// ListRRSets returns records of the domain grouped into rrsets.
+// The "name" and "rrset_types" options filter rrsets, "offset" and "limit" are applied locally.
+func (a *Adapter) ListRRSets(
+	ctx context.Context, zoneID string, options *map[string]string,
+) (v2.Listable[v2.RRSet], error) {
+	domainID, err := parseZoneID(zoneID)
+	if err != nil {
+		return nil, err
+	}
+	views, _, err := record.ListByDomainID(ctx, a.client, domainID)
+	if err != nil {
+		return nil, convertErr(err)
+	}
+	rrsets, _ := Group(views)
+	name := option(options, "name")
+	var types []string
+	if value := option(options, "rrset_types"); value != "" {
+		types = strings.Split(value, ",")
+	}
+	result := make([]*v2.RRSet, 0, len(rrsets))
+	for _, rrset := range rrsets {
+		if name != "" && rrset.Name != Name(name) {
+			continue
+		}
+		if len(types) > 0 && !containsFold(types, string(rrset.Type)) {
+			continue
+		}
+		result = append(result, withIDs(rrset, zoneID))
+	}
+
+	return paginate(result, options), nil
+}
+
+// UpdateRRSet reconciles records of the rrset with the update form.
+// Records missing from the form are deleted, new ones are created
+// and kept records are updated if their TTL changes.
+func (a *Adapter) UpdateRRSet(ctx context.Context, zoneID, rrsetID string, rrset v2.Updatable) error {
+	//nolint: exhaustruct
+	form := &v2.RRSet{}
+	if err := decodeForm(rrset.UpdateForm, form); err != nil {
+		return err
+	}
+	domainID, err := parseZoneID(zoneID)
+	if err != nil {
+		return err
+	}
+	form.Type, form.Name, err = parseRRSetID(rrsetID)
+	if err != nil {
+		return err
+	}
+	views, err := a.rrsetRecords(ctx, domainID, rrsetID)
+	if err != nil {
+		return err
+	}
+	if len(views) == 0 {
+		return v2.ErrNotFound
+	}
+
+	desired := make(map[string]bool, len(form.Records))
+	for _, item := range form.Records {
+		desired[canonicalContent(form.Type, item.Content)] = true
+	}
+	existing := make(map[string]bool, len(views))
+	for _, view := range views {
+		content, err := Content(view)
+		if err != nil {
+			return err
+		}
+		content = canonicalContent(form.Type, content)
+		switch {
+		case !desired[content] || existing[content]:
+			if _, err := record.Delete(ctx, a.client, domainID, view.ID); err != nil {
+				return convertErr(err)
+			}
+		case view.TTL != form.TTL:
+			opts, err := RecordOpts(form.Name, form.TTL, form.Type, content)
+			if err != nil {
+				return err
+			}
+			updateOpts := record.UpdateOpts(*opts)
+			if _, _, err := record.Update(ctx, a.client, domainID, view.ID, &updateOpts); err != nil {
+				return convertErr(err)
+			}
+		}
+		existing[content] = true
+	}
+	for _, item := range form.Records {
+		content := canonicalContent(form.Type, item.Content)
+		if existing[content] {
+			continue
+		}
+		if err := a.createRecord(ctx, domainID, form, item); err != nil {
+			return err
+		}
+		existing[content] = true
+	}
+
+	return nil
+}
+
+// DeleteRRSet deletes all records with the name and type encoded in the rrset id.
+func (a *Adapter) DeleteRRSet(ctx context.Context, zoneID, rrsetID string) error {
+	domainID, err := parseZoneID(zoneID)
+	if err != nil {
+		return err
+	}
+	views, err := a.rrsetRecords(ctx, domainID, rrsetID)
+	if err != nil {
+		return err
+	}
+	if len(views) == 0 {
+		return v2.ErrNotFound
+	}
+	for _, view := range views {
+		if _, err := record.Delete(ctx, a.client, domainID, view.ID); err != nil {
+			return convertErr(err)
+		}
+	}
+
+	return nil
+}
+
+func (a *Adapter) createRecord(ctx context.Context, domainID int, rrset *v2.RRSet, item v2.RecordItem) error {
+	if item.Disabled {
+		return fmt.Errorf("%w: disabled records", ErrUnsupported)
+	}
+	opts, err := RecordOpts(rrset.Name, rrset.TTL, rrset.Type, item.Content)
+	if err != nil {
+		return err
+	}
+	if _, _, err := record.Create(ctx, a.client, domainID, opts); err != nil {
+		return convertErr(err)
+	}
+
+	return nil
+}
+
+// rrsetRecords returns records of the domain that belong to the rrset.
+func (a *Adapter) rrsetRecords(ctx context.Context, domainID int, rrsetID string) ([]*record.View, error) {
+	recordType, name, err := parseRRSetID(rrsetID)
+	if err != nil {
+		return nil, err
+	}
+	views, _, err := record.ListByDomainID(ctx, a.client, domainID)
+	if err != nil {
+		return nil, convertErr(err)
+	}
+	var result []*record.View
+	for _, view := range views {
+		viewType, err := RecordType(view.Type)
+		if err == nil && viewType == recordType && Name(view.Name) == name {
+			result = append(result, view)
+		}
+	}
+
+	return result, nil
+}
+
+// canonicalContent returns the content in the form miekg/dns prints it, so equal records compare equal.
+func canonicalContent(recordType v2.RecordType, content string) string {
+	rr, err := rrconv.ToRR(".", 0, recordType, content)
+	if err != nil {
+		return content
+	}
+
+	return rrconv.Content(rr)
+}
+
+func toZone(view *domain.View) *v2.Zone {
+	//nolint: exhaustruct
+	return &v2.Zone{
+		ID:        strconv.Itoa(view.ID),
+		Name:      Name(view.Name),
+		CreatedAt: time.Unix(int64(view.CreateDate), 0).UTC(),
+		UpdatedAt: time.Unix(int64(view.ChangeDate), 0).UTC(),
+	}
+}
+
+func withIDs(rrset *v2.RRSet, zoneID string) *v2.RRSet {
+	rrset.ID = RRSetID(rrset.Name, rrset.Type)
+	rrset.ZoneID = zoneID
+
+	return rrset
+}
+
+func parseZoneID(zoneID string) (int, error) {
+	domainID, err := strconv.Atoi(zoneID)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid zone id %q", v2.ErrNotFound, zoneID)
+	}
+
+	return domainID, nil
+}
+
+func parseRRSetID(rrsetID string) (v2.RecordType, string, error) {
+	recordType, name, ok := strings.Cut(rrsetID, rrsetIDSeparator)
+	if !ok || recordType == "" || name == "" {
+		return "", "", fmt.Errorf("%w: invalid rrset id %q", v2.ErrNotFound, rrsetID)
+	}
+
+	return v2.RecordType(recordType), Name(name), nil
+}
+
+// convertErr maps V1 not found errors to v2.ErrNotFound, so callers can handle both APIs alike.
+func convertErr(err error) error {
+	if errors.Is(err, v1.ErrNotFound) {
+		return fmt.Errorf("%w: %w", v2.ErrNotFound, err)
+	}
+
+	return err
+}
+
+func decodeForm(form func() (io.Reader, error), to interface{}) error {
+	reader, err := form()
+	if err != nil {
+		return fmt.Errorf("%w: %w", v2.ErrInvalidRequestObj, err)
+	}
+	if err := json.NewDecoder(reader).Decode(to); err != nil {
+		return fmt.Errorf("%w: %w", v2.ErrInvalidRequestObj, err)
+	}
+
+	return nil
+}
+
+func option(options *map[string]string, key string) string {
+	if options == nil {
+		return ""
+	}
+
+	return (*options)[key]
+}
+
+func paginate[T v2.Zone | v2.RRSet](items []*T, options *map[string]string) v2.List[T] {
+	offset, _ := strconv.Atoi(option(options, "offset"))
+	limit, _ := strconv.Atoi(option(options, "limit"))
+	if offset > len(items) {
+		offset = len(items)
+	}
+	end := len(items)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	list := v2.List[T]{Count: len(items), NextOffset: 0, Items: items[offset:end]}
+	if end < len(items) {
+		list.NextOffset = end
+	}
+
+	return list
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,279 @@
+package v2compat
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/miekg/dns"
+	"github.com/selectel/domains-go/pkg/v1/record"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/rrconv"
+)
+
+var (
+	ErrUnsupportedType = errors.New("record type is not supported by the V2 API")
+	ErrMissingField    = errors.New("record field is missing")
+)
+
+// Kinds of conflicts reported by Group.
+const (
+	// ConflictTTL means records of an rrset have different TTLs, the lowest one is used.
+	ConflictTTL ConflictKind = "ttl_mismatch"
+
+	// ConflictCNAME means a CNAME record shares its name with records of other types.
+	ConflictCNAME ConflictKind = "cname_and_other_data"
+
+	// ConflictUnsupported means a record can't be converted and is left out.
+	ConflictUnsupported ConflictKind = "unsupported_record"
+)
+
+type (
+	// ConflictKind represents the kind of a conversion problem.
+	ConflictKind string
+
+	// Conflict describes records that can't be converted as is.
+	Conflict struct {
+		Kind   ConflictKind `json:"kind"`
+		Name   string       `json:"name"`
+		Type   string       `json:"type"`
+		Detail string       `json:"detail"`
+	}
+
+	groupKey struct {
+		name       string
+		recordType v2.RecordType
+	}
+)
+
+// String returns a human-readable description of the conflict.
+func (c Conflict) String() string {
+	return fmt.Sprintf("%s %s: %s: %s", c.Name, c.Type, c.Kind, c.Detail)
+}
+
+// Name returns the fully qualified V2 form of a V1 record name.
+func Name(name string) string {
+	return fqdn(strings.ToLower(name))
+}
+
+// RecordType returns the V2 type of a V1 record type.
+// SOA records are maintained by the V2 API and are reported as unsupported.
+func RecordType(recordType record.Type) (v2.RecordType, error) {
+	switch recordType {
+	case record.TypeA:
+		return v2.A, nil
+	case record.TypeAAAA:
+		return v2.AAAA, nil
+	case record.TypeTXT:
+		return v2.TXT, nil
+	case record.TypeCNAME:
+		return v2.CNAME, nil
+	case record.TypeNS:
+		return v2.NS, nil
+	case record.TypeMX:
+		return v2.MX, nil
+	case record.TypeSRV:
+		return v2.SRV, nil
+	case record.TypeCAA:
+		return v2.CAA, nil
+	case record.TypeSSHFP:
+		return v2.SSHFP, nil
+	case record.TypeALIAS:
+		return v2.ALIAS, nil
+	case record.TypeSOA, record.TypeUnknown:
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedType, recordType)
+}
+
+// Content returns the V2 content of a V1 record.
+func Content(view *record.View) (string, error) {
+	switch view.Type {
+	case record.TypeA, record.TypeAAAA:
+		return view.Content, nil
+	case record.TypeCNAME, record.TypeNS, record.TypeALIAS:
+		return fqdn(view.Content), nil
+	case record.TypeTXT:
+		return QuoteTXT(view.Content), nil
+	case record.TypeMX:
+		if view.Priority == nil {
+			return "", fmt.Errorf("%w: priority", ErrMissingField)
+		}
+
+		return fmt.Sprintf("%d %s", *view.Priority, fqdn(view.Content)), nil
+	case record.TypeSRV:
+		if view.Priority == nil || view.Weight == nil || view.Port == nil {
+			return "", fmt.Errorf("%w: priority, weight and port are required", ErrMissingField)
+		}
+
+		return fmt.Sprintf("%d %d %d %s", *view.Priority, *view.Weight, *view.Port, fqdn(view.Target)), nil
+	case record.TypeCAA:
+		if view.Flag == nil {
+			return "", fmt.Errorf("%w: flag", ErrMissingField)
+		}
+
+		return fmt.Sprintf("%d %s %s", *view.Flag, view.Tag, quote(view.Value)), nil
+	case record.TypeSSHFP:
+		if view.Algorithm == nil || view.FingerprintType == nil {
+			return "", fmt.Errorf("%w: algorithm and fingerprint_type are required", ErrMissingField)
+		}
+
+		return fmt.Sprintf("%d %d %s", *view.Algorithm, *view.FingerprintType, view.Fingerprint), nil
+	case record.TypeSOA, record.TypeUnknown:
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedType, view.Type)
+}
+
+// RecordOpts returns V1 creation options of a single V2 record.
+func RecordOpts(name string, ttl int, recordType v2.RecordType, content string) (*record.CreateOpts, error) {
+	//nolint: exhaustruct
+	opts := &record.CreateOpts{Name: strings.TrimSuffix(name, "."), TTL: ttl}
+	if recordType == v2.ALIAS {
+		opts.Type = record.TypeALIAS
+		opts.Content = strings.TrimSuffix(content, ".")
+
+		return opts, nil
+	}
+	rr, err := rrconv.ToRR(name, ttl, recordType, content)
+	if err != nil {
+		return nil, err
+	}
+	switch rr := rr.(type) {
+	case *dns.A:
+		opts.Type, opts.Content = record.TypeA, rr.A.String()
+	case *dns.AAAA:
+		opts.Type, opts.Content = record.TypeAAAA, rr.AAAA.String()
+	case *dns.CNAME:
+		opts.Type, opts.Content = record.TypeCNAME, strings.TrimSuffix(rr.Target, ".")
+	case *dns.NS:
+		opts.Type, opts.Content = record.TypeNS, strings.TrimSuffix(rr.Ns, ".")
+	case *dns.TXT:
+		opts.Type, opts.Content = record.TypeTXT, strings.Join(rr.Txt, "")
+	case *dns.MX:
+		opts.Type, opts.Content = record.TypeMX, strings.TrimSuffix(rr.Mx, ".")
+		opts.Priority = intPtr(int(rr.Preference))
+	case *dns.SRV:
+		opts.Type, opts.Target = record.TypeSRV, strings.TrimSuffix(rr.Target, ".")
+		opts.Priority, opts.Weight, opts.Port = intPtr(int(rr.Priority)), intPtr(int(rr.Weight)), intPtr(int(rr.Port))
+	case *dns.CAA:
+		opts.Type, opts.Tag, opts.Value = record.TypeCAA, rr.Tag, rr.Value
+		opts.Flag = intPtr(int(rr.Flag))
+	case *dns.SSHFP:
+		opts.Type, opts.Fingerprint = record.TypeSSHFP, rr.FingerPrint
+		opts.Algorithm, opts.FingerprintType = intPtr(int(rr.Algorithm)), intPtr(int(rr.Type))
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, recordType)
+	}
+
+	return opts, nil
+}
+
+// QuoteTXT returns TXT data as quoted character-strings of at most 255 bytes.
+// Data that is already quoted is returned unchanged.
+func QuoteTXT(data string) string {
+	if len(data) >= 2 && strings.HasPrefix(data, `"`) && strings.HasSuffix(data, `"`) {
+		return data
+	}
+
+	return v2.EncodeTXT(data)
+}
+
+// Group converts V1 records into V2 rrsets.
+// Records with the same name and type form a single rrset, duplicates are dropped.
+// SOA records are skipped since the V2 API maintains them itself.
+// Records that can't be converted are left out and reported as conflicts
+// together with other inconsistencies, rrsets are ordered by name and type.
+func Group(records []*record.View) ([]*v2.RRSet, []Conflict) {
+	var conflicts []Conflict
+	rrsets := make(map[groupKey]*v2.RRSet)
+	for _, view := range records {
+		if view.Type == record.TypeSOA {
+			continue
+		}
+		name := Name(view.Name)
+		recordType, err := RecordType(view.Type)
+		if err != nil {
+			conflicts = append(conflicts, newConflict(ConflictUnsupported, name, string(view.Type), err.Error()))
+
+			continue
+		}
+		content, err := Content(view)
+		if err != nil {
+			conflicts = append(conflicts, newConflict(ConflictUnsupported, name, string(view.Type), err.Error()))
+
+			continue
+		}
+
+		key := groupKey{name: name, recordType: recordType}
+		rrset, ok := rrsets[key]
+		if !ok {
+			//nolint: exhaustruct
+			rrset = &v2.RRSet{Name: name, Type: recordType, TTL: view.TTL}
+			rrsets[key] = rrset
+		}
+		if rrset.TTL != view.TTL {
+			detail := fmt.Sprintf("records have TTLs %d and %d, using the lowest", rrset.TTL, view.TTL)
+			conflicts = append(conflicts, newConflict(ConflictTTL, name, string(recordType), detail))
+			if view.TTL < rrset.TTL {
+				rrset.TTL = view.TTL
+			}
+		}
+		if !hasContent(rrset, content) {
+			rrset.Records = append(rrset.Records, v2.RecordItem{Content: content, Disabled: false})
+		}
+	}
+
+	result := make([]*v2.RRSet, 0, len(rrsets))
+	types := make(map[string][]v2.RecordType)
+	for key, rrset := range rrsets {
+		result = append(result, rrset)
+		types[key.name] = append(types[key.name], key.recordType)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+
+		return result[i].Type < result[j].Type
+	})
+	for _, rrset := range result {
+		if rrset.Type == v2.CNAME && len(types[rrset.Name]) > 1 {
+			detail := "CNAME can't coexist with other records of the same name"
+			conflicts = append(conflicts, newConflict(ConflictCNAME, rrset.Name, string(v2.CNAME), detail))
+		}
+	}
+
+	return result, conflicts
+}
+
+func newConflict(kind ConflictKind, name, recordType, detail string) Conflict {
+	return Conflict{Kind: kind, Name: name, Type: recordType, Detail: detail}
+}
+
+func hasContent(rrset *v2.RRSet, content string) bool {
+	for _, item := range rrset.Records {
+		if item.Content == content {
+			return true
+		}
+	}
+
+	return false
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+
+	return name + "."
+}
+
+func quote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
@@ -0,0 +1,38 @@
+/*
+Package v2compat converts records of the Selectel Domains API V1
+to rrsets of the Selectel Domains API V2.
+
+V1 stores every record separately with structured fields for MX, SRV, CAA
+and SSHFP data, while V2 groups records with the same name and type into
+an rrset with contents in the zone file presentation format.
+
+Example of converting records of a domain
+
+  records, _, err := record.ListByDomainName(ctx, serviceClient, domainName)
+  if err != nil {
+    log.Fatal(err)
+  }
+  rrsets, conflicts := v2compat.Group(records)
+  for _, conflict := range conflicts {
+    fmt.Println(conflict)
+  }
+  for _, rrset := range rrsets {
+    fmt.Printf("%+v\n", rrset)
+  }
+
+Adapter implements v2.DNSManager on top of the V1 API, so code written
+against the V2 interfaces can manage domains that are still on V1.
+Operations V1 has no counterpart for return ErrUnsupported.
+
+Example of listing rrsets of a V1 domain through the V2 interface
+
+  adapter := v2compat.NewAdapter(serviceClient)
+  rrsets, err := v2.ListAllRRSets[v2.RRSet](ctx, adapter, strconv.Itoa(domainID), nil)
+  if err != nil {
+    log.Fatal(err)
+  }
+  for _, rrset := range rrsets {
+    fmt.Printf("%s %s %+v\n", rrset.ID, rrset.Type, rrset.Records)
+  }
+*/
+package v2compat
@@ -0,0 +1,254 @@
+package testing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/selectel/domains-go/pkg/testutils"
+	v1 "github.com/selectel/domains-go/pkg/v1"
+	"github.com/selectel/domains-go/pkg/v1/record"
+	"github.com/selectel/domains-go/pkg/v1/v2compat"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testDomainID   = 123
+	testDomainName = "testdomain.xyz"
+)
+
+type (
+	AdapterSuite struct {
+		suite.Suite
+		fake    *fakeV1
+		server  *httptest.Server
+		adapter *v2compat.Adapter
+		zoneID  string
+	}
+
+	// fakeV1 keeps records of a single domain of the Domains API V1.
+	fakeV1 struct {
+		mu      sync.Mutex
+		records map[int]*fakeRecord
+		nextID  int
+	}
+
+	fakeRecord struct {
+		ID int `json:"id"`
+		record.CreateOpts
+	}
+)
+
+//nolint:paralleltest
+func TestAdapter(t *testing.T) {
+	suite.Run(t, new(AdapterSuite))
+}
+
+func (s *AdapterSuite) SetupTest() {
+	s.fake = &fakeV1{records: make(map[int]*fakeRecord), nextID: 1}
+	s.server = httptest.NewServer(s.fake)
+	s.adapter = v2compat.NewAdapter(v1.NewDomainsClientV1(testutils.Token, s.server.URL+"/v1"))
+	s.zoneID = strconv.Itoa(testDomainID)
+
+	priority := 10
+	//nolint: exhaustruct
+	s.fake.add(record.CreateOpts{Name: testDomainName, Type: record.TypeSOA, TTL: 86400, Content: "ns1.selectel.org"})
+	//nolint: exhaustruct
+	s.fake.add(record.CreateOpts{Name: "www." + testDomainName, Type: record.TypeA, TTL: 60, Content: "10.0.0.1"})
+	//nolint: exhaustruct
+	s.fake.add(record.CreateOpts{Name: "www." + testDomainName, Type: record.TypeA, TTL: 60, Content: "10.0.0.2"})
+	//nolint: exhaustruct
+	s.fake.add(record.CreateOpts{
+		Name: testDomainName, Type: record.TypeMX, TTL: 60, Content: "mx." + testDomainName, Priority: &priority,
+	})
+}
+
+func (s *AdapterSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *AdapterSuite) TestZones() {
+	zone, err := s.adapter.GetZone(context.Background(), s.zoneID, nil)
+
+	s.Require().NoError(err)
+	s.Equal("testdomain.xyz.", zone.Name)
+
+	zones, err := v2.ListAllZones[v2.Zone](context.Background(), s.adapter, nil)
+
+	s.Require().NoError(err)
+	s.Require().Len(zones, 1)
+	s.Equal(s.zoneID, zones[0].ID)
+
+	_, err = s.adapter.GetZone(context.Background(), "999", nil)
+	s.ErrorIs(err, v2.ErrNotFound)
+	s.ErrorIs(err, v1.ErrNotFound)
+}
+
+func (s *AdapterSuite) TestUnsupportedOperations() {
+	ctx := context.Background()
+
+	s.ErrorIs(s.adapter.UpdateZoneState(ctx, s.zoneID, true), v2compat.ErrUnsupported)
+	s.ErrorIs(s.adapter.UpdateProtectionState(ctx, s.zoneID, true), v2compat.ErrUnsupported)
+	s.ErrorIs(s.adapter.UpdateZoneComment(ctx, s.zoneID, "comment"), v2compat.ErrUnsupported)
+}
+
+func (s *AdapterSuite) TestListRRSets() {
+	rrsets, err := v2.ListAllRRSets[v2.RRSet](context.Background(), s.adapter, s.zoneID, &map[string]string{
+		"limit": "1",
+	})
+
+	s.Require().NoError(err)
+	s.Require().Len(rrsets, 2)
+	s.Equal("MX:testdomain.xyz.", rrsets[0].ID)
+	s.Equal("10 mx.testdomain.xyz.", rrsets[0].Records[0].Content)
+	s.Equal("A:www.testdomain.xyz.", rrsets[1].ID)
+	s.Len(rrsets[1].Records, 2)
+
+	list, err := s.adapter.ListRRSets(context.Background(), s.zoneID, &map[string]string{"rrset_types": "A"})
+
+	s.Require().NoError(err)
+	s.Len(list.GetItems(), 1)
+}
+
+func (s *AdapterSuite) TestCreateRRSet() {
+	//nolint: exhaustruct
+	rrset, err := s.adapter.CreateRRSet(context.Background(), s.zoneID, &v2.RRSet{
+		Name: "_sip._tcp.testdomain.xyz.",
+		Type: v2.SRV,
+		TTL:  300,
+		Records: []v2.RecordItem{
+			{Content: "10 20 5060 sip.testdomain.xyz.", Disabled: false},
+		},
+	})
+
+	s.Require().NoError(err)
+	s.Equal("SRV:_sip._tcp.testdomain.xyz.", rrset.ID)
+	s.Equal([]v2.RecordItem{{Content: "10 20 5060 sip.testdomain.xyz.", Disabled: false}}, rrset.Records)
+
+	//nolint: exhaustruct
+	_, err = s.adapter.CreateRRSet(context.Background(), s.zoneID, &v2.RRSet{
+		Name:    "www.testdomain.xyz.",
+		Type:    v2.A,
+		TTL:     60,
+		Records: []v2.RecordItem{{Content: "10.0.0.3", Disabled: false}},
+	})
+	s.ErrorIs(err, v2compat.ErrAlreadyExists)
+}
+
+func (s *AdapterSuite) TestUpdateRRSet() {
+	rrsetID := v2compat.RRSetID("www.testdomain.xyz", v2.A)
+
+	//nolint: exhaustruct
+	err := s.adapter.UpdateRRSet(context.Background(), s.zoneID, rrsetID, &v2.RRSet{
+		TTL: 300,
+		Records: []v2.RecordItem{
+			{Content: "10.0.0.2", Disabled: false},
+			{Content: "10.0.0.3", Disabled: false},
+		},
+	})
+
+	s.Require().NoError(err)
+	rrset, err := s.adapter.GetRRSet(context.Background(), s.zoneID, rrsetID)
+	s.Require().NoError(err)
+	s.Equal(300, rrset.TTL)
+	s.ElementsMatch([]v2.RecordItem{
+		{Content: "10.0.0.2", Disabled: false},
+		{Content: "10.0.0.3", Disabled: false},
+	}, rrset.Records)
+}
+
+func (s *AdapterSuite) TestDeleteRRSet() {
+	rrsetID := v2compat.RRSetID("www.testdomain.xyz.", v2.A)
+
+	s.Require().NoError(s.adapter.DeleteRRSet(context.Background(), s.zoneID, rrsetID))
+
+	_, err := s.adapter.GetRRSet(context.Background(), s.zoneID, rrsetID)
+	s.ErrorIs(err, v2.ErrNotFound)
+	s.ErrorIs(s.adapter.DeleteRRSet(context.Background(), s.zoneID, rrsetID), v2.ErrNotFound)
+}
+
+func (f *fakeV1) add(opts record.CreateOpts) *fakeRecord {
+	rec := &fakeRecord{ID: f.nextID, CreateOpts: opts}
+	f.records[rec.ID] = rec
+	f.nextID++
+
+	return rec
+}
+
+func (f *fakeV1) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1"), "/"), "/")
+	domainView := map[string]interface{}{"id": testDomainID, "name": testDomainName}
+	switch {
+	case len(parts) == 1 && parts[0] == "" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, []interface{}{domainView})
+	case parts[0] != strconv.Itoa(testDomainID):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "domain_not_found"})
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, domainView)
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		ids := make([]int, 0, len(f.records))
+		for id := range f.records {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		records := make([]*fakeRecord, 0, len(ids))
+		for _, id := range ids {
+			records = append(records, f.records[id])
+		}
+		writeJSON(w, http.StatusOK, records)
+	case len(parts) == 2 && r.Method == http.MethodPost:
+		var opts record.CreateOpts
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+
+			return
+		}
+		writeJSON(w, http.StatusOK, f.add(opts))
+	case len(parts) == 3:
+		f.serveRecord(w, r, parts[2])
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method_not_allowed"})
+	}
+}
+
+func (f *fakeV1) serveRecord(w http.ResponseWriter, r *http.Request, recordID string) {
+	id, _ := strconv.Atoi(recordID)
+	rec, ok := f.records[id]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "record_not_found"})
+
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		var opts record.CreateOpts
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+
+			return
+		}
+		rec.CreateOpts = opts
+		writeJSON(w, http.StatusOK, rec)
+	case http.MethodDelete:
+		delete(f.records, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method_not_allowed"})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
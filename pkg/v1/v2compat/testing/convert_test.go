@@ -0,0 +1,134 @@
+package testing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/selectel/domains-go/pkg/v1/record"
+	"github.com/selectel/domains-go/pkg/v1/v2compat"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestContent(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		view     record.View
+		expected string
+	}{
+		"mx": {
+			//nolint: exhaustruct
+			view:     record.View{Type: record.TypeMX, Content: "mx.example.com", Priority: intPtr(10)},
+			expected: "10 mx.example.com.",
+		},
+		"srv": {
+			//nolint: exhaustruct
+			view: record.View{
+				Type: record.TypeSRV, Priority: intPtr(10), Weight: intPtr(20), Port: intPtr(5060), Target: "sip.example.com",
+			},
+			expected: "10 20 5060 sip.example.com.",
+		},
+		"caa": {
+			//nolint: exhaustruct
+			view:     record.View{Type: record.TypeCAA, Flag: intPtr(0), Tag: "issue", Value: "letsencrypt.org"},
+			expected: `0 issue "letsencrypt.org"`,
+		},
+		"sshfp": {
+			//nolint: exhaustruct
+			view: record.View{
+				Type: record.TypeSSHFP, Algorithm: intPtr(4), FingerprintType: intPtr(2), Fingerprint: "abcdef",
+			},
+			expected: "4 2 abcdef",
+		},
+		"txt": {
+			//nolint: exhaustruct
+			view:     record.View{Type: record.TypeTXT, Content: `say "hi"`},
+			expected: `"say \"hi\""`,
+		},
+		"cname": {
+			//nolint: exhaustruct
+			view:     record.View{Type: record.TypeCNAME, Content: "origin.com"},
+			expected: "origin.com.",
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			content, err := v2compat.Content(&test.view)
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, content)
+		})
+	}
+}
+
+func TestContent_missing_field(t *testing.T) {
+	t.Parallel()
+	//nolint: exhaustruct
+	_, err := v2compat.Content(&record.View{Type: record.TypeMX, Content: "mx.example.com"})
+
+	assert.ErrorIs(t, err, v2compat.ErrMissingField)
+}
+
+func TestQuoteTXT_splits_long_data(t *testing.T) {
+	t.Parallel()
+	quoted := v2compat.QuoteTXT(strings.Repeat("a", 300))
+
+	assert.Equal(t, `"`+strings.Repeat("a", 255)+`" "`+strings.Repeat("a", 45)+`"`, quoted)
+	assert.Equal(t, `"already"`, v2compat.QuoteTXT(`"already"`))
+}
+
+func TestGroup(t *testing.T) {
+	t.Parallel()
+	//nolint: exhaustruct
+	records := []*record.View{
+		{Type: record.TypeSOA, Name: "example.com", Content: "ns1.example.com"},
+		{Type: record.TypeA, Name: "www.example.com", TTL: 300, Content: "10.0.0.1"},
+		{Type: record.TypeA, Name: "WWW.example.com", TTL: 60, Content: "10.0.0.2"},
+		{Type: record.TypeA, Name: "www.example.com", TTL: 60, Content: "10.0.0.2"},
+		{Type: record.TypeCNAME, Name: "www.example.com", TTL: 60, Content: "origin.com"},
+		{Type: record.TypeUnknown, Name: "x.example.com", TTL: 60, Content: "?"},
+	}
+
+	rrsets, conflicts := v2compat.Group(records)
+
+	require.Len(t, rrsets, 2)
+	assert.Equal(t, "www.example.com.", rrsets[0].Name)
+	assert.Equal(t, v2.A, rrsets[0].Type)
+	assert.Equal(t, 60, rrsets[0].TTL)
+	assert.Len(t, rrsets[0].Records, 2)
+	assert.Equal(t, v2.CNAME, rrsets[1].Type)
+
+	kinds := make([]v2compat.ConflictKind, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		kinds = append(kinds, conflict.Kind)
+	}
+	assert.ElementsMatch(t, []v2compat.ConflictKind{
+		v2compat.ConflictTTL, v2compat.ConflictUnsupported, v2compat.ConflictCNAME,
+	}, kinds)
+}
+
+func TestRecordOpts(t *testing.T) {
+	t.Parallel()
+	opts, err := v2compat.RecordOpts("_sip._tcp.example.com.", 60, v2.SRV, "10 20 5060 sip.example.com.")
+
+	require.NoError(t, err)
+	assert.Equal(t, "_sip._tcp.example.com", opts.Name)
+	assert.Equal(t, record.TypeSRV, opts.Type)
+	assert.Equal(t, "sip.example.com", opts.Target)
+	assert.Equal(t, 10, *opts.Priority)
+	assert.Equal(t, 20, *opts.Weight)
+	assert.Equal(t, 5060, *opts.Port)
+
+	opts, err = v2compat.RecordOpts("example.com.", 60, v2.CAA, `0 issue "letsencrypt.org"`)
+
+	require.NoError(t, err)
+	assert.Equal(t, "issue", opts.Tag)
+	assert.Equal(t, "letsencrypt.org", opts.Value)
+}
@@ -0,0 +1,217 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	v2 "github.com/selectel/domains-go/pkg/v2"
+)
+
+const (
+	// FormatVersion represents the version of the snapshot layout written by Backup.
+	FormatVersion = 1
+
+	// manifestFile represents the name of the manifest file inside a snapshot directory.
+	manifestFile = "manifest.json"
+
+	// snapshotIDLayout represents the time layout snapshot ids are built with.
+	snapshotIDLayout = "20060102T150405.000Z"
+)
+
+var (
+	ErrChecksumMismatch   = errors.New("zone file checksum mismatch")
+	ErrUnsupportedVersion = errors.New("unsupported snapshot format version")
+)
+
+type (
+	// Manifest describes a single snapshot.
+	Manifest struct {
+		Version   int             `json:"version"`
+		ID        string          `json:"id"`
+		CreatedAt time.Time       `json:"created_at"`
+		Zones     []ManifestEntry `json:"zones"`
+	}
+
+	// ManifestEntry describes a zone file of a snapshot.
+	ManifestEntry struct {
+		ZoneID     string    `json:"zone_id"`
+		Name       string    `json:"name"`
+		File       string    `json:"file"`
+		SHA256     string    `json:"sha256"`
+		RRSetCount int       `json:"rrset_count"`
+		BackedUpAt time.Time `json:"backed_up_at"`
+	}
+
+	// ZoneBackup represents the contents of a zone file.
+	ZoneBackup struct {
+		Zone   v2.Zone    `json:"zone"`
+		RRSets []v2.RRSet `json:"rrsets"`
+	}
+
+	// Target receives files of a snapshot being written.
+	// Names are slash-separated paths relative to the backup root.
+	Target interface {
+		WriteFile(name string, data []byte) error
+	}
+
+	// DirTarget writes snapshots into a local directory.
+	DirTarget string
+)
+
+// WriteFile writes data to the file inside the directory, creating parent directories as needed.
+func (d DirTarget) WriteFile(name string, data []byte) error {
+	fullPath := filepath.Join(string(d), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o750); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+
+	return os.WriteFile(fullPath, data, 0o600)
+}
+
+// Backup writes a snapshot of every zone returned by ListZones to the target.
+func Backup(ctx context.Context, manager v2.DNSManager[v2.Zone, v2.RRSet], target Target) (*Manifest, error) {
+	createdAt := time.Now().UTC()
+	manifest := &Manifest{
+		Version:   FormatVersion,
+		ID:        createdAt.Format(snapshotIDLayout),
+		CreatedAt: createdAt,
+		Zones:     nil,
+	}
+
+	zones, err := v2.ListAllZones[v2.Zone](ctx, manager, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list zones: %w", err)
+	}
+	for _, zone := range zones {
+		rrsets, err := v2.ListAllRRSets[v2.RRSet](ctx, manager, zone.ID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("list rrsets of zone %s: %w", zone.Name, err)
+		}
+		zoneBackup := ZoneBackup{Zone: *zone, RRSets: make([]v2.RRSet, 0, len(rrsets))}
+		for _, rrset := range rrsets {
+			zoneBackup.RRSets = append(zoneBackup.RRSets, *rrset)
+		}
+		sort.Slice(zoneBackup.RRSets, func(i, j int) bool {
+			a, b := zoneBackup.RRSets[i], zoneBackup.RRSets[j]
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+
+			return a.Type < b.Type
+		})
+
+		data, err := json.MarshalIndent(zoneBackup, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("marshal zone %s: %w", zone.Name, err)
+		}
+		file := path.Join("zones", zoneFileName(zone))
+		if err := target.WriteFile(path.Join(manifest.ID, file), data); err != nil {
+			return nil, fmt.Errorf("write zone %s: %w", zone.Name, err)
+		}
+		manifest.Zones = append(manifest.Zones, ManifestEntry{
+			ZoneID:     zone.ID,
+			Name:       zone.Name,
+			File:       file,
+			SHA256:     checksum(data),
+			RRSetCount: len(rrsets),
+			BackedUpAt: time.Now().UTC(),
+		})
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal manifest: %w", err)
+	}
+	if err := target.WriteFile(path.Join(manifest.ID, manifestFile), data); err != nil {
+		return nil, fmt.Errorf("write manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// ListSnapshots returns manifests of all complete snapshots found in fsys, oldest first.
+func ListSnapshots(fsys fs.FS) ([]*Manifest, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read snapshots: %w", err)
+	}
+	var manifests []*Manifest
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		manifest, err := ReadManifest(fsys, entry.Name())
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, manifest)
+	}
+	sort.Slice(manifests, func(i, j int) bool {
+		return manifests[i].CreatedAt.Before(manifests[j].CreatedAt)
+	})
+
+	return manifests, nil
+}
+
+// ReadManifest returns the manifest of the snapshot.
+func ReadManifest(fsys fs.FS, snapshotID string) (*Manifest, error) {
+	data, err := fs.ReadFile(fsys, path.Join(snapshotID, manifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("read manifest of snapshot %s: %w", snapshotID, err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parse manifest of snapshot %s: %w", snapshotID, err)
+	}
+	if manifest.Version != FormatVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, manifest.Version)
+	}
+
+	return &manifest, nil
+}
+
+// ReadZone returns the zone file described by the manifest entry after verifying its checksum.
+func ReadZone(fsys fs.FS, snapshotID string, entry ManifestEntry) (*ZoneBackup, error) {
+	data, err := fs.ReadFile(fsys, path.Join(snapshotID, entry.File))
+	if err != nil {
+		return nil, fmt.Errorf("read zone %s: %w", entry.Name, err)
+	}
+	if checksum(data) != entry.SHA256 {
+		return nil, fmt.Errorf("%w: %s", ErrChecksumMismatch, entry.File)
+	}
+	var zoneBackup ZoneBackup
+	if err := json.Unmarshal(data, &zoneBackup); err != nil {
+		return nil, fmt.Errorf("parse zone %s: %w", entry.Name, err)
+	}
+
+	return &zoneBackup, nil
+}
+
+func zoneFileName(zone *v2.Zone) string {
+	name := strings.TrimSuffix(strings.ToLower(zone.Name), ".")
+	if name == "" {
+		name = "root"
+	}
+
+	return strings.NewReplacer("/", "_", "\\", "_").Replace(name) + ".json"
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
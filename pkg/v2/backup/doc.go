@@ -0,0 +1,32 @@
+/*
+Package backup saves zones of the Selectel Domains API V2 to versioned
+snapshots and restores them.
+
+A snapshot is a directory named after the time it was taken. It contains a
+JSON file with the zone and its rrsets for every zone of the project and a
+manifest listing those files with their SHA-256 checksums. The manifest is
+written last, so an interrupted backup never looks like a complete snapshot.
+
+Example of taking a snapshot
+
+  manifest, err := backup.Backup(ctx, client, backup.DirTarget("/var/backups/dns"))
+  if err != nil {
+    log.Fatal(err)
+  }
+  fmt.Println("snapshot", manifest.ID)
+
+Example of restoring the latest snapshot
+
+  fsys := os.DirFS("/var/backups/dns")
+  snapshots, err := backup.ListSnapshots(fsys)
+  if err != nil {
+    log.Fatal(err)
+  }
+  latest := snapshots[len(snapshots)-1]
+  report, err := backup.Restore(ctx, client, fsys, latest.ID, nil)
+  if err != nil {
+    log.Fatal(err)
+  }
+  fmt.Printf("%+v\n", report)
+*/
+package backup
@@ -0,0 +1,200 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strings"
+
+	v2 "github.com/selectel/domains-go/pkg/v2"
+)
+
+type (
+	// RestoreOpts represents options of a restore.
+	RestoreOpts struct {
+		// Zones limits the restore to zones with these names. All zones are restored if empty.
+		Zones []string
+
+		// Prune deletes rrsets that are absent from the snapshot.
+		// Rrsets of protected zones are never deleted.
+		Prune bool
+	}
+
+	// RestoreReport describes changes made by a restore.
+	RestoreReport struct {
+		Zones []ZoneReport
+	}
+
+	// ZoneReport describes changes made to a single zone by a restore.
+	ZoneReport struct {
+		Name          string
+		ZoneID        string
+		Created       bool
+		RRSetsCreated int
+		RRSetsUpdated int
+		RRSetsDeleted int
+		// Skipped lists rrsets left untouched, e.g. extra rrsets of a protected zone.
+		Skipped []string
+		Err     error
+	}
+
+	rrsetKey struct {
+		name       string
+		recordType v2.RecordType
+	}
+)
+
+// Restore recreates missing zones and reconciles rrsets with the snapshot.
+// Zone comment, state and protection are set to the snapshot values after rrsets are restored.
+// A failure in one zone doesn't stop others from being restored,
+// errors of all zones are returned joined and are also available in the report.
+func Restore(
+	ctx context.Context, manager v2.DNSManager[v2.Zone, v2.RRSet], fsys fs.FS, snapshotID string, opts *RestoreOpts,
+) (*RestoreReport, error) {
+	if opts == nil {
+		opts = &RestoreOpts{Zones: nil, Prune: false}
+	}
+	manifest, err := ReadManifest(fsys, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	zones, err := v2.ListAllZones[v2.Zone](ctx, manager, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list zones: %w", err)
+	}
+	existing := make(map[string]*v2.Zone, len(zones))
+	for _, zone := range zones {
+		existing[canonicalName(zone.Name)] = zone
+	}
+
+	report := &RestoreReport{Zones: nil}
+	var errs []error
+	for _, entry := range manifest.Zones {
+		if !selected(opts.Zones, entry.Name) {
+			continue
+		}
+		//nolint: exhaustruct
+		zoneReport := ZoneReport{Name: entry.Name}
+		zoneBackup, err := ReadZone(fsys, snapshotID, entry)
+		if err == nil {
+			err = restoreZone(ctx, manager, zoneBackup, existing[canonicalName(entry.Name)], opts, &zoneReport)
+		}
+		if err != nil {
+			zoneReport.Err = err
+			errs = append(errs, fmt.Errorf("zone %s: %w", entry.Name, err))
+		}
+		report.Zones = append(report.Zones, zoneReport)
+	}
+
+	return report, errors.Join(errs...)
+}
+
+func restoreZone(
+	ctx context.Context,
+	manager v2.DNSManager[v2.Zone, v2.RRSet],
+	zoneBackup *ZoneBackup,
+	zone *v2.Zone,
+	opts *RestoreOpts,
+	report *ZoneReport,
+) error {
+	if zone == nil {
+		//nolint: exhaustruct
+		created, err := manager.CreateZone(ctx, &v2.Zone{Name: zoneBackup.Zone.Name})
+		if err != nil {
+			return fmt.Errorf("create zone: %w", err)
+		}
+		zone = created
+		report.Created = true
+	}
+	report.ZoneID = zone.ID
+
+	current, err := v2.ListAllRRSets[v2.RRSet](ctx, manager, zone.ID, nil)
+	if err != nil {
+		return fmt.Errorf("list rrsets: %w", err)
+	}
+	currentIndex := make(map[rrsetKey]*v2.RRSet, len(current))
+	for _, rrset := range current {
+		currentIndex[keyOf(rrset)] = rrset
+	}
+
+	restored := make(map[rrsetKey]bool, len(zoneBackup.RRSets))
+	for i := range zoneBackup.RRSets {
+		rrset := &zoneBackup.RRSets[i]
+		key := keyOf(rrset)
+		restored[key] = true
+		// SOA is maintained by the API.
+		if rrset.Type == v2.SOA {
+			continue
+		}
+		existing, ok := currentIndex[key]
+		switch {
+		case !ok:
+			if _, err := manager.CreateRRSet(ctx, zone.ID, rrset); err != nil {
+				return fmt.Errorf("create rrset %s %s: %w", rrset.Name, rrset.Type, err)
+			}
+			report.RRSetsCreated++
+		case !sameContents(existing, rrset):
+			if err := manager.UpdateRRSet(ctx, zone.ID, existing.ID, rrset); err != nil {
+				return fmt.Errorf("update rrset %s %s: %w", rrset.Name, rrset.Type, err)
+			}
+			report.RRSetsUpdated++
+		}
+	}
+
+	for _, rrset := range current {
+		if restored[keyOf(rrset)] || rrset.Type == v2.SOA || !opts.Prune {
+			continue
+		}
+		if zone.Protected {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s %s", rrset.Name, rrset.Type))
+
+			continue
+		}
+		if err := manager.DeleteRRSet(ctx, zone.ID, rrset.ID); err != nil {
+			return fmt.Errorf("delete rrset %s %s: %w", rrset.Name, rrset.Type, err)
+		}
+		report.RRSetsDeleted++
+	}
+
+	//nolint: exhaustruct
+	update := &v2.ZoneUpdateOpts{}
+	if zone.Comment != zoneBackup.Zone.Comment {
+		update.Comment = &zoneBackup.Zone.Comment
+	}
+	if zone.Disabled != zoneBackup.Zone.Disabled {
+		update.Disabled = &zoneBackup.Zone.Disabled
+	}
+	if zone.Protected != zoneBackup.Zone.Protected {
+		update.Protected = &zoneBackup.Zone.Protected
+	}
+
+	return v2.UpdateZone[v2.Zone](ctx, manager, zone.ID, update)
+}
+
+func sameContents(a, b *v2.RRSet) bool {
+	return a.TTL == b.TTL && a.Comment == b.Comment && a.ManagedBy == b.ManagedBy &&
+		reflect.DeepEqual(a.Records, b.Records)
+}
+
+func keyOf(rrset *v2.RRSet) rrsetKey {
+	return rrsetKey{name: canonicalName(rrset.Name), recordType: rrset.Type}
+}
+
+func canonicalName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(name), ".")
+}
+
+func selected(names []string, name string) bool {
+	if len(names) == 0 {
+		return true
+	}
+	for _, n := range names {
+		if canonicalName(n) == canonicalName(name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,160 @@
+package testing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/selectel/domains-go/pkg/testutils"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/backup"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testZoneName  = "bonnie-test.com."
+	testOtherZone = "clyde-test.com."
+)
+
+type (
+	BackupSuite struct {
+		suite.Suite
+		api  *testutils.FakeAPI
+		zone *v2.Zone
+		dir  string
+	}
+)
+
+//nolint:paralleltest
+func TestBackup(t *testing.T) {
+	suite.Run(t, new(BackupSuite))
+}
+
+func (s *BackupSuite) SetupTest() {
+	s.api = testutils.NewFakeAPI()
+	s.api.PageSize = 2
+	s.zone = s.api.AddZone(testZoneName)
+	s.addRRSet(s.zone.ID, "www.bonnie-test.com.", v2.A, "10.0.0.1")
+	s.addRRSet(s.zone.ID, "bonnie-test.com.", v2.MX, "10 mx.bonnie-test.com.")
+	s.addRRSet(s.zone.ID, "bonnie-test.com.", v2.TXT, `"v=spf1 -all"`)
+	other := s.api.AddZone(testOtherZone)
+	s.addRRSet(other.ID, "clyde-test.com.", v2.A, "10.0.0.2")
+	s.dir = s.T().TempDir()
+}
+
+func (s *BackupSuite) TearDownTest() {
+	s.api.Close()
+}
+
+func (s *BackupSuite) addRRSet(zoneID, name string, recordType v2.RecordType, content string) *v2.RRSet {
+	//nolint: exhaustruct
+	rrset := v2.RRSet{
+		Name:    name,
+		Type:    recordType,
+		TTL:     60,
+		Records: []v2.RecordItem{{Content: content, Disabled: false}},
+	}
+
+	return s.api.AddRRSet(zoneID, rrset)
+}
+
+func (s *BackupSuite) backup() *backup.Manifest {
+	manifest, err := backup.Backup(context.Background(), s.api.Client(), backup.DirTarget(s.dir))
+	s.Require().NoError(err)
+
+	return manifest
+}
+
+func (s *BackupSuite) TestBackup_writes_manifest() {
+	manifest := s.backup()
+
+	s.Equal(backup.FormatVersion, manifest.Version)
+	s.Require().Len(manifest.Zones, 2)
+	s.Equal(testZoneName, manifest.Zones[0].Name)
+	s.Equal(3, manifest.Zones[0].RRSetCount)
+
+	snapshots, err := backup.ListSnapshots(os.DirFS(s.dir))
+	s.Require().NoError(err)
+	s.Require().Len(snapshots, 1)
+	s.Equal(manifest.ID, snapshots[0].ID)
+
+	zoneBackup, err := backup.ReadZone(os.DirFS(s.dir), manifest.ID, manifest.Zones[0])
+	s.Require().NoError(err)
+	s.Len(zoneBackup.RRSets, 3)
+}
+
+func (s *BackupSuite) TestReadZone_checksum_mismatch() {
+	manifest := s.backup()
+	file := filepath.Join(s.dir, manifest.ID, filepath.FromSlash(manifest.Zones[0].File))
+	s.Require().NoError(os.WriteFile(file, []byte(`{"zone":{},"rrsets":[]}`), 0o600))
+
+	_, err := backup.ReadZone(os.DirFS(s.dir), manifest.ID, manifest.Zones[0])
+	s.ErrorIs(err, backup.ErrChecksumMismatch)
+
+	report, err := backup.Restore(context.Background(), s.api.Client(), os.DirFS(s.dir), manifest.ID, nil)
+	s.ErrorIs(err, backup.ErrChecksumMismatch)
+	s.Require().Len(report.Zones, 2)
+	s.Error(report.Zones[0].Err)
+	s.NoError(report.Zones[1].Err)
+}
+
+func (s *BackupSuite) TestRestore_recreates_deleted_zone() {
+	manifest := s.backup()
+	client := s.api.Client()
+	s.Require().NoError(client.DeleteZone(context.Background(), s.zone.ID))
+
+	//nolint: exhaustruct
+	report, err := backup.Restore(context.Background(), client, os.DirFS(s.dir), manifest.ID, &backup.RestoreOpts{
+		Zones: []string{"bonnie-test.com"},
+	})
+
+	s.Require().NoError(err)
+	s.Require().Len(report.Zones, 1)
+	s.True(report.Zones[0].Created)
+	s.Equal(3, report.Zones[0].RRSetsCreated)
+	s.Len(s.api.RRSets(report.Zones[0].ZoneID), 3)
+}
+
+func (s *BackupSuite) TestRestore_reconciles_rrsets() {
+	manifest := s.backup()
+	rrsets := s.api.RRSets(s.zone.ID)
+	client := s.api.Client()
+	s.Require().NoError(client.DeleteRRSet(context.Background(), s.zone.ID, rrsets[0].ID))
+	changed := rrsets[1]
+	changed.TTL = 3600
+	s.Require().NoError(client.UpdateRRSet(context.Background(), s.zone.ID, changed.ID, &changed))
+	s.addRRSet(s.zone.ID, "extra.bonnie-test.com.", v2.A, "10.0.0.9")
+
+	report, err := backup.Restore(context.Background(), client, os.DirFS(s.dir), manifest.ID, &backup.RestoreOpts{
+		Zones: []string{testZoneName},
+		Prune: true,
+	})
+
+	s.Require().NoError(err)
+	s.Require().Len(report.Zones, 1)
+	s.False(report.Zones[0].Created)
+	s.Equal(1, report.Zones[0].RRSetsCreated)
+	s.Equal(1, report.Zones[0].RRSetsUpdated)
+	s.Equal(1, report.Zones[0].RRSetsDeleted)
+	s.Len(s.api.RRSets(s.zone.ID), 3)
+}
+
+func (s *BackupSuite) TestRestore_keeps_extra_rrsets_of_protected_zone() {
+	manifest := s.backup()
+	client := s.api.Client()
+	s.addRRSet(s.zone.ID, "extra.bonnie-test.com.", v2.A, "10.0.0.9")
+	s.Require().NoError(client.UpdateProtectionState(context.Background(), s.zone.ID, true))
+
+	report, err := backup.Restore(context.Background(), client, os.DirFS(s.dir), manifest.ID, &backup.RestoreOpts{
+		Zones: []string{testZoneName},
+		Prune: true,
+	})
+
+	s.Require().NoError(err)
+	s.Equal([]string{"extra.bonnie-test.com. A"}, report.Zones[0].Skipped)
+	s.Len(s.api.RRSets(s.zone.ID), 4)
+	// Protection is restored to the snapshot value after rrsets.
+	zones := s.api.Zones()
+	s.False(zones[0].Protected)
+}
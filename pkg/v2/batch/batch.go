@@ -0,0 +1,250 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	v2 "github.com/selectel/domains-go/pkg/v2"
+)
+
+// defaultWorkers represents the default number of operations executed concurrently.
+const defaultWorkers = 4
+
+// Kinds of operations.
+const (
+	KindCreate Kind = "create"
+	KindUpdate Kind = "update"
+	KindDelete Kind = "delete"
+)
+
+var (
+	ErrSkipped          = errors.New("operation skipped after a previous failure")
+	ErrInvalidOperation = errors.New("invalid operation")
+)
+
+type (
+	// Kind represents the kind of an operation.
+	Kind string
+
+	// Form represents an rrset body that can be used both to create and to update an rrset.
+	Form interface {
+		v2.Creatable
+		v2.Updatable
+	}
+
+	// Operation represents a single change of an rrset.
+	Operation struct {
+		Kind Kind
+		// RRSetID identifies the rrset to update or delete.
+		RRSetID string
+		// RRSet holds the body of a created or updated rrset.
+		RRSet Form
+		// Stage orders operations: all operations of a stage finish before the next stage starts.
+		Stage int
+	}
+
+	// Opts represents options of a batch run.
+	Opts struct {
+		// Workers limits the number of operations executed concurrently.
+		// If zero, defaultWorkers is used.
+		Workers int
+
+		// StopOnError stops starting new operations after the first failure.
+		// Operations that are not started are reported with ErrSkipped.
+		StopOnError bool
+
+		// DeletesFirst runs deletes of every stage before its creates and updates.
+		DeletesFirst bool
+	}
+
+	// Result describes the outcome of a single operation.
+	Result[S any] struct {
+		// Index is the position of the operation in the batch.
+		Index     int
+		Operation Operation
+		// Created holds the rrset returned by the API for a create operation.
+		Created *S
+		Err     error
+	}
+
+	// Report holds results of all operations in the order they were given.
+	Report[S any] struct {
+		Results []Result[S]
+	}
+)
+
+// Create returns an operation that creates the rrset.
+func Create(rrset Form) Operation {
+	return Operation{Kind: KindCreate, RRSetID: "", RRSet: rrset, Stage: 0}
+}
+
+// Update returns an operation that updates the rrset with the given id.
+func Update(rrsetID string, rrset Form) Operation {
+	return Operation{Kind: KindUpdate, RRSetID: rrsetID, RRSet: rrset, Stage: 0}
+}
+
+// Delete returns an operation that deletes the rrset with the given id.
+func Delete(rrsetID string) Operation {
+	return Operation{Kind: KindDelete, RRSetID: rrsetID, RRSet: nil, Stage: 0}
+}
+
+// Succeeded returns the number of operations that were executed without errors.
+func (r *Report[S]) Succeeded() int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Err == nil {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Failed returns results of operations that failed or were skipped.
+func (r *Report[S]) Failed() []Result[S] {
+	var failed []Result[S]
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+
+	return failed
+}
+
+// Run executes operations on rrsets of the zone.
+// The report is always returned, the error joins errors of all failed operations.
+func Run[S any](
+	ctx context.Context, manager v2.RRSetManager[S], zoneID string, ops []Operation, opts *Opts,
+) (*Report[S], error) {
+	if opts == nil {
+		opts = &Opts{Workers: defaultWorkers, StopOnError: false, DeletesFirst: false}
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+
+	report := &Report[S]{Results: make([]Result[S], len(ops))}
+	for i, op := range ops {
+		report.Results[i] = Result[S]{Index: i, Operation: op, Created: nil, Err: ErrSkipped}
+	}
+	var stopped atomic.Bool
+	for _, phase := range phases(ops, opts.DeletesFirst) {
+		if stopped.Load() || ctx.Err() != nil {
+			break
+		}
+		runPhase(ctx, manager, zoneID, phase, report.Results, workers, opts.StopOnError, &stopped)
+	}
+
+	var errs []error
+	for _, result := range report.Results {
+		if result.Err != nil && !errors.Is(result.Err, ErrSkipped) {
+			errs = append(errs, fmt.Errorf("operation %d (%s): %w", result.Index, result.Operation.Kind, result.Err))
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return report, errors.Join(errs...)
+}
+
+func runPhase[S any](
+	ctx context.Context,
+	manager v2.RRSetManager[S],
+	zoneID string,
+	phase []int,
+	results []Result[S],
+	workers int,
+	stopOnError bool,
+	stopped *atomic.Bool,
+) {
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				// The operation may have been dispatched before another worker failed.
+				if stopped.Load() {
+					continue
+				}
+				result := &results[i]
+				result.Created, result.Err = execute(ctx, manager, zoneID, result.Operation)
+				if result.Err != nil && stopOnError {
+					stopped.Store(true)
+				}
+			}
+		}()
+	}
+	for _, i := range phase {
+		if stopped.Load() || ctx.Err() != nil {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+func execute[S any](ctx context.Context, manager v2.RRSetManager[S], zoneID string, op Operation) (*S, error) {
+	switch op.Kind {
+	case KindCreate:
+		if op.RRSet == nil {
+			return nil, fmt.Errorf("%w: create without rrset", ErrInvalidOperation)
+		}
+
+		return manager.CreateRRSet(ctx, zoneID, op.RRSet)
+	case KindUpdate:
+		if op.RRSet == nil || op.RRSetID == "" {
+			return nil, fmt.Errorf("%w: update requires rrset id and rrset", ErrInvalidOperation)
+		}
+
+		return nil, manager.UpdateRRSet(ctx, zoneID, op.RRSetID, op.RRSet)
+	case KindDelete:
+		if op.RRSetID == "" {
+			return nil, fmt.Errorf("%w: delete without rrset id", ErrInvalidOperation)
+		}
+
+		return nil, manager.DeleteRRSet(ctx, zoneID, op.RRSetID)
+	}
+
+	return nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidOperation, op.Kind)
+}
+
+// phases groups indexes of operations into sets that are executed one after another.
+func phases(ops []Operation, deletesFirst bool) [][]int {
+	type phaseKey struct {
+		stage  int
+		delete bool
+	}
+	index := make(map[phaseKey][]int)
+	var keys []phaseKey
+	for i, op := range ops {
+		key := phaseKey{stage: op.Stage, delete: deletesFirst && op.Kind == KindDelete}
+		if _, ok := index[key]; !ok {
+			keys = append(keys, key)
+		}
+		index[key] = append(index[key], i)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].stage != keys[j].stage {
+			return keys[i].stage < keys[j].stage
+		}
+
+		return keys[i].delete && !keys[j].delete
+	})
+
+	result := make([][]int, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, index[key])
+	}
+
+	return result
+}
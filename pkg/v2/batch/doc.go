@@ -0,0 +1,32 @@
+/*
+Package batch applies many rrset changes of the Selectel Domains API V2 at once.
+
+Operations are executed by a pool of workers. Operations with a lower Stage
+finish before operations with a higher one start, and DeletesFirst runs the
+deletes of every stage before its creates and updates, which is needed to
+replace records with a CNAME of the same name. By default all operations are
+attempted; StopOnError stops starting new operations after the first failure.
+The report holds a result for every operation in the order they were given.
+
+Example of creating many rrsets
+
+  ops := make([]batch.Operation, 0, len(rrsets))
+  for _, rrset := range rrsets {
+    ops = append(ops, batch.Create(rrset))
+  }
+  report, err := batch.Run[v2.RRSet](ctx, client, zoneID, ops, &batch.Opts{Workers: 8})
+  if err != nil {
+    for _, result := range report.Failed() {
+      fmt.Println(result.Index, result.Err)
+    }
+  }
+
+Example of replacing A records with a CNAME
+
+  ops := []batch.Operation{
+    batch.Delete(aRRSetID),
+    batch.Create(cnameRRSet),
+  }
+  _, err := batch.Run[v2.RRSet](ctx, client, zoneID, ops, &batch.Opts{DeletesFirst: true, StopOnError: true})
+*/
+package batch
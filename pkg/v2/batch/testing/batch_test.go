@@ -0,0 +1,146 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/selectel/domains-go/pkg/testutils"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/batch"
+	"github.com/stretchr/testify/suite"
+)
+
+const testZoneName = "bonnie-test.com."
+
+type (
+	BatchSuite struct {
+		suite.Suite
+		api  *testutils.FakeAPI
+		zone *v2.Zone
+	}
+)
+
+//nolint:paralleltest
+func TestBatch(t *testing.T) {
+	suite.Run(t, new(BatchSuite))
+}
+
+func (s *BatchSuite) SetupTest() {
+	s.api = testutils.NewFakeAPI()
+	s.zone = s.api.AddZone(testZoneName)
+}
+
+func (s *BatchSuite) TearDownTest() {
+	s.api.Close()
+}
+
+func (s *BatchSuite) TestRun_create() {
+	ops := make([]batch.Operation, 0, 20)
+	for i := 0; i < 20; i++ {
+		ops = append(ops, batch.Create(testutils.NewRRSet(fmt.Sprintf("host%d.%s", i, testZoneName), v2.A, "10.0.0.1")))
+	}
+
+	report, err := batch.Run[v2.RRSet](context.Background(), s.api.Client(), s.zone.ID, ops, &batch.Opts{
+		Workers: 5, StopOnError: false, DeletesFirst: false,
+	})
+
+	s.Require().NoError(err)
+	s.Equal(20, report.Succeeded())
+	s.Empty(report.Failed())
+	s.Len(s.api.RRSets(s.zone.ID), 20)
+	for i, result := range report.Results {
+		s.Equal(i, result.Index)
+		s.Require().NotNil(result.Created)
+		s.Equal(fmt.Sprintf("host%d.%s", i, testZoneName), result.Created.Name)
+	}
+}
+
+func (s *BatchSuite) TestRun_continue_on_error() {
+	first := s.api.AddRRSet(s.zone.ID, *testutils.NewRRSet("a."+testZoneName, v2.A, "10.0.0.1"))
+	second := s.api.AddRRSet(s.zone.ID, *testutils.NewRRSet("b."+testZoneName, v2.A, "10.0.0.1"))
+	s.api.InjectFault(func(r *http.Request) int {
+		if r.Method == http.MethodDelete && strings.HasSuffix(r.URL.Path, "/"+first.ID) {
+			return http.StatusInternalServerError
+		}
+
+		return 0
+	})
+	ops := []batch.Operation{
+		batch.Delete(first.ID),
+		batch.Delete(second.ID),
+		batch.Create(testutils.NewRRSet("c."+testZoneName, v2.A, "10.0.0.1")),
+	}
+
+	report, err := batch.Run[v2.RRSet](context.Background(), s.api.Client(), s.zone.ID, ops, nil)
+
+	s.Require().Error(err)
+	s.Equal(2, report.Succeeded())
+	failed := report.Failed()
+	s.Require().Len(failed, 1)
+	s.Equal(0, failed[0].Index)
+	var badResponse *v2.BadResponseError
+	s.ErrorAs(err, &badResponse)
+}
+
+func (s *BatchSuite) TestRun_stop_on_error() {
+	ops := []batch.Operation{
+		batch.Delete("missing"),
+		batch.Create(testutils.NewRRSet("a."+testZoneName, v2.A, "10.0.0.1")),
+		batch.Create(testutils.NewRRSet("b."+testZoneName, v2.A, "10.0.0.1")),
+	}
+
+	report, err := batch.Run[v2.RRSet](context.Background(), s.api.Client(), s.zone.ID, ops, &batch.Opts{
+		Workers: 1, StopOnError: true, DeletesFirst: false,
+	})
+
+	s.Require().ErrorIs(err, v2.ErrNotFound)
+	s.NotErrorIs(err, batch.ErrSkipped)
+	s.ErrorIs(report.Results[1].Err, batch.ErrSkipped)
+	s.ErrorIs(report.Results[2].Err, batch.ErrSkipped)
+	s.Empty(s.api.RRSets(s.zone.ID))
+}
+
+func (s *BatchSuite) TestRun_deletes_first() {
+	old := s.api.AddRRSet(s.zone.ID, *testutils.NewRRSet("www."+testZoneName, v2.A, "10.0.0.1"))
+	ops := []batch.Operation{
+		batch.Create(testutils.NewRRSet("www."+testZoneName, v2.CNAME, "origin.com.")),
+		batch.Delete(old.ID),
+	}
+
+	_, err := batch.Run[v2.RRSet](context.Background(), s.api.Client(), s.zone.ID, ops, &batch.Opts{
+		Workers: 4, StopOnError: true, DeletesFirst: true,
+	})
+
+	s.Require().NoError(err)
+	requests := s.api.Requests()
+	s.Require().Len(requests, 2)
+	s.True(strings.HasPrefix(requests[0], http.MethodDelete))
+	s.True(strings.HasPrefix(requests[1], http.MethodPost))
+}
+
+func (s *BatchSuite) TestRun_stages() {
+	ops := []batch.Operation{
+		batch.Create(testutils.NewRRSet("b."+testZoneName, v2.A, "10.0.0.1")),
+		batch.Create(testutils.NewRRSet("a."+testZoneName, v2.A, "10.0.0.1")),
+	}
+	ops[0].Stage = 1
+
+	_, err := batch.Run[v2.RRSet](context.Background(), s.api.Client(), s.zone.ID, ops, nil)
+
+	s.Require().NoError(err)
+	rrsets := s.api.RRSets(s.zone.ID)
+	s.Require().Len(rrsets, 2)
+	s.Equal("a."+testZoneName, rrsets[0].Name)
+}
+
+func (s *BatchSuite) TestRun_invalid_operation() {
+	ops := []batch.Operation{batch.Update("", nil)}
+
+	report, err := batch.Run[v2.RRSet](context.Background(), s.api.Client(), s.zone.ID, ops, nil)
+
+	s.ErrorIs(err, batch.ErrInvalidOperation)
+	s.Equal(0, report.Succeeded())
+}
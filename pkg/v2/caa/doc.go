@@ -0,0 +1,38 @@
+/*
+Package caa builds, parses and evaluates CAA rrsets of the Selectel Domains
+API V2.
+
+A Policy holds the issue, issuewild and iodef properties of a name, with the
+critical flag and RFC 8657 parameters such as accounturi and
+validationmethods, and renders them to rrset records. An Evaluator loads
+rrsets of zones and implements the tree-climbing algorithm of RFC 8659 to
+answer whether a CA may issue a certificate before it is requested.
+
+Example of publishing a policy
+
+  policy := caa.NewPolicy().
+    Issue("letsencrypt.org", caa.Parameter{Key: caa.ParamValidationMethods, Value: "dns-01"}).
+    DenyWild().
+    Iodef("mailto:security@example.com")
+  if err := policy.Validate(); err != nil {
+    log.Fatal(err)
+  }
+  if _, err := v2.UpsertRRSet(ctx, client, zone.ID, policy.RRSet(zone.Name, 3600)); err != nil {
+    log.Fatal(err)
+  }
+
+Example of checking issuance
+
+  evaluator := caa.NewEvaluator()
+  if err := evaluator.LoadZone(ctx, client, zone); err != nil {
+    log.Fatal(err)
+  }
+  decision, err := evaluator.Check(caa.Request{Name: "www.example.com", Issuer: "letsencrypt.org"})
+  if err != nil {
+    log.Fatal(err)
+  }
+  if !decision.Allowed {
+    log.Printf("issuance denied by CAA of %s: %s", decision.RelevantName, decision.Reason)
+  }
+*/
+package caa
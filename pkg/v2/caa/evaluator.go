@@ -0,0 +1,195 @@
+package caa
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/rrconv"
+)
+
+// maxCNAMEHops represents the maximum length of a CNAME chain followed while looking up CAA rrsets.
+const maxCNAMEHops = 8
+
+type (
+	// Evaluator answers whether a CA may issue a certificate for a name
+	// using rrsets of zones loaded from the API. Names outside the loaded zones
+	// are considered to have no CAA rrsets.
+	Evaluator struct {
+		rrsets map[string][]*v2.RRSet
+	}
+
+	// Request describes a certificate a CA is asked to issue.
+	Request struct {
+		// Name is the domain name of the certificate, names starting with "*." are wildcard names.
+		Name string
+		// Issuer is the issuer domain name of the CA, e.g. "letsencrypt.org".
+		Issuer string
+		// AccountURI and ValidationMethod are checked against RFC 8657 parameters if set.
+		AccountURI       string
+		ValidationMethod string
+	}
+
+	// Decision describes the outcome of an evaluation.
+	Decision struct {
+		Allowed bool
+		// RelevantName is the name whose CAA rrset was used, it is empty if no name has one.
+		RelevantName string
+		// Property is the property that allowed issuance, if any.
+		Property *Property
+		Reason   string
+	}
+)
+
+// NewEvaluator returns an evaluator without zones.
+func NewEvaluator() *Evaluator {
+	return &Evaluator{rrsets: make(map[string][]*v2.RRSet)}
+}
+
+// AddRRSets makes the evaluator use the rrsets.
+func (e *Evaluator) AddRRSets(rrsets []*v2.RRSet) {
+	for _, rrset := range rrsets {
+		name := rrconv.CanonicalName(rrset.Name)
+		e.rrsets[name] = append(e.rrsets[name], rrset)
+	}
+}
+
+// LoadZone lists rrsets of the zone and adds them to the evaluator.
+func (e *Evaluator) LoadZone(ctx context.Context, manager v2.RRSetManager[v2.RRSet], zone *v2.Zone) error {
+	rrsets, err := v2.ListAllRRSets[v2.RRSet](ctx, manager, zone.ID, nil)
+	if err != nil {
+		return fmt.Errorf("list rrsets of %s: %w", zone.Name, err)
+	}
+	e.AddRRSets(rrsets)
+
+	return nil
+}
+
+// RelevantPolicy returns the relevant CAA policy of the name found by climbing the tree
+// as RFC 8659 describes, together with the name it was found at.
+// A nil policy means no CA is restricted.
+func (e *Evaluator) RelevantPolicy(name string) (*Policy, string, error) {
+	name = rrconv.CanonicalName(strings.TrimPrefix(name, "*."))
+	for {
+		policy, err := e.lookup(name)
+		if err != nil {
+			return nil, name, err
+		}
+		if policy != nil && len(policy.Properties) > 0 {
+			return policy, name, nil
+		}
+		if name == "." {
+			return nil, "", nil
+		}
+		labels := dns.SplitDomainName(name)
+		name = rrconv.CanonicalName(strings.Join(labels[1:], "."))
+	}
+}
+
+// Check evaluates the request against the relevant CAA policy.
+func (e *Evaluator) Check(request Request) (*Decision, error) {
+	policy, relevantName, err := e.RelevantPolicy(request.Name)
+	if err != nil {
+		return nil, err
+	}
+	//nolint: exhaustruct
+	decision := &Decision{RelevantName: relevantName}
+	if policy == nil {
+		decision.Allowed, decision.Reason = true, "no CAA rrset"
+
+		return decision, nil
+	}
+	for _, property := range policy.Properties {
+		if property.Critical && !property.Known() {
+			decision.Reason = fmt.Sprintf("unknown critical property %s", property.Tag)
+
+			return decision, nil
+		}
+	}
+	tag := TagIssue
+	if strings.HasPrefix(request.Name, "*.") && hasTag(policy, TagIssueWild) {
+		tag = TagIssueWild
+	}
+	if !hasTag(policy, tag) {
+		decision.Allowed, decision.Reason = true, "no issue properties"
+
+		return decision, nil
+	}
+	issuerDomain := strings.ToLower(strings.TrimSuffix(request.Issuer, "."))
+	decision.Reason = fmt.Sprintf("%s is not allowed by %s properties", issuerDomain, tag)
+	for i, property := range policy.Properties {
+		if property.Tag != tag {
+			continue
+		}
+		issuer, err := property.Issuer()
+		// Malformed properties don't allow issuance but still restrict it.
+		if err != nil || issuer.Domain != issuerDomain {
+			continue
+		}
+		if reason := checkParameters(issuer, request); reason != "" {
+			decision.Reason = reason
+
+			continue
+		}
+		decision.Allowed, decision.Property, decision.Reason = true, &policy.Properties[i], ""
+
+		return decision, nil
+	}
+
+	return decision, nil
+}
+
+// lookup returns the CAA policy of the name, following CNAMEs of loaded rrsets.
+func (e *Evaluator) lookup(name string) (*Policy, error) {
+	for hop := 0; hop <= maxCNAMEHops; hop++ {
+		var cname string
+		for _, rrset := range e.rrsets[name] {
+			switch rrset.Type {
+			case v2.CAA:
+				return ParsePolicy(rrset)
+			case v2.CNAME:
+				for _, item := range rrset.Records {
+					if !item.Disabled {
+						cname = rrconv.CanonicalName(item.Content)
+					}
+				}
+			}
+		}
+		if cname == "" {
+			return nil, nil
+		}
+		name = cname
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrCNAMELoop, name)
+}
+
+func checkParameters(issuer Issuer, request Request) string {
+	if accountURI, ok := issuer.Parameter(ParamAccountURI); ok && request.AccountURI != "" &&
+		accountURI != request.AccountURI {
+		return fmt.Sprintf("account %s is not allowed", request.AccountURI)
+	}
+	if methods, ok := issuer.Parameter(ParamValidationMethods); ok && request.ValidationMethod != "" {
+		for _, method := range strings.Split(methods, ",") {
+			if strings.TrimSpace(method) == request.ValidationMethod {
+				return ""
+			}
+		}
+
+		return fmt.Sprintf("validation method %s is not allowed", request.ValidationMethod)
+	}
+
+	return ""
+}
+
+func hasTag(policy *Policy, tag string) bool {
+	for _, property := range policy.Properties {
+		if property.Tag == tag {
+			return true
+		}
+	}
+
+	return false
+}
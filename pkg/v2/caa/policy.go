@@ -0,0 +1,122 @@
+package caa
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	v2 "github.com/selectel/domains-go/pkg/v2"
+)
+
+// Policy represents the CAA rrset of a name.
+type Policy struct {
+	Properties []Property
+}
+
+// NewPolicy returns an empty policy.
+func NewPolicy() *Policy {
+	return &Policy{Properties: nil}
+}
+
+// ParsePolicy parses enabled records of a CAA rrset.
+func ParsePolicy(rrset *v2.RRSet) (*Policy, error) {
+	if rrset.Type != v2.CAA {
+		return nil, fmt.Errorf("%w: %s rrset", ErrInvalidProperty, rrset.Type)
+	}
+	policy := NewPolicy()
+	for _, item := range rrset.Records {
+		if item.Disabled {
+			continue
+		}
+		property, err := ParseProperty(item.Content)
+		if err != nil {
+			return nil, err
+		}
+		policy.Properties = append(policy.Properties, property)
+	}
+
+	return policy, nil
+}
+
+// Issue allows the CA to issue certificates for the name.
+func (p *Policy) Issue(domain string, params ...Parameter) *Policy {
+	return p.add(TagIssue, Issuer{Domain: domain, Parameters: params}.String())
+}
+
+// IssueWild allows the CA to issue wildcard certificates for the name.
+func (p *Policy) IssueWild(domain string, params ...Parameter) *Policy {
+	return p.add(TagIssueWild, Issuer{Domain: domain, Parameters: params}.String())
+}
+
+// DenyAll forbids all CAs to issue certificates for the name.
+func (p *Policy) DenyAll() *Policy {
+	return p.add(TagIssue, ";")
+}
+
+// DenyWild forbids all CAs to issue wildcard certificates for the name.
+func (p *Policy) DenyWild() *Policy {
+	return p.add(TagIssueWild, ";")
+}
+
+// Iodef adds a mailto: or https: URI CAs report invalid certificate requests to.
+func (p *Policy) Iodef(uri string) *Policy {
+	return p.add(TagIodef, uri)
+}
+
+// Critical marks the last added property as critical.
+func (p *Policy) Critical() *Policy {
+	if len(p.Properties) > 0 {
+		p.Properties[len(p.Properties)-1].Critical = true
+	}
+
+	return p
+}
+
+// Validate checks values of known properties.
+func (p *Policy) Validate() error {
+	for _, property := range p.Properties {
+		switch property.Tag {
+		case TagIssue, TagIssueWild:
+			if _, err := property.Issuer(); err != nil {
+				return err
+			}
+		case TagIodef:
+			parsed, err := url.Parse(property.Value)
+			if err != nil || (parsed.Scheme != "mailto" && parsed.Scheme != "https" && parsed.Scheme != "http") {
+				return fmt.Errorf("%w: iodef %q", ErrInvalidProperty, property.Value)
+			}
+		default:
+			if property.Tag == "" || strings.IndexFunc(property.Tag, notAlphanumeric) >= 0 {
+				return fmt.Errorf("%w: tag %q", ErrInvalidProperty, property.Tag)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Records returns the properties as rrset records.
+func (p *Policy) Records() []v2.RecordItem {
+	records := make([]v2.RecordItem, 0, len(p.Properties))
+	for _, property := range p.Properties {
+		records = append(records, v2.RecordItem{Content: property.Content(), Disabled: false})
+	}
+
+	return records
+}
+
+// RRSet returns the CAA rrset of the name holding the policy.
+func (p *Policy) RRSet(name string, ttl int) *v2.RRSet {
+	//nolint: exhaustruct
+	return &v2.RRSet{Name: name, Type: v2.CAA, TTL: ttl, Records: p.Records()}
+}
+
+func (p *Policy) add(tag, value string) *Policy {
+	p.Properties = append(p.Properties, Property{Critical: false, Tag: tag, Value: value})
+
+	return p
+}
+
+func notAlphanumeric(r rune) bool {
+	return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+}
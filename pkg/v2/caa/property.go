@@ -0,0 +1,165 @@
+package caa
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/rrconv"
+)
+
+// FlagCritical represents the issuer critical flag of a property.
+const FlagCritical = 128
+
+// Property tags defined by RFC 8659.
+const (
+	TagIssue     = "issue"
+	TagIssueWild = "issuewild"
+	TagIodef     = "iodef"
+)
+
+// Parameters defined by RFC 8657.
+const (
+	ParamAccountURI        = "accounturi"
+	ParamValidationMethods = "validationmethods"
+)
+
+var (
+	ErrInvalidProperty = errors.New("invalid CAA property")
+	ErrCNAMELoop       = errors.New("CNAME chain is too long")
+)
+
+type (
+	// Property represents a single CAA record.
+	Property struct {
+		// Critical marks a property the CA must understand to issue.
+		Critical bool
+		Tag      string
+		Value    string
+	}
+
+	// Parameter represents a key=value parameter of an issue or issuewild property.
+	Parameter struct {
+		Key   string
+		Value string
+	}
+
+	// Issuer represents the value of an issue or issuewild property.
+	Issuer struct {
+		// Domain is the issuer domain name of the CA, empty means no CA may issue.
+		Domain     string
+		Parameters []Parameter
+	}
+)
+
+// ParseProperty parses CAA rrset content like `0 issue "letsencrypt.org"`.
+func ParseProperty(content string) (Property, error) {
+	rr, err := rrconv.ToRR(".", 0, v2.CAA, content)
+	if err != nil {
+		return Property{Critical: false, Tag: "", Value: ""}, fmt.Errorf("%w: %w", ErrInvalidProperty, err)
+	}
+	caa, _ := rr.(*dns.CAA)
+
+	return Property{Critical: caa.Flag&FlagCritical != 0, Tag: strings.ToLower(caa.Tag), Value: caa.Value}, nil
+}
+
+// Content returns the property as CAA rrset content.
+func (p Property) Content() string {
+	flag := uint8(0)
+	if p.Critical {
+		flag = FlagCritical
+	}
+	//nolint: exhaustruct
+	rr := &dns.CAA{Flag: flag, Tag: p.Tag, Value: p.Value}
+
+	return rrconv.Content(rr)
+}
+
+// Known reports whether the tag of the property is defined by RFC 8659.
+func (p Property) Known() bool {
+	switch p.Tag {
+	case TagIssue, TagIssueWild, TagIodef:
+		return true
+	}
+
+	return false
+}
+
+// Issuer parses the value of an issue or issuewild property.
+func (p Property) Issuer() (Issuer, error) {
+	if p.Tag != TagIssue && p.Tag != TagIssueWild {
+		return Issuer{Domain: "", Parameters: nil}, fmt.Errorf("%w: %s is not an issue property", ErrInvalidProperty, p.Tag)
+	}
+
+	return ParseIssuer(p.Value)
+}
+
+// ParseIssuer parses an issue value like "ca.example; accounturi=https://ca.example/acct/1".
+func ParseIssuer(value string) (Issuer, error) {
+	parts := strings.Split(value, ";")
+	issuer := Issuer{Domain: strings.ToLower(strings.TrimSpace(parts[0])), Parameters: nil}
+	if issuer.Domain != "" && !validIssuerDomain(issuer.Domain) {
+		return issuer, fmt.Errorf("%w: issuer %q", ErrInvalidProperty, issuer.Domain)
+	}
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, paramValue, ok := strings.Cut(part, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return issuer, fmt.Errorf("%w: parameter %q", ErrInvalidProperty, part)
+		}
+		issuer.Parameters = append(issuer.Parameters, Parameter{
+			Key:   strings.ToLower(strings.TrimSpace(key)),
+			Value: strings.TrimSpace(paramValue),
+		})
+	}
+
+	return issuer, nil
+}
+
+// Parameter returns the value of the parameter.
+func (i Issuer) Parameter(key string) (string, bool) {
+	for _, param := range i.Parameters {
+		if param.Key == key {
+			return param.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// String returns the issuer as the value of an issue property.
+func (i Issuer) String() string {
+	if len(i.Parameters) == 0 {
+		if i.Domain == "" {
+			return ";"
+		}
+
+		return i.Domain
+	}
+	parts := make([]string, 0, len(i.Parameters)+1)
+	parts = append(parts, i.Domain)
+	for _, param := range i.Parameters {
+		parts = append(parts, param.Key+"="+param.Value)
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// validIssuerDomain reports whether the name consists of letter, digit and hyphen labels.
+func validIssuerDomain(name string) bool {
+	if _, ok := dns.IsDomainName(name); !ok || strings.HasSuffix(name, ".") {
+		return false
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || strings.IndexFunc(label, func(r rune) bool { return r != '-' && notAlphanumeric(r) }) >= 0 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,125 @@
+package testing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/selectel/domains-go/pkg/testutils"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/caa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testZoneName = "bonnie-test.com."
+
+func newEvaluator() *caa.Evaluator {
+	evaluator := caa.NewEvaluator()
+	evaluator.AddRRSets([]*v2.RRSet{
+		caa.NewPolicy().
+			Issue("letsencrypt.org", caa.Parameter{Key: caa.ParamValidationMethods, Value: "dns-01,http-01"}).
+			IssueWild("sectigo.com").
+			Iodef("mailto:security@bonnie-test.com").
+			RRSet(testZoneName, 60),
+		caa.NewPolicy().DenyAll().RRSet("locked."+testZoneName, 60),
+		caa.NewPolicy().Issue("pki.goog").Issue("x-ca.test").Critical().RRSet("goog."+testZoneName, 60),
+		testutils.NewRRSet("alias."+testZoneName, v2.CNAME, "locked."+testZoneName),
+		testutils.NewRRSet("strict."+testZoneName, v2.CAA, `128 tbs "unknown"`, `0 issue "letsencrypt.org"`),
+		testutils.NewRRSet("iodef."+testZoneName, v2.CAA, `0 iodef "mailto:security@bonnie-test.com"`),
+		testutils.NewRRSet("loop1."+testZoneName, v2.CNAME, "loop2."+testZoneName),
+		testutils.NewRRSet("loop2."+testZoneName, v2.CNAME, "loop1."+testZoneName),
+	})
+
+	return evaluator
+}
+
+func TestPolicy(t *testing.T) {
+	t.Parallel()
+	policy := caa.NewPolicy().
+		Issue("letsencrypt.org", caa.Parameter{Key: caa.ParamAccountURI, Value: "https://acme.test/acct/1"}).
+		DenyWild().
+		Iodef("mailto:security@bonnie-test.com").Critical()
+
+	require.NoError(t, policy.Validate())
+	records := policy.Records()
+	assert.Equal(t, []v2.RecordItem{
+		{Content: `0 issue "letsencrypt.org; accounturi=https://acme.test/acct/1"`, Disabled: false},
+		{Content: `0 issuewild ";"`, Disabled: false},
+		{Content: `128 iodef "mailto:security@bonnie-test.com"`, Disabled: false},
+	}, records)
+
+	parsed, err := caa.ParsePolicy(policy.RRSet(testZoneName, 60))
+	require.NoError(t, err)
+	assert.Equal(t, policy, parsed)
+	issuer, err := parsed.Properties[0].Issuer()
+	require.NoError(t, err)
+	accountURI, ok := issuer.Parameter(caa.ParamAccountURI)
+	assert.True(t, ok)
+	assert.Equal(t, "https://acme.test/acct/1", accountURI)
+	issuer, err = parsed.Properties[1].Issuer()
+	require.NoError(t, err)
+	assert.Empty(t, issuer.Domain)
+}
+
+func TestPolicyValidate_errors(t *testing.T) {
+	t.Parallel()
+	for _, policy := range []*caa.Policy{
+		caa.NewPolicy().Issue("not a domain"),
+		caa.NewPolicy().Iodef("ftp://bonnie-test.com"),
+		{Properties: []caa.Property{{Critical: false, Tag: "bad-tag", Value: ""}}},
+	} {
+		assert.ErrorIs(t, policy.Validate(), caa.ErrInvalidProperty)
+	}
+}
+
+func TestEvaluatorCheck(t *testing.T) {
+	t.Parallel()
+	evaluator := newEvaluator()
+
+	for _, test := range []struct {
+		request      caa.Request
+		allowed      bool
+		relevantName string
+	}{
+		{caa.Request{Name: "www.bonnie-test.com", Issuer: "letsencrypt.org"}, true, testZoneName},
+		{caa.Request{Name: "www.bonnie-test.com", Issuer: "sectigo.com"}, false, testZoneName},
+		{caa.Request{Name: "*.bonnie-test.com", Issuer: "sectigo.com"}, true, testZoneName},
+		{caa.Request{Name: "*.bonnie-test.com", Issuer: "letsencrypt.org"}, false, testZoneName},
+		{caa.Request{Name: "a.b.bonnie-test.com", Issuer: "LetsEncrypt.org.", ValidationMethod: "dns-01"}, true, testZoneName},
+		{caa.Request{Name: "bonnie-test.com", Issuer: "letsencrypt.org", ValidationMethod: "tls-alpn-01"}, false, testZoneName},
+		{caa.Request{Name: "locked.bonnie-test.com", Issuer: "letsencrypt.org"}, false, "locked." + testZoneName},
+		{caa.Request{Name: "alias.bonnie-test.com", Issuer: "letsencrypt.org"}, false, "alias." + testZoneName},
+		{caa.Request{Name: "www.goog.bonnie-test.com", Issuer: "pki.goog"}, true, "goog." + testZoneName},
+		{caa.Request{Name: "strict.bonnie-test.com", Issuer: "letsencrypt.org"}, false, "strict." + testZoneName},
+		{caa.Request{Name: "iodef.bonnie-test.com", Issuer: "any.test"}, true, "iodef." + testZoneName},
+		{caa.Request{Name: "other.test", Issuer: "any.test"}, true, ""},
+	} {
+		decision, err := evaluator.Check(test.request)
+
+		require.NoError(t, err)
+		assert.Equal(t, test.allowed, decision.Allowed, test.request)
+		assert.Equal(t, test.relevantName, decision.RelevantName, test.request)
+	}
+}
+
+func TestEvaluatorCheck_CNAMELoop(t *testing.T) {
+	t.Parallel()
+	_, err := newEvaluator().Check(caa.Request{Name: "loop1.bonnie-test.com", Issuer: "letsencrypt.org"})
+
+	assert.ErrorIs(t, err, caa.ErrCNAMELoop)
+}
+
+func TestEvaluatorLoadZone(t *testing.T) {
+	t.Parallel()
+	api := testutils.NewFakeAPI()
+	defer api.Close()
+	zone := api.AddZone(testZoneName)
+	api.AddRRSet(zone.ID, *caa.NewPolicy().Issue("letsencrypt.org").RRSet(testZoneName, 60))
+	evaluator := caa.NewEvaluator()
+
+	require.NoError(t, evaluator.LoadZone(context.Background(), api.Client(), zone))
+
+	decision, err := evaluator.Check(caa.Request{Name: "www.bonnie-test.com", Issuer: "pki.goog"})
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+}
@@ -0,0 +1,216 @@
+package changeset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/batch"
+)
+
+var (
+	ErrAlreadyExists      = errors.New("rrset already exists")
+	ErrRollbackIncomplete = errors.New("some changes could not be rolled back")
+)
+
+type (
+	// Change represents a single change of a change set.
+	Change struct {
+		Kind batch.Kind
+		// RRSetID identifies the rrset to update or delete.
+		// After a create is applied it holds the id of the created rrset.
+		RRSetID string
+		// RRSet holds the body of a created or updated rrset.
+		RRSet *v2.RRSet
+	}
+
+	// RollbackFailure describes a change that could not be rolled back.
+	RollbackFailure struct {
+		Change Change
+		Err    error
+	}
+
+	// Report describes the outcome of applying a change set.
+	Report struct {
+		// Applied lists changes that were applied, including rolled back ones.
+		Applied []Change
+		// Failed holds the change that failed, if any.
+		Failed *Change
+		// RolledBack lists applied changes that were compensated after the failure.
+		RolledBack []Change
+		// NotRolledBack lists applied changes that could not be compensated.
+		NotRolledBack []RollbackFailure
+	}
+
+	// ChangeSet represents a group of rrset changes of a zone applied as a unit.
+	ChangeSet struct {
+		manager v2.RRSetManager[v2.RRSet]
+		zoneID  string
+		changes []Change
+	}
+
+	// appliedChange holds an applied change together with the rrset state it replaced.
+	appliedChange struct {
+		change   Change
+		previous *v2.RRSet
+	}
+)
+
+// New returns an empty change set of the zone.
+func New(manager v2.RRSetManager[v2.RRSet], zoneID string) *ChangeSet {
+	return &ChangeSet{manager: manager, zoneID: zoneID, changes: nil}
+}
+
+// Create adds creation of the rrset to the change set.
+func (cs *ChangeSet) Create(rrset *v2.RRSet) *ChangeSet {
+	cs.changes = append(cs.changes, Change{Kind: batch.KindCreate, RRSetID: "", RRSet: rrset})
+
+	return cs
+}
+
+// Update adds update of the rrset with the given id to the change set.
+func (cs *ChangeSet) Update(rrsetID string, rrset *v2.RRSet) *ChangeSet {
+	cs.changes = append(cs.changes, Change{Kind: batch.KindUpdate, RRSetID: rrsetID, RRSet: rrset})
+
+	return cs
+}
+
+// Delete adds deletion of the rrset with the given id to the change set.
+func (cs *ChangeSet) Delete(rrsetID string) *ChangeSet {
+	cs.changes = append(cs.changes, Change{Kind: batch.KindDelete, RRSetID: rrsetID, RRSet: nil})
+
+	return cs
+}
+
+// Changes returns changes of the change set in the order they are applied.
+func (cs *ChangeSet) Changes() []Change {
+	return append([]Change(nil), cs.changes...)
+}
+
+// Apply snapshots affected rrsets and applies changes in order.
+// If a change fails, applied changes are rolled back in reverse order and the error of the change is returned;
+// it also wraps ErrRollbackIncomplete if some of them could not be rolled back.
+// Nothing is changed if the snapshot can't be taken.
+func (cs *ChangeSet) Apply(ctx context.Context) (*Report, error) {
+	//nolint: exhaustruct
+	report := &Report{}
+	snapshot, err := cs.snapshot(ctx)
+	if err != nil {
+		return report, fmt.Errorf("snapshot: %w", err)
+	}
+
+	applied := make([]appliedChange, 0, len(cs.changes))
+	for i, change := range cs.changes {
+		err := cs.apply(ctx, &change)
+		if err == nil {
+			applied = append(applied, appliedChange{change: change, previous: snapshot[i]})
+			report.Applied = append(report.Applied, change)
+
+			continue
+		}
+		report.Failed = &change
+		err = fmt.Errorf("change %d (%s): %w", i, change.Kind, err)
+		if rollbackErr := cs.rollback(ctx, applied, report); rollbackErr != nil {
+			return report, errors.Join(err, rollbackErr)
+		}
+
+		return report, err
+	}
+
+	return report, nil
+}
+
+// snapshot returns the current state of rrsets affected by every change.
+// A created rrset may already exist only if an earlier change of the set deletes it.
+func (cs *ChangeSet) snapshot(ctx context.Context) ([]*v2.RRSet, error) {
+	snapshot := make([]*v2.RRSet, len(cs.changes))
+	deleted := make(map[string]bool)
+	for i, change := range cs.changes {
+		switch change.Kind {
+		case batch.KindCreate:
+			existing, err := v2.FindRRSet(ctx, cs.manager, cs.zoneID, change.RRSet.Name, change.RRSet.Type)
+			switch {
+			case errors.Is(err, v2.ErrNotFound):
+			case err != nil:
+				return nil, fmt.Errorf("find rrset %s %s: %w", change.RRSet.Name, change.RRSet.Type, err)
+			case deleted[existing.ID]:
+				delete(deleted, existing.ID)
+			default:
+				return nil, fmt.Errorf("%w: %s %s", ErrAlreadyExists, change.RRSet.Name, change.RRSet.Type)
+			}
+		case batch.KindUpdate, batch.KindDelete:
+			rrset, err := cs.manager.GetRRSet(ctx, cs.zoneID, change.RRSetID)
+			if err != nil {
+				return nil, fmt.Errorf("get rrset %s: %w", change.RRSetID, err)
+			}
+			snapshot[i] = rrset
+			if change.Kind == batch.KindDelete {
+				deleted[change.RRSetID] = true
+			}
+		default:
+			return nil, fmt.Errorf("%w: unknown kind %q", batch.ErrInvalidOperation, change.Kind)
+		}
+	}
+
+	return snapshot, nil
+}
+
+// apply applies a single change, the id of a created rrset is stored in the change.
+func (cs *ChangeSet) apply(ctx context.Context, change *Change) error {
+	switch change.Kind {
+	case batch.KindCreate:
+		created, err := cs.manager.CreateRRSet(ctx, cs.zoneID, change.RRSet)
+		if err != nil {
+			return err
+		}
+		change.RRSetID = created.ID
+
+		return nil
+	case batch.KindUpdate:
+		return cs.manager.UpdateRRSet(ctx, cs.zoneID, change.RRSetID, change.RRSet)
+	case batch.KindDelete:
+		return cs.manager.DeleteRRSet(ctx, cs.zoneID, change.RRSetID)
+	}
+
+	return fmt.Errorf("%w: unknown kind %q", batch.ErrInvalidOperation, change.Kind)
+}
+
+// rollback compensates applied changes in reverse order.
+// Deleted rrsets are recreated with new ids, which earlier changes of the same rrsets are rolled back with.
+func (cs *ChangeSet) rollback(ctx context.Context, applied []appliedChange, report *Report) error {
+	var errs []error
+	recreated := make(map[string]string)
+	for i := len(applied) - 1; i >= 0; i-- {
+		change, previous := applied[i].change, applied[i].previous
+		rrsetID := change.RRSetID
+		if id, ok := recreated[rrsetID]; ok {
+			rrsetID = id
+		}
+		var err error
+		switch change.Kind {
+		case batch.KindCreate:
+			err = cs.manager.DeleteRRSet(ctx, cs.zoneID, rrsetID)
+		case batch.KindUpdate:
+			err = cs.manager.UpdateRRSet(ctx, cs.zoneID, rrsetID, previous)
+		case batch.KindDelete:
+			var created *v2.RRSet
+			created, err = cs.manager.CreateRRSet(ctx, cs.zoneID, previous)
+			if err == nil {
+				recreated[change.RRSetID] = created.ID
+			}
+		}
+		if err != nil {
+			report.NotRolledBack = append(report.NotRolledBack, RollbackFailure{Change: change, Err: err})
+			errs = append(errs, fmt.Errorf("roll back %s of rrset %s: %w", change.Kind, change.RRSetID, err))
+
+			continue
+		}
+		report.RolledBack = append(report.RolledBack, change)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrRollbackIncomplete, errors.Join(errs...))
+	}
+
+	return nil
+}
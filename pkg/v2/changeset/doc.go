@@ -0,0 +1,26 @@
+/*
+Package changeset applies a group of rrset changes of the Selectel Domains API
+V2 as a unit.
+
+Before anything is changed, a ChangeSet reads the current state of every rrset
+it is going to update or delete and makes sure rrsets it is going to create
+don't exist yet. Changes are then applied one by one. If one of them fails,
+the already applied changes are compensated in reverse order: created rrsets
+are deleted, updated rrsets get their previous contents back and deleted
+rrsets are recreated. Compensations that fail are listed in the report, so
+the zone can be fixed by hand.
+
+Example of applying changes
+
+  changes := changeset.New(client, zoneID)
+  changes.Create(newRRSet)
+  changes.Update(rrsetID, updatedRRSet)
+  changes.Delete(obsoleteRRSetID)
+  report, err := changes.Apply(ctx)
+  if errors.Is(err, changeset.ErrRollbackIncomplete) {
+    for _, failure := range report.NotRolledBack {
+      log.Println(failure.Change.Kind, failure.Change.RRSetID, failure.Err)
+    }
+  }
+*/
+package changeset
@@ -0,0 +1,189 @@
+package testing
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/selectel/domains-go/pkg/testutils"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/batch"
+	"github.com/selectel/domains-go/pkg/v2/changeset"
+	"github.com/stretchr/testify/suite"
+)
+
+const testZoneName = "bonnie-test.com."
+
+type (
+	ChangeSetSuite struct {
+		suite.Suite
+		api      *testutils.FakeAPI
+		zone     *v2.Zone
+		www      *v2.RRSet
+		mail     *v2.RRSet
+		original []v2.RRSet
+	}
+)
+
+//nolint:paralleltest
+func TestChangeSet(t *testing.T) {
+	suite.Run(t, new(ChangeSetSuite))
+}
+
+func (s *ChangeSetSuite) SetupTest() {
+	s.api = testutils.NewFakeAPI()
+	s.zone = s.api.AddZone(testZoneName)
+	s.www = s.api.AddRRSet(s.zone.ID, *testutils.NewRRSet("www."+testZoneName, v2.A, "10.0.0.1"))
+	s.mail = s.api.AddRRSet(s.zone.ID, *testutils.NewRRSet("mail."+testZoneName, v2.A, "10.0.0.2"))
+	s.original = s.api.RRSets(s.zone.ID)
+}
+
+func (s *ChangeSetSuite) TearDownTest() {
+	s.api.Close()
+}
+
+func (s *ChangeSetSuite) newChangeSet() *changeset.ChangeSet {
+	return changeset.New(s.api.Client(), s.zone.ID).
+		Create(testutils.NewRRSet("api."+testZoneName, v2.A, "10.0.0.3")).
+		Update(s.www.ID, testutils.NewRRSet("www."+testZoneName, v2.A, "10.0.0.10")).
+		Delete(s.mail.ID)
+}
+
+func (s *ChangeSetSuite) TestApply() {
+	report, err := s.newChangeSet().Apply(context.Background())
+
+	s.Require().NoError(err)
+	s.Len(report.Applied, 3)
+	s.Nil(report.Failed)
+	s.NotEmpty(report.Applied[0].RRSetID)
+	rrsets := s.api.RRSets(s.zone.ID)
+	s.Require().Len(rrsets, 2)
+	s.Equal("10.0.0.10", rrsets[0].Records[0].Content)
+	s.Equal("api."+testZoneName, rrsets[1].Name)
+}
+
+func (s *ChangeSetSuite) TestApply_rollback() {
+	s.failRequests(http.MethodDelete, s.mail.ID)
+
+	report, err := s.newChangeSet().Apply(context.Background())
+
+	var badResponse *v2.BadResponseError
+	s.Require().ErrorAs(err, &badResponse)
+	s.NotErrorIs(err, changeset.ErrRollbackIncomplete)
+	s.Require().NotNil(report.Failed)
+	s.Equal(batch.KindDelete, report.Failed.Kind)
+	s.Len(report.RolledBack, 2)
+	s.Empty(report.NotRolledBack)
+	s.Equal(s.original, s.api.RRSets(s.zone.ID))
+}
+
+func (s *ChangeSetSuite) TestApply_rollback_incomplete() {
+	s.failRequests(http.MethodDelete, "")
+
+	report, err := s.newChangeSet().Apply(context.Background())
+
+	s.Require().ErrorIs(err, changeset.ErrRollbackIncomplete)
+	s.Require().Len(report.NotRolledBack, 1)
+	s.Equal(batch.KindCreate, report.NotRolledBack[0].Change.Kind)
+	s.Require().Len(report.RolledBack, 1)
+	s.Equal(batch.KindUpdate, report.RolledBack[0].Kind)
+}
+
+func (s *ChangeSetSuite) TestApply_deleted_rrset_is_recreated() {
+	s.failRequests(http.MethodPatch, s.www.ID)
+
+	report, err := changeset.New(s.api.Client(), s.zone.ID).
+		Delete(s.mail.ID).
+		Update(s.www.ID, testutils.NewRRSet("www."+testZoneName, v2.A, "10.0.0.10")).
+		Apply(context.Background())
+
+	s.Require().Error(err)
+	s.Len(report.RolledBack, 1)
+	rrsets := s.api.RRSets(s.zone.ID)
+	s.Require().Len(rrsets, 2)
+	s.Equal("mail."+testZoneName, rrsets[1].Name)
+	s.Equal(s.mail.Records, rrsets[1].Records)
+}
+
+func (s *ChangeSetSuite) TestApply_rollback_update_and_delete_of_rrset() {
+	s.failRequests(http.MethodPatch, s.www.ID)
+
+	report, err := changeset.New(s.api.Client(), s.zone.ID).
+		Update(s.mail.ID, testutils.NewRRSet("mail."+testZoneName, v2.A, "10.0.0.20")).
+		Delete(s.mail.ID).
+		Update(s.www.ID, testutils.NewRRSet("www."+testZoneName, v2.A, "10.0.0.10")).
+		Apply(context.Background())
+
+	s.Require().Error(err)
+	s.NotErrorIs(err, changeset.ErrRollbackIncomplete)
+	s.Len(report.RolledBack, 2)
+	s.Empty(report.NotRolledBack)
+	rrsets := s.api.RRSets(s.zone.ID)
+	s.Require().Len(rrsets, 2)
+	s.Equal("mail."+testZoneName, rrsets[1].Name)
+	s.Equal(s.mail.Records, rrsets[1].Records)
+}
+
+func (s *ChangeSetSuite) TestApply_delete_then_create() {
+	report, err := changeset.New(s.api.Client(), s.zone.ID).
+		Delete(s.www.ID).
+		Create(testutils.NewRRSet("www."+testZoneName, v2.A, "10.0.0.10")).
+		Apply(context.Background())
+
+	s.Require().NoError(err)
+	s.Len(report.Applied, 2)
+	rrsets := s.api.RRSets(s.zone.ID)
+	s.Require().Len(rrsets, 2)
+	s.Equal("www."+testZoneName, rrsets[1].Name)
+	s.Equal("10.0.0.10", rrsets[1].Records[0].Content)
+}
+
+func (s *ChangeSetSuite) TestApply_recreation_failure_is_reported() {
+	s.failRequests(http.MethodPost, "")
+
+	report, err := changeset.New(s.api.Client(), s.zone.ID).
+		Delete(s.mail.ID).
+		Create(testutils.NewRRSet("api."+testZoneName, v2.A, "10.0.0.3")).
+		Apply(context.Background())
+
+	s.Require().ErrorIs(err, changeset.ErrRollbackIncomplete)
+	s.Require().Len(report.NotRolledBack, 1)
+	s.Equal(batch.KindDelete, report.NotRolledBack[0].Change.Kind)
+	s.Equal(s.mail.ID, report.NotRolledBack[0].Change.RRSetID)
+}
+
+func (s *ChangeSetSuite) TestApply_snapshot_missing_rrset() {
+	report, err := changeset.New(s.api.Client(), s.zone.ID).
+		Delete(s.www.ID).
+		Update("missing", testutils.NewRRSet("mail."+testZoneName, v2.A, "10.0.0.3")).
+		Apply(context.Background())
+
+	s.Require().ErrorIs(err, v2.ErrNotFound)
+	s.Empty(report.Applied)
+	s.Equal(s.original, s.api.RRSets(s.zone.ID))
+}
+
+func (s *ChangeSetSuite) TestApply_snapshot_failure_changes_nothing() {
+	_, err := changeset.New(s.api.Client(), s.zone.ID).
+		Delete(s.mail.ID).
+		Create(testutils.NewRRSet("www."+testZoneName, v2.A, "10.0.0.3")).
+		Apply(context.Background())
+
+	s.Require().ErrorIs(err, changeset.ErrAlreadyExists)
+	s.Equal(s.original, s.api.RRSets(s.zone.ID))
+	for _, request := range s.api.Requests() {
+		s.True(strings.HasPrefix(request, http.MethodGet), request)
+	}
+}
+
+// failRequests makes the fake API fail requests with the method whose path ends with suffix.
+func (s *ChangeSetSuite) failRequests(method, suffix string) {
+	s.api.InjectFault(func(r *http.Request) int {
+		if r.Method == method && strings.HasSuffix(r.URL.Path, suffix) {
+			return http.StatusInternalServerError
+		}
+
+		return 0
+	})
+}
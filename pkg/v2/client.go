@@ -15,10 +15,10 @@ type (
 		httpClient     *http.Client
 		defaultHeaders http.Header
 		BaseURL        string
+		zoneNames      *zoneNames
 	}
-	ReturnTypes interface {
-		Zone | List[Zone] | RRSet | List[RRSet]
-	}
+	// ReturnTypes is kept for compatibility, responses can be decoded into any type.
+	ReturnTypes = any
 )
 
 //nolint:exhaustruct
@@ -29,6 +29,7 @@ func NewClient(apiURL string, httpClient *http.Client, defaultHeaders http.Heade
 		httpClient:     httpClient,
 		defaultHeaders: defaultHeaders,
 		BaseURL:        apiURL,
+		zoneNames:      nil,
 	}
 }
 
@@ -93,7 +94,13 @@ func addedParamsToHTTPQuery(request *http.Request, params *map[string]string) {
 	request.URL.RawQuery = urlQuery.Encode()
 }
 
-func processRequest[RT ReturnTypes](client *http.Client, request *http.Request, err error) (*RT, error) {
+func processRequest[RT any](client *http.Client, request *http.Request, err error) (*RT, error) {
+	return processRequestWithDecoder(client, request, err, DecodeJSON[RT])
+}
+
+func processRequestWithDecoder[RT any](
+	client *http.Client, request *http.Request, err error, decode Decoder[RT],
+) (*RT, error) {
 	if err != nil {
 		return nil, ErrInvalidRequestObj
 	}
@@ -106,12 +113,12 @@ func processRequest[RT ReturnTypes](client *http.Client, request *http.Request,
 	if err != nil {
 		return nil, fmt.Errorf("processing response: %w", err)
 	}
-	resp, err := checkProccessResult[RT](response.StatusCode, body)
+	resp, err := checkProccessResult(response.StatusCode, body, decode)
 
 	return resp, err
 }
 
-func checkProccessResult[RT ReturnTypes](statusCode int, body []byte) (*RT, error) {
+func checkProccessResult[RT any](statusCode int, body []byte, decode Decoder[RT]) (*RT, error) {
 	switch {
 	case statusCode == http.StatusNoContent && len(body) == 0:
 		//nolint: nilnil
@@ -119,12 +126,12 @@ func checkProccessResult[RT ReturnTypes](statusCode int, body []byte) (*RT, erro
 	case statusCode == http.StatusNotFound:
 		return nil, ErrNotFound
 	case statusCode < http.StatusBadRequest:
-		var result RT
-		if err := json.Unmarshal(body, &result); err != nil {
+		result, err := decode(body)
+		if err != nil {
 			return nil, fmt.Errorf("processing good response: %w", err)
 		}
 
-		return &result, nil
+		return result, nil
 	default:
 		var result BadResponseError
 		if err := json.Unmarshal(body, &result); err != nil {
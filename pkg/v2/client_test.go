@@ -17,7 +17,7 @@ const (
 
 var (
 	testHTTPClient = http.DefaultClient
-	testClient     = &Client{testHTTPClient, make(http.Header), testAPIURL}
+	testClient     = &Client{testHTTPClient, make(http.Header), testAPIURL, nil}
 )
 
 func TestProcessRequest_FailedRequest(t *testing.T) {
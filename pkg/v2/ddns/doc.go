@@ -0,0 +1,25 @@
+/*
+Package ddns keeps A and AAAA rrsets of hosts with changing public addresses
+in sync with the Selectel Domains API V2.
+
+The Updater detects the current address with a Source, compares it with the
+rrset found by v2.FindRRSet and updates the rrset only when the address has
+changed for longer than the debounce period. Failed checks are retried with
+exponential backoff and the Updater serves its status as JSON over HTTP.
+
+Sources are provided for echo endpoints over HTTP, addresses of local network
+interfaces and output of commands, SourceFunc adapts any other detection.
+
+Example of updating a home-lab host
+
+  source := &ddns.HTTPSource{IPv4URL: "https://api.ipify.org", IPv6URL: "https://api6.ipify.org"}
+  updater := ddns.NewUpdater(client, source, zone.ID, zone.FQDN("lab"))
+  updater.Types = []v2.RecordType{v2.A, v2.AAAA}
+  updater.Debounce = 10 * time.Minute
+  updater.ErrorLog = log.Default()
+  go func() {
+    log.Println(http.ListenAndServe("127.0.0.1:8053", updater))
+  }()
+  updater.Run(ctx)
+*/
+package ddns
@@ -0,0 +1,178 @@
+package ddns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"os/exec"
+	"strings"
+
+	v2 "github.com/selectel/domains-go/pkg/v2"
+)
+
+// maxEchoResponse represents the maximum size of a response of an echo endpoint.
+const maxEchoResponse = 1024
+
+var (
+	ErrUnsupportedType = errors.New("record type is not A or AAAA")
+	ErrNoAddress       = errors.New("no address found")
+)
+
+type (
+	// Source detects the current address for A or AAAA records.
+	Source interface {
+		Address(ctx context.Context, recordType v2.RecordType) (netip.Addr, error)
+	}
+
+	// SourceFunc is a function that implements Source.
+	SourceFunc func(ctx context.Context, recordType v2.RecordType) (netip.Addr, error)
+
+	// HTTPSource asks echo endpoints that respond with the address of the caller as plain text,
+	// e.g. https://api.ipify.org and https://api6.ipify.org.
+	HTTPSource struct {
+		// IPv4URL and IPv6URL are endpoints for A and AAAA records, empty ones fail with ErrNoAddress.
+		IPv4URL string
+		IPv6URL string
+
+		// Client sends requests, http.DefaultClient is used if nil.
+		Client *http.Client
+	}
+
+	// InterfaceSource takes the first public address assigned to a local network interface,
+	// e.g. the uplink of an office router. Private, loopback and link-local addresses are skipped.
+	InterfaceSource struct {
+		Name string
+	}
+
+	// CommandSource runs a command and takes the first address of the record family in its output.
+	// The record type is passed to the command in the DDNS_RECORD_TYPE environment variable.
+	CommandSource struct {
+		Name string
+		Args []string
+	}
+)
+
+// Address calls f.
+func (f SourceFunc) Address(ctx context.Context, recordType v2.RecordType) (netip.Addr, error) {
+	return f(ctx, recordType)
+}
+
+// Address returns the address the echo endpoint of the record type responds with.
+func (s *HTTPSource) Address(ctx context.Context, recordType v2.RecordType) (netip.Addr, error) {
+	if err := checkType(recordType); err != nil {
+		return netip.Addr{}, err
+	}
+	url := s.IPv4URL
+	if recordType == v2.AAAA {
+		url = s.IPv6URL
+	}
+	if url == "" {
+		return netip.Addr{}, fmt.Errorf("%w: no endpoint for %s", ErrNoAddress, recordType)
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("prepare request: %w", err)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("query %s: %w", url, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return netip.Addr{}, fmt.Errorf("query %s: unexpected status %s", url, response.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxEchoResponse))
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("read %s: %w", url, err)
+	}
+
+	return findAddress(string(body), recordType)
+}
+
+// Address returns the first public address of the interface matching the record type.
+func (s *InterfaceSource) Address(_ context.Context, recordType v2.RecordType) (netip.Addr, error) {
+	if err := checkType(recordType); err != nil {
+		return netip.Addr{}, err
+	}
+	iface, err := net.InterfaceByName(s.Name)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("interface %s: %w", s.Name, err)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("addresses of %s: %w", s.Name, err)
+	}
+	for _, addr := range addrs {
+		prefix, err := netip.ParsePrefix(addr.String())
+		if err != nil {
+			continue
+		}
+		if ip := prefix.Addr(); matchesType(ip, recordType) && isPublic(ip) {
+			return ip, nil
+		}
+	}
+
+	return netip.Addr{}, fmt.Errorf("%w: %s has no public %s address", ErrNoAddress, s.Name, recordType)
+}
+
+// Address runs the command and returns the first address of the record family in its output.
+func (s *CommandSource) Address(ctx context.Context, recordType v2.RecordType) (netip.Addr, error) {
+	if err := checkType(recordType); err != nil {
+		return netip.Addr{}, err
+	}
+	command := exec.CommandContext(ctx, s.Name, s.Args...)
+	command.Env = append(os.Environ(), "DDNS_RECORD_TYPE="+string(recordType))
+	output, err := command.Output()
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("run %s: %w", s.Name, err)
+	}
+
+	return findAddress(string(output), recordType)
+}
+
+// findAddress returns the first address of the record family among whitespace separated fields of the text.
+// Fields in CIDR notation, e.g. 203.0.113.5/32 printed by ip, are taken as their address.
+func findAddress(text string, recordType v2.RecordType) (netip.Addr, error) {
+	for _, field := range strings.Fields(text) {
+		addr, err := netip.ParseAddr(field)
+		if err != nil {
+			var prefix netip.Prefix
+			prefix, err = netip.ParsePrefix(field)
+			addr = prefix.Addr()
+		}
+		if err == nil && matchesType(addr, recordType) {
+			return addr.Unmap(), nil
+		}
+	}
+
+	return netip.Addr{}, fmt.Errorf("%w: no %s address in %q", ErrNoAddress, recordType, strings.TrimSpace(text))
+}
+
+func checkType(recordType v2.RecordType) error {
+	if recordType != v2.A && recordType != v2.AAAA {
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, recordType)
+	}
+
+	return nil
+}
+
+func matchesType(addr netip.Addr, recordType v2.RecordType) bool {
+	if recordType == v2.A {
+		return addr.Unmap().Is4()
+	}
+
+	return addr.Is6() && !addr.Is4In6()
+}
+
+func isPublic(addr netip.Addr) bool {
+	return addr.IsGlobalUnicast() && !addr.IsPrivate()
+}
@@ -0,0 +1,255 @@
+package testing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/selectel/domains-go/pkg/testutils"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/ddns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testZoneName = "bonnie-test.com."
+	testHostname = "lab." + testZoneName
+)
+
+// stubSource returns addresses set by tests.
+type stubSource struct {
+	mu        sync.Mutex
+	addresses map[v2.RecordType]string
+	err       error
+}
+
+func (s *stubSource) Address(_ context.Context, recordType v2.RecordType) (netip.Addr, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return netip.Addr{}, s.err
+	}
+
+	return netip.ParseAddr(s.addresses[recordType])
+}
+
+func (s *stubSource) set(recordType v2.RecordType, address string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addresses[recordType] = address
+}
+
+func newUpdater(t *testing.T) (*ddns.Updater, *stubSource, *testutils.FakeAPI, string) {
+	t.Helper()
+	api := testutils.NewFakeAPI()
+	t.Cleanup(api.Close)
+	zone := api.AddZone(testZoneName)
+	//nolint: exhaustruct
+	source := &stubSource{addresses: map[v2.RecordType]string{v2.A: "203.0.113.1", v2.AAAA: "2001:db8::1"}}
+	updater := ddns.NewUpdater(api.Client(), source, zone.ID, testHostname)
+	updater.Types = []v2.RecordType{v2.A, v2.AAAA}
+
+	return updater, source, api, zone.ID
+}
+
+func contents(t *testing.T, api *testutils.FakeAPI, zoneID string, recordType v2.RecordType) []string {
+	t.Helper()
+	var result []string
+	for _, rrset := range api.RRSets(zoneID) {
+		if rrset.Name == testHostname && rrset.Type == recordType {
+			for _, item := range rrset.Records {
+				result = append(result, item.Content)
+			}
+		}
+	}
+
+	return result
+}
+
+func writes(api *testutils.FakeAPI) int {
+	count := 0
+	for _, request := range api.Requests() {
+		if strings.HasPrefix(request, http.MethodPost) || strings.HasPrefix(request, http.MethodPatch) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestUpdater_Check(t *testing.T) {
+	t.Parallel()
+	updater, source, api, zoneID := newUpdater(t)
+	ctx := context.Background()
+
+	require.NoError(t, updater.Check(ctx))
+	assert.Equal(t, []string{"203.0.113.1"}, contents(t, api, zoneID, v2.A))
+	assert.Equal(t, []string{"2001:db8::1"}, contents(t, api, zoneID, v2.AAAA))
+	assert.Equal(t, 2, writes(api))
+	assert.Equal(t, []string{
+		"GET /zones/" + zoneID + "/rrset",
+		"POST /zones/" + zoneID + "/rrset",
+		"GET /zones/" + zoneID + "/rrset",
+		"POST /zones/" + zoneID + "/rrset",
+	}, api.Requests(), "each record must be looked up once")
+
+	require.NoError(t, updater.Check(ctx))
+	assert.Equal(t, 2, writes(api), "unchanged addresses must not be written")
+
+	source.set(v2.A, "203.0.113.2")
+	require.NoError(t, updater.Check(ctx))
+	assert.Equal(t, []string{"203.0.113.2"}, contents(t, api, zoneID, v2.A))
+	assert.Equal(t, 3, writes(api))
+
+	status := updater.Status()
+	assert.Equal(t, testHostname, status.Hostname)
+	assert.Equal(t, "203.0.113.2", status.Records[v2.A].Published)
+	assert.False(t, status.Records[v2.A].UpdatedAt.IsZero())
+}
+
+func TestUpdater_Check_debounce(t *testing.T) {
+	t.Parallel()
+	updater, source, api, zoneID := newUpdater(t)
+	updater.Types = []v2.RecordType{v2.A}
+	updater.Debounce = time.Minute
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	updater.Now = func() time.Time { return now }
+	ctx := context.Background()
+	//nolint: exhaustruct
+	api.AddRRSet(zoneID, v2.RRSet{
+		Name: testHostname, Type: v2.A, TTL: 300, Records: []v2.RecordItem{{Content: "203.0.113.1", Disabled: false}},
+	})
+
+	source.set(v2.A, "203.0.113.2")
+	require.NoError(t, updater.Check(ctx))
+	assert.Equal(t, "203.0.113.2", updater.Status().Records[v2.A].Pending)
+
+	// The address flaps back before the debounce period is over.
+	now = now.Add(30 * time.Second)
+	source.set(v2.A, "203.0.113.1")
+	require.NoError(t, updater.Check(ctx))
+	assert.Empty(t, updater.Status().Records[v2.A].Pending)
+
+	source.set(v2.A, "203.0.113.3")
+	require.NoError(t, updater.Check(ctx))
+	now = now.Add(59 * time.Second)
+	require.NoError(t, updater.Check(ctx))
+	assert.Equal(t, 0, writes(api))
+
+	now = now.Add(time.Second)
+	require.NoError(t, updater.Check(ctx))
+	assert.Equal(t, []string{"203.0.113.3"}, contents(t, api, zoneID, v2.A))
+	assert.Equal(t, 300, api.RRSets(zoneID)[0].TTL, "the TTL of the existing rrset must be kept")
+}
+
+func TestUpdater_errors(t *testing.T) {
+	t.Parallel()
+	updater, source, api, _ := newUpdater(t)
+	ctx := context.Background()
+	source.err = errors.New("no route")
+
+	err := updater.Check(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no route")
+	assert.Equal(t, 0, writes(api))
+
+	server := httptest.NewServer(updater)
+	defer server.Close()
+	response, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	var status ddns.Status
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&status))
+	assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+	assert.Equal(t, 1, status.Failures)
+	assert.Contains(t, status.Records[v2.AAAA].Error, "no route")
+
+	source.err = nil
+	require.NoError(t, updater.Check(ctx))
+	assert.Equal(t, 0, updater.Status().Failures)
+}
+
+func TestUpdater_Run(t *testing.T) {
+	t.Parallel()
+	updater, _, api, zoneID := newUpdater(t)
+	updater.Interval = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		updater.Run(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return len(contents(t, api, zoneID, v2.AAAA)) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+}
+
+func TestHTTPSource(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ip4":
+			fmt.Fprintln(w, "198.51.100.7")
+		case "/ip6":
+			fmt.Fprintln(w, "2001:db8::7")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	//nolint: exhaustruct
+	source := &ddns.HTTPSource{IPv4URL: server.URL + "/ip4", IPv6URL: server.URL + "/ip6"}
+	ctx := context.Background()
+
+	addr, err := source.Address(ctx, v2.A)
+	require.NoError(t, err)
+	assert.Equal(t, "198.51.100.7", addr.String())
+	addr, err = source.Address(ctx, v2.AAAA)
+	require.NoError(t, err)
+	assert.Equal(t, "2001:db8::7", addr.String())
+
+	source.IPv6URL = server.URL + "/ip4"
+	_, err = source.Address(ctx, v2.AAAA)
+	assert.ErrorIs(t, err, ddns.ErrNoAddress)
+	source.IPv4URL = server.URL + "/missing"
+	_, err = source.Address(ctx, v2.A)
+	assert.Error(t, err)
+	_, err = source.Address(ctx, v2.MX)
+	assert.ErrorIs(t, err, ddns.ErrUnsupportedType)
+}
+
+func TestCommandSource(t *testing.T) {
+	t.Parallel()
+	source := &ddns.CommandSource{Name: "sh", Args: []string{"-c", `echo "inet 10.0.0.1 $DDNS_RECORD_TYPE 2001:db8::9"`}}
+	ctx := context.Background()
+
+	addr, err := source.Address(ctx, v2.A)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", addr.String())
+	addr, err = source.Address(ctx, v2.AAAA)
+	require.NoError(t, err)
+	assert.Equal(t, "2001:db8::9", addr.String())
+
+	source.Args = []string{"-c", `echo "ppp0 UP 203.0.113.5/32 2001:db8::5/64"`}
+	addr, err = source.Address(ctx, v2.A)
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.5", addr.String())
+	addr, err = source.Address(ctx, v2.AAAA)
+	require.NoError(t, err)
+	assert.Equal(t, "2001:db8::5", addr.String())
+
+	_, err = (&ddns.CommandSource{Name: "sh", Args: []string{"-c", "exit 1"}}).Address(ctx, v2.A)
+	assert.Error(t, err)
+}
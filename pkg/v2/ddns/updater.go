@@ -0,0 +1,315 @@
+package ddns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/netip"
+	"sync"
+	"time"
+
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/internal/backoff"
+)
+
+const (
+	// defaultTTL represents the TTL of created rrsets.
+	defaultTTL = 60
+
+	// defaultInterval represents the default delay between checks.
+	defaultInterval = 5 * time.Minute
+
+	// defaultMaxBackoff represents the default upper limit of the delay between failed checks.
+	defaultMaxBackoff = 30 * time.Minute
+)
+
+type (
+	// Updater keeps A and AAAA rrsets of a host in sync with addresses detected by a Source.
+	Updater struct {
+		// Types lists the record types to keep in sync, A by default.
+		Types []v2.RecordType
+
+		// TTL of created and updated rrsets. If zero, TTLs of existing rrsets are kept
+		// and new rrsets get a TTL of 60 seconds.
+		TTL int
+
+		// Interval between checks.
+		Interval time.Duration
+
+		// Debounce is how long a changed address has to be detected before the rrset is updated,
+		// so that short flaps of a link don't cause updates.
+		Debounce time.Duration
+
+		// MaxBackoff limits the delay between checks after consecutive errors, zero means no limit.
+		MaxBackoff time.Duration
+
+		// ErrorLog logs updates and errors. If nil, nothing is logged.
+		ErrorLog *log.Logger
+
+		// Now returns the current time, time.Now is used if nil.
+		Now func() time.Time
+
+		manager  v2.RRSetManager[v2.RRSet]
+		source   Source
+		zoneID   string
+		hostname string
+
+		mu     sync.Mutex
+		status Status
+	}
+
+	// Status represents the state of the Updater served by its status endpoint.
+	Status struct {
+		Hostname  string                          `json:"hostname"`
+		LastCheck time.Time                       `json:"last_check"`
+		Failures  int                             `json:"failures"`
+		Records   map[v2.RecordType]*RecordStatus `json:"records"`
+	}
+
+	// RecordStatus represents the state of a single record type of the host.
+	RecordStatus struct {
+		// Detected is the address returned by the source on the last check.
+		Detected string `json:"detected,omitempty"`
+
+		// Published is the address of the rrset on the last check.
+		Published string `json:"published,omitempty"`
+
+		// Pending is a changed address that waits for the debounce period since PendingSince.
+		Pending      string    `json:"pending,omitempty"`
+		PendingSince time.Time `json:"pending_since,omitempty"`
+
+		// UpdatedAt is when the rrset was last updated by the Updater.
+		UpdatedAt time.Time `json:"updated_at,omitempty"`
+
+		// Error of the last check.
+		Error string `json:"error,omitempty"`
+	}
+)
+
+// NewUpdater returns an updater of A records of the host with default interval and backoff.
+// The hostname has to be the absolute name of an rrset of the zone.
+func NewUpdater(manager v2.RRSetManager[v2.RRSet], source Source, zoneID, hostname string) *Updater {
+	return &Updater{
+		Types:      []v2.RecordType{v2.A},
+		TTL:        0,
+		Interval:   defaultInterval,
+		Debounce:   0,
+		MaxBackoff: defaultMaxBackoff,
+		ErrorLog:   nil,
+		Now:        nil,
+		manager:    manager,
+		source:     source,
+		zoneID:     zoneID,
+		hostname:   hostname,
+		mu:         sync.Mutex{},
+		status:     Status{Hostname: hostname, LastCheck: time.Time{}, Failures: 0, Records: nil},
+	}
+}
+
+// Run checks the addresses every interval until the context is done.
+// Failed checks are retried with exponential backoff.
+func (u *Updater) Run(ctx context.Context) {
+	for {
+		failures := 0
+		if err := u.Check(ctx); err != nil {
+			u.logf("check %s: %v", u.hostname, err)
+			u.mu.Lock()
+			failures = u.status.Failures
+			u.mu.Unlock()
+		}
+
+		timer := time.NewTimer(u.delay(failures))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Check detects the addresses once and updates rrsets whose addresses changed
+// for longer than the debounce period. Errors of all record types are joined.
+func (u *Updater) Check(ctx context.Context) error {
+	now := u.now()
+	errs := make([]error, 0, len(u.Types))
+	for _, recordType := range u.Types {
+		if err := u.check(ctx, recordType, now); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", recordType, err))
+		}
+	}
+	err := errors.Join(errs...)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.status.LastCheck = now
+	if err != nil {
+		u.status.Failures++
+	} else {
+		u.status.Failures = 0
+	}
+
+	return err
+}
+
+// Status returns a copy of the current status.
+func (u *Updater) Status() Status {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	status := u.status
+	status.Records = make(map[v2.RecordType]*RecordStatus, len(u.status.Records))
+	for recordType, record := range u.status.Records {
+		copied := *record
+		status.Records[recordType] = &copied
+	}
+
+	return status
+}
+
+// ServeHTTP serves the status as JSON, with 503 status code while checks fail.
+func (u *Updater) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	status := u.Status()
+	w.Header().Set("Content-Type", "application/json")
+	if status.Failures > 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+func (u *Updater) check(ctx context.Context, recordType v2.RecordType, now time.Time) error {
+	record := u.recordStatus(recordType)
+	detected, err := u.source.Address(ctx, recordType)
+	if err != nil {
+		return u.fail(record, fmt.Errorf("detect address: %w", err))
+	}
+	existing, err := v2.FindRRSet(ctx, u.manager, u.zoneID, u.hostname, recordType)
+	if err != nil && !errors.Is(err, v2.ErrNotFound) {
+		return u.fail(record, fmt.Errorf("find rrset: %w", err))
+	}
+
+	u.mu.Lock()
+	record.Detected, record.Published, record.Error = detected.String(), published(existing), ""
+	if record.Published == record.Detected {
+		record.Pending, record.PendingSince = "", time.Time{}
+		u.mu.Unlock()
+
+		return nil
+	}
+	if record.Pending != record.Detected {
+		record.Pending, record.PendingSince = record.Detected, now
+	}
+	due := now.Sub(record.PendingSince) >= u.Debounce
+	u.mu.Unlock()
+	if !due {
+		return nil
+	}
+
+	if _, err := v2.UpsertFoundRRSet(ctx, u.manager, u.zoneID, existing, u.rrset(recordType, detected, existing)); err != nil {
+		return u.fail(record, err)
+	}
+	u.logf("updated %s %s from %q to %s", u.hostname, recordType, record.Published, detected)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	record.Published, record.Pending, record.PendingSince, record.UpdatedAt = detected.String(), "", time.Time{}, now
+
+	return nil
+}
+
+// rrset returns the rrset with the single detected address.
+func (u *Updater) rrset(recordType v2.RecordType, detected netip.Addr, existing *v2.RRSet) *v2.RRSet {
+	ttl := u.TTL
+	if ttl == 0 && existing != nil {
+		ttl = existing.TTL
+	}
+	if ttl == 0 {
+		ttl = defaultTTL
+	}
+	//nolint: exhaustruct
+	rrset := &v2.RRSet{
+		Name:    u.hostname,
+		Type:    recordType,
+		TTL:     ttl,
+		Records: []v2.RecordItem{{Content: detected.String(), Disabled: false}},
+	}
+	if existing != nil {
+		rrset.Comment, rrset.ManagedBy = existing.Comment, existing.ManagedBy
+	}
+
+	return rrset
+}
+
+func (u *Updater) recordStatus(recordType v2.RecordType) *RecordStatus {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.status.Records == nil {
+		u.status.Records = make(map[v2.RecordType]*RecordStatus)
+	}
+	record, ok := u.status.Records[recordType]
+	if !ok {
+		//nolint: exhaustruct
+		record = &RecordStatus{}
+		u.status.Records[recordType] = record
+	}
+
+	return record
+}
+
+func (u *Updater) fail(record *RecordStatus, err error) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	record.Error = err.Error()
+
+	return err
+}
+
+// delay returns the time to wait before the next check.
+func (u *Updater) delay(failures int) time.Duration {
+	return backoff.Delay(u.Interval, u.MaxBackoff, failures)
+}
+
+func (u *Updater) now() time.Time {
+	if u.Now != nil {
+		return u.Now()
+	}
+
+	return time.Now()
+}
+
+func (u *Updater) logf(format string, args ...any) {
+	if u.ErrorLog != nil {
+		u.ErrorLog.Printf(format, args...)
+	}
+}
+
+// published returns the address of an rrset with a single enabled record or all its enabled contents otherwise.
+func published(rrset *v2.RRSet) string {
+	if rrset == nil {
+		return ""
+	}
+	var contents []string
+	for _, item := range rrset.Records {
+		if item.Disabled {
+			continue
+		}
+		if addr, err := netip.ParseAddr(item.Content); err == nil {
+			contents = append(contents, addr.String())
+		} else {
+			contents = append(contents, item.Content)
+		}
+	}
+	switch len(contents) {
+	case 0:
+		return ""
+	case 1:
+		return contents[0]
+	default:
+		return fmt.Sprint(contents)
+	}
+}
@@ -0,0 +1,423 @@
+package delegation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/rrconv"
+)
+
+const (
+	// defaultPort represents the default port of nameservers.
+	defaultPort = 53
+
+	// defaultTimeout represents the default timeout of a single DNS query.
+	defaultTimeout = 5 * time.Second
+)
+
+var (
+	ErrNoParent        = errors.New("parent zone not found")
+	ErrNoParentServers = errors.New("no nameserver of the parent zone answered")
+	ErrNotDelegated    = errors.New("zone is not delegated by the parent zone")
+	ErrBadRcode        = errors.New("unexpected response code")
+	ErrNoAddresses     = errors.New("nameserver has no addresses")
+	ErrNoSOA           = errors.New("no SOA record in the answer")
+)
+
+// DefaultNameservers lists nameservers of the Selectel DNS hosting.
+var DefaultNameservers = []string{
+	"a.ns.selectel.ru.",
+	"b.ns.selectel.ru.",
+	"c.ns.selectel.ru.",
+	"d.ns.selectel.ru.",
+}
+
+type (
+	// Checker checks delegation of zones.
+	Checker struct {
+		// Resolver represents the address (host:port) of a recursive resolver
+		// used to find the parent zone and addresses of nameservers.
+		Resolver string
+
+		// Port represents the port authoritative nameservers are queried on.
+		// If zero, 53 is used.
+		Port int
+
+		// Timeout limits a single DNS query. If zero, defaultTimeout is used.
+		Timeout time.Duration
+
+		// Expected lists nameservers the zone must be delegated to.
+		// If empty, DefaultNameservers are expected.
+		Expected []string
+	}
+
+	// Report describes delegation of a zone.
+	Report struct {
+		Zone   string
+		Parent string
+		// ParentServer is the parent nameserver that answered the delegation query.
+		ParentServer string
+		Expected     []string
+		// Delegated lists nameservers from the NS records served by the parent zone.
+		Delegated []string
+		// Missing lists expected nameservers absent from the delegation.
+		Missing []string
+		// Unexpected lists delegated nameservers that aren't expected.
+		Unexpected []string
+		// Glue maps delegated nameservers to glue addresses served by the parent zone.
+		Glue map[string][]string
+		// MissingGlue lists nameservers inside the zone delegated without glue.
+		MissingGlue []string
+		// Servers holds the SOA check result of every address of every delegated nameserver.
+		Servers []ServerStatus
+		// SerialConsistent reports whether all answering nameservers serve the same SOA serial.
+		SerialConsistent bool
+		CheckedAt        time.Time
+	}
+
+	// ServerStatus describes the SOA answer of a single nameserver address.
+	ServerStatus struct {
+		Name          string
+		Address       string
+		Serial        uint32
+		Authoritative bool
+		Err           error
+	}
+)
+
+// NewChecker returns a checker that uses resolver to look up the parent zone and nameserver addresses.
+func NewChecker(resolver string) *Checker {
+	return &Checker{
+		Resolver: resolver,
+		Port:     defaultPort,
+		Timeout:  defaultTimeout,
+		Expected: nil,
+	}
+}
+
+// OK reports whether the zone is delegated to the expected nameservers with the required glue
+// and all of them answer authoritatively with the same SOA serial.
+func (r *Report) OK() bool {
+	if len(r.Missing) > 0 || len(r.Unexpected) > 0 || len(r.MissingGlue) > 0 || !r.SerialConsistent {
+		return false
+	}
+	for _, server := range r.Servers {
+		if server.Err != nil || !server.Authoritative {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CheckZone checks delegation of the zone.
+// Nameservers returned by the API for the zone are expected if the checker doesn't list its own.
+func (c *Checker) CheckZone(ctx context.Context, zone *v2.Zone) (*Report, error) {
+	checker := *c
+	if len(checker.Expected) == 0 {
+		checker.Expected = zone.Nameservers
+	}
+
+	return checker.Check(ctx, zone.Name)
+}
+
+// Check checks delegation of the zone with the given name.
+// An error is returned only if the delegation can't be found at all,
+// other problems are described by the report.
+func (c *Checker) Check(ctx context.Context, zoneName string) (*Report, error) {
+	zoneName = rrconv.CanonicalName(zoneName)
+	expected := c.Expected
+	if len(expected) == 0 {
+		expected = DefaultNameservers
+	}
+	//nolint: exhaustruct
+	report := &Report{
+		Zone:      zoneName,
+		Expected:  canonicalNames(expected),
+		Glue:      make(map[string][]string),
+		CheckedAt: time.Now(),
+	}
+
+	parent, err := c.findParent(ctx, zoneName)
+	if err != nil {
+		return nil, err
+	}
+	report.Parent = parent
+	parentServers, err := c.nameservers(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	referral, server, err := c.queryParent(ctx, zoneName, parentServers)
+	if err != nil {
+		return nil, err
+	}
+	report.ParentServer = server
+
+	for _, rr := range append(referral.Answer, referral.Ns...) {
+		if ns, ok := rr.(*dns.NS); ok && rrconv.CanonicalName(ns.Hdr.Name) == zoneName {
+			report.Delegated = appendUnique(report.Delegated, rrconv.CanonicalName(ns.Ns))
+		}
+	}
+	if len(report.Delegated) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotDelegated, zoneName)
+	}
+	sort.Strings(report.Delegated)
+	for _, rr := range referral.Extra {
+		name := rrconv.CanonicalName(rr.Header().Name)
+		if !contains(report.Delegated, name) {
+			continue
+		}
+		switch rr := rr.(type) {
+		case *dns.A:
+			report.Glue[name] = append(report.Glue[name], rr.A.String())
+		case *dns.AAAA:
+			report.Glue[name] = append(report.Glue[name], rr.AAAA.String())
+		}
+	}
+
+	for _, name := range report.Expected {
+		if !contains(report.Delegated, name) {
+			report.Missing = append(report.Missing, name)
+		}
+	}
+	for _, name := range report.Delegated {
+		if !contains(report.Expected, name) {
+			report.Unexpected = append(report.Unexpected, name)
+		}
+		if dns.IsSubDomain(zoneName, name) && len(report.Glue[name]) == 0 {
+			report.MissingGlue = append(report.MissingGlue, name)
+		}
+	}
+
+	report.Servers = c.checkServers(ctx, zoneName, report.Delegated, report.Glue)
+	report.SerialConsistent = serialConsistent(report.Servers)
+
+	return report, nil
+}
+
+// findParent returns the name of the zone the given zone is delegated from.
+func (c *Checker) findParent(ctx context.Context, zoneName string) (string, error) {
+	labels := dns.SplitDomainName(zoneName)
+	if len(labels) <= 1 {
+		return ".", nil
+	}
+	candidate := dns.Fqdn(strings.Join(labels[1:], "."))
+	resp, err := c.exchange(ctx, c.Resolver, candidate, dns.TypeSOA, true)
+	if err != nil {
+		return "", fmt.Errorf("find parent zone: %w", err)
+	}
+	for _, rr := range append(resp.Answer, resp.Ns...) {
+		if soa, ok := rr.(*dns.SOA); ok {
+			return rrconv.CanonicalName(soa.Hdr.Name), nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrNoParent, zoneName)
+}
+
+// nameservers returns names of nameservers of the zone known to the resolver.
+func (c *Checker) nameservers(ctx context.Context, zoneName string) ([]string, error) {
+	resp, err := c.exchange(ctx, c.Resolver, zoneName, dns.TypeNS, true)
+	if err != nil {
+		return nil, fmt.Errorf("list nameservers of %s: %w", zoneName, err)
+	}
+	var names []string
+	for _, rr := range resp.Answer {
+		if ns, ok := rr.(*dns.NS); ok {
+			names = appendUnique(names, rrconv.CanonicalName(ns.Ns))
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%w: %s has no NS records", ErrNoParentServers, zoneName)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// queryParent asks parent nameservers for NS records of the zone
+// and returns the first answer together with the address of the server that gave it.
+func (c *Checker) queryParent(ctx context.Context, zoneName string, servers []string) (*dns.Msg, string, error) {
+	var errs []error
+	for _, server := range servers {
+		addrs, err := c.resolve(ctx, server)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+		for _, addr := range addrs {
+			addr = c.hostPort(addr)
+			resp, err := c.exchange(ctx, addr, zoneName, dns.TypeNS, false)
+			if err == nil && resp.Rcode != dns.RcodeSuccess && resp.Rcode != dns.RcodeNameError {
+				err = fmt.Errorf("%w: %s from %s", ErrBadRcode, dns.RcodeToString[resp.Rcode], addr)
+			}
+			if err != nil {
+				errs = append(errs, err)
+
+				continue
+			}
+
+			return resp, addr, nil
+		}
+	}
+
+	return nil, "", fmt.Errorf("%w: %w", ErrNoParentServers, errors.Join(errs...))
+}
+
+// checkServers queries the SOA record of the zone on every address of the nameservers.
+func (c *Checker) checkServers(
+	ctx context.Context, zoneName string, names []string, glue map[string][]string,
+) []ServerStatus {
+	var statuses []ServerStatus
+	for _, name := range names {
+		addrs := glue[name]
+		if len(addrs) == 0 {
+			var err error
+			addrs, err = c.resolve(ctx, name)
+			if err != nil {
+				//nolint: exhaustruct
+				statuses = append(statuses, ServerStatus{Name: name, Err: err})
+
+				continue
+			}
+		}
+		for _, addr := range addrs {
+			//nolint: exhaustruct
+			status := ServerStatus{Name: name, Address: c.hostPort(addr)}
+			resp, err := c.exchange(ctx, status.Address, zoneName, dns.TypeSOA, false)
+			switch {
+			case err != nil:
+				status.Err = err
+			case resp.Rcode != dns.RcodeSuccess:
+				status.Err = fmt.Errorf("%w: %s", ErrBadRcode, dns.RcodeToString[resp.Rcode])
+			default:
+				status.Authoritative = resp.Authoritative
+				for _, rr := range resp.Answer {
+					if soa, ok := rr.(*dns.SOA); ok {
+						status.Serial = soa.Serial
+					}
+				}
+				if status.Serial == 0 {
+					status.Err = ErrNoSOA
+				}
+			}
+			statuses = append(statuses, status)
+		}
+	}
+
+	return statuses
+}
+
+// resolve returns IPv4 and IPv6 addresses of the host known to the resolver.
+func (c *Checker) resolve(ctx context.Context, host string) ([]string, error) {
+	var addrs []string
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		resp, err := c.exchange(ctx, c.Resolver, host, qtype, true)
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s: %w", host, err)
+		}
+		for _, rr := range resp.Answer {
+			switch rr := rr.(type) {
+			case *dns.A:
+				addrs = append(addrs, rr.A.String())
+			case *dns.AAAA:
+				addrs = append(addrs, rr.AAAA.String())
+			}
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("resolve %s: %w", host, ErrNoAddresses)
+	}
+
+	return addrs, nil
+}
+
+// exchange sends a single query to addr, retrying over TCP if the UDP answer is truncated.
+func (c *Checker) exchange(
+	ctx context.Context, addr, name string, qtype uint16, recursive bool,
+) (*dns.Msg, error) {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	msg := new(dns.Msg)
+	msg.SetQuestion(name, qtype)
+	msg.RecursionDesired = recursive
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	//nolint: exhaustruct
+	client := &dns.Client{Timeout: timeout}
+	resp, _, err := client.ExchangeContext(ctx, msg, addr)
+	if err == nil && resp.Truncated {
+		client.Net = "tcp"
+		resp, _, err = client.ExchangeContext(ctx, msg, addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query %s %s at %s: %w", name, dns.TypeToString[qtype], addr, err)
+	}
+
+	return resp, nil
+}
+
+func (c *Checker) hostPort(addr string) string {
+	port := c.Port
+	if port == 0 {
+		port = defaultPort
+	}
+
+	return net.JoinHostPort(addr, strconv.Itoa(port))
+}
+
+func serialConsistent(statuses []ServerStatus) bool {
+	var serial uint32
+	answered := false
+	for _, status := range statuses {
+		if status.Err != nil {
+			continue
+		}
+		if answered && status.Serial != serial {
+			return false
+		}
+		serial, answered = status.Serial, true
+	}
+
+	return answered
+}
+
+func canonicalNames(names []string) []string {
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		result = appendUnique(result, rrconv.CanonicalName(name))
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+func appendUnique(names []string, name string) []string {
+	if contains(names, name) {
+		return names
+	}
+
+	return append(names, name)
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
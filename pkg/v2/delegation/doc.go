@@ -0,0 +1,22 @@
+/*
+Package delegation verifies that a zone of the Selectel Domains API V2 is
+delegated correctly.
+
+The Checker finds the parent zone and its nameservers through a configurable
+resolver, asks the parent for the NS records of the zone and compares them
+with the expected nameservers. It also reports missing glue for nameservers
+inside the zone and queries the SOA record on every delegated nameserver to
+make sure they all serve the same serial.
+
+Example of checking delegation before switching traffic
+
+  checker := delegation.NewChecker("8.8.8.8:53")
+  report, err := checker.CheckZone(ctx, zone)
+  if err != nil {
+    log.Fatal(err)
+  }
+  if !report.OK() {
+    fmt.Println("missing:", report.Missing, "unexpected:", report.Unexpected)
+  }
+*/
+package delegation
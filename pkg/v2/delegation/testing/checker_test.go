@@ -0,0 +1,214 @@
+package testing
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/delegation"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testZoneName    = "bonnie-test.com."
+	testInZoneNS    = "ns1.bonnie-test.com."
+	testSelectelNS  = "a.ns.selectel.ru."
+	testParentNS    = "a.gtld-servers.test."
+	testMissingZone = "missing-test.com."
+	primaryAddr     = "127.0.0.1"
+	secondaryAddr   = "127.0.0.2"
+)
+
+type (
+	CheckerSuite struct {
+		suite.Suite
+		handler *fakeDNS
+		servers []*dns.Server
+		checker *delegation.Checker
+	}
+
+	// fakeDNS plays the resolver, the parent zone and the child zone at once.
+	// Serials of the child zone depend on the address the query came to.
+	fakeDNS struct {
+		mu      sync.Mutex
+		glue    bool
+		serials map[string]uint32
+	}
+)
+
+//nolint:paralleltest
+func TestChecker(t *testing.T) {
+	suite.Run(t, new(CheckerSuite))
+}
+
+func (s *CheckerSuite) SetupTest() {
+	s.handler = &fakeDNS{
+		mu:      sync.Mutex{},
+		glue:    true,
+		serials: map[string]uint32{primaryAddr: 2024010101, secondaryAddr: 2024010101},
+	}
+	s.servers = nil
+	port := 0
+	for _, addr := range []string{primaryAddr, secondaryAddr} {
+		conn, err := net.ListenPacket("udp", net.JoinHostPort(addr, strconv.Itoa(port)))
+		s.Require().NoError(err)
+		port = conn.LocalAddr().(*net.UDPAddr).Port
+		//nolint: exhaustruct
+		server := &dns.Server{PacketConn: conn, Handler: s.handler}
+		started := make(chan struct{})
+		server.NotifyStartedFunc = func() { close(started) }
+		go func() {
+			_ = server.ActivateAndServe()
+		}()
+		<-started
+		s.servers = append(s.servers, server)
+	}
+
+	s.checker = delegation.NewChecker(net.JoinHostPort(primaryAddr, strconv.Itoa(port)))
+	s.checker.Port = port
+	s.checker.Timeout = time.Second
+	s.checker.Expected = []string{testSelectelNS, "NS1.bonnie-test.com"}
+}
+
+func (s *CheckerSuite) TearDownTest() {
+	for _, server := range s.servers {
+		_ = server.Shutdown()
+	}
+}
+
+func (s *CheckerSuite) TestCheck() {
+	report, err := s.checker.Check(context.Background(), "Bonnie-Test.com")
+
+	s.Require().NoError(err)
+	s.True(report.OK())
+	s.Equal(testZoneName, report.Zone)
+	s.Equal("com.", report.Parent)
+	s.Equal([]string{testSelectelNS, testInZoneNS}, report.Delegated)
+	s.Empty(report.Missing)
+	s.Empty(report.Unexpected)
+	s.Equal(map[string][]string{testInZoneNS: {secondaryAddr}}, report.Glue)
+	s.Empty(report.MissingGlue)
+	s.Require().Len(report.Servers, 2)
+	for _, server := range report.Servers {
+		s.NoError(server.Err)
+		s.True(server.Authoritative)
+		s.Equal(uint32(2024010101), server.Serial)
+	}
+	s.True(report.SerialConsistent)
+}
+
+func (s *CheckerSuite) TestCheck_unexpected_nameservers() {
+	s.checker.Expected = nil
+
+	report, err := s.checker.Check(context.Background(), testZoneName)
+
+	s.Require().NoError(err)
+	s.False(report.OK())
+	s.Equal([]string{"b.ns.selectel.ru.", "c.ns.selectel.ru.", "d.ns.selectel.ru."}, report.Missing)
+	s.Equal([]string{testInZoneNS}, report.Unexpected)
+}
+
+func (s *CheckerSuite) TestCheckZone_uses_zone_nameservers() {
+	s.checker.Expected = nil
+	//nolint: exhaustruct
+	zone := &v2.Zone{Name: testZoneName, Nameservers: []string{testSelectelNS, testInZoneNS}}
+
+	report, err := s.checker.CheckZone(context.Background(), zone)
+
+	s.Require().NoError(err)
+	s.True(report.OK())
+}
+
+func (s *CheckerSuite) TestCheck_missing_glue() {
+	s.handler.setGlue(false)
+
+	report, err := s.checker.Check(context.Background(), testZoneName)
+
+	s.Require().NoError(err)
+	s.False(report.OK())
+	s.Equal([]string{testInZoneNS}, report.MissingGlue)
+	// Addresses of the nameserver are still found through the resolver.
+	s.Require().Len(report.Servers, 2)
+	s.NoError(report.Servers[1].Err)
+}
+
+func (s *CheckerSuite) TestCheck_inconsistent_serials() {
+	s.handler.setSerial(secondaryAddr, 2024010102)
+
+	report, err := s.checker.Check(context.Background(), testZoneName)
+
+	s.Require().NoError(err)
+	s.False(report.OK())
+	s.False(report.SerialConsistent)
+}
+
+func (s *CheckerSuite) TestCheck_not_delegated() {
+	_, err := s.checker.Check(context.Background(), testMissingZone)
+
+	s.ErrorIs(err, delegation.ErrNotDelegated)
+}
+
+func (f *fakeDNS) setGlue(glue bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.glue = glue
+}
+
+func (f *fakeDNS) setSerial(addr string, serial uint32) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.serials[addr] = serial
+}
+
+func (f *fakeDNS) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	resp := new(dns.Msg)
+	resp.SetReply(r)
+	question := r.Question[0]
+	name := dns.CanonicalName(question.Name)
+	switch {
+	case name == "com." && question.Qtype == dns.TypeSOA:
+		resp.Answer = append(resp.Answer, mustRR("com. 60 IN SOA a.gtld-servers.test. admin.test. 1 3600 600 86400 60"))
+	case name == "com." && question.Qtype == dns.TypeNS:
+		resp.Answer = append(resp.Answer, mustRR("com. 60 IN NS "+testParentNS))
+	case name == testParentNS && question.Qtype == dns.TypeA,
+		name == testSelectelNS && question.Qtype == dns.TypeA:
+		resp.Answer = append(resp.Answer, mustRR(name+" 60 IN A "+primaryAddr))
+	case name == testInZoneNS && question.Qtype == dns.TypeA:
+		resp.Answer = append(resp.Answer, mustRR(name+" 60 IN A "+secondaryAddr))
+	case name == testZoneName && question.Qtype == dns.TypeNS:
+		// Referral from the parent zone.
+		resp.Ns = append(resp.Ns,
+			mustRR(testZoneName+" 60 IN NS "+testInZoneNS),
+			mustRR(testZoneName+" 60 IN NS "+testSelectelNS),
+		)
+		if f.glue {
+			resp.Extra = append(resp.Extra, mustRR(testInZoneNS+" 60 IN A "+secondaryAddr))
+		}
+	case name == testZoneName && question.Qtype == dns.TypeSOA:
+		host, _, _ := net.SplitHostPort(w.LocalAddr().String())
+		soa := mustRR(testZoneName + " 60 IN SOA a.ns.selectel.ru. support.selectel.ru. 1 10800 3600 604800 60")
+		soa.(*dns.SOA).Serial = f.serials[host]
+		resp.Answer = append(resp.Answer, soa)
+		resp.Authoritative = true
+	case name == testMissingZone:
+		resp.Rcode = dns.RcodeNameError
+	}
+	_ = w.WriteMsg(resp)
+}
+
+func mustRR(s string) dns.RR {
+	rr, err := dns.NewRR(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return rr
+}
@@ -0,0 +1,42 @@
+/*
+Package dkim generates DKIM keys and publishes them in zones of the Selectel
+Domains API V2.
+
+Keys are generated locally, only the public key leaves the process: it is
+rendered as a v=DKIM1 TXT record, split into quoted strings of at most 255
+bytes, and published under <selector>._domainkey.<domain>.
+
+A Rotator keeps the state of selectors in the zone itself. Published rrsets
+are marked with ManagedBy and their Comment holds the selector state, so the
+set of active and retiring selectors can be recovered by any process. A
+rotation publishes a new selector and marks the active ones as retiring for a
+grace period, Cleanup removes retiring selectors whose grace period is over.
+
+Example of rotating a key
+
+  key, err := dkim.GenerateKey(dkim.KeyTypeEd25519, 0)
+  if err != nil {
+    log.Fatal(err)
+  }
+  rotator := dkim.NewRotator(client, zoneID, "example.com.")
+  rotator.GracePeriod = 7 * 24 * time.Hour
+  if _, err := rotator.Rotate(ctx, "mail2026", key.Public()); err != nil {
+    log.Fatal(err)
+  }
+  pemKey, err := dkim.MarshalPrivateKey(key)
+  if err != nil {
+    log.Fatal(err)
+  }
+  _ = os.WriteFile("mail2026.pem", pemKey, 0o600)
+
+Example of removing selectors after their grace period
+
+  removed, err := rotator.Cleanup(ctx)
+  if err != nil {
+    log.Fatal(err)
+  }
+  for _, selector := range removed {
+    fmt.Println("removed", selector.Name)
+  }
+*/
+package dkim
@@ -0,0 +1,200 @@
+package dkim
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	v2 "github.com/selectel/domains-go/pkg/v2"
+)
+
+const (
+	// Version represents the version tag of DKIM key records.
+	Version = "DKIM1"
+
+	// defaultRSABits represents the default size of generated RSA keys.
+	defaultRSABits = 2048
+
+	// minRSABits represents the minimum size of RSA keys accepted by verifiers.
+	minRSABits = 1024
+)
+
+// Types of DKIM keys.
+const (
+	KeyTypeRSA     KeyType = "rsa"
+	KeyTypeEd25519 KeyType = "ed25519"
+)
+
+var (
+	ErrUnsupportedKey = errors.New("unsupported DKIM key")
+	ErrInvalidRecord  = errors.New("invalid DKIM record")
+)
+
+type (
+	// KeyType represents the k= tag of a DKIM record.
+	KeyType string
+
+	// Record represents a DKIM key record.
+	Record struct {
+		KeyType KeyType
+		// PublicKey holds the decoded p= tag: DER encoded SubjectPublicKeyInfo for RSA keys
+		// and the raw 32 bytes for Ed25519 keys. It is empty for revoked keys.
+		PublicKey []byte
+		// Tags holds other tags of the record, e.g. t=y for testing mode.
+		Tags map[string]string
+	}
+)
+
+// GenerateKey generates a private key of the given type.
+// If bits is zero, RSA keys are 2048 bits long; bits is ignored for Ed25519 keys.
+func GenerateKey(keyType KeyType, bits int) (crypto.Signer, error) {
+	switch keyType {
+	case KeyTypeRSA:
+		if bits == 0 {
+			bits = defaultRSABits
+		}
+		if bits < minRSABits {
+			return nil, fmt.Errorf("%w: RSA keys must be at least %d bits", ErrUnsupportedKey, minRSABits)
+		}
+		key, err := rsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			return nil, fmt.Errorf("generate RSA key: %w", err)
+		}
+
+		return key, nil
+	case KeyTypeEd25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("generate Ed25519 key: %w", err)
+		}
+
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedKey, keyType)
+}
+
+// MarshalPrivateKey returns the private key as a PKCS #8 PEM block, as mail servers expect it.
+func MarshalPrivateKey(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("marshal private key: %w", err)
+	}
+
+	//nolint: exhaustruct
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// NewRecord returns a record publishing the public key.
+func NewRecord(publicKey crypto.PublicKey) (*Record, error) {
+	switch publicKey := publicKey.(type) {
+	case *rsa.PublicKey:
+		der, err := x509.MarshalPKIXPublicKey(publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("marshal public key: %w", err)
+		}
+
+		return &Record{KeyType: KeyTypeRSA, PublicKey: der, Tags: nil}, nil
+	case ed25519.PublicKey:
+		return &Record{KeyType: KeyTypeEd25519, PublicKey: append([]byte(nil), publicKey...), Tags: nil}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, publicKey)
+}
+
+// ParseRecord parses a DKIM record. Quoted TXT content, as stored in rrsets, is accepted too.
+func ParseRecord(data string) (*Record, error) {
+	data = v2.TXTData(data)
+	record := &Record{KeyType: KeyTypeRSA, PublicKey: nil, Tags: nil}
+	hasKey := false
+	for i, part := range strings.Split(data, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: tag %q has no value", ErrInvalidRecord, part)
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		switch name {
+		case "v":
+			if i != 0 || value != Version {
+				return nil, fmt.Errorf("%w: v=%s", ErrInvalidRecord, value)
+			}
+		case "k":
+			record.KeyType = KeyType(value)
+		case "p":
+			key, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+			if err != nil {
+				return nil, fmt.Errorf("%w: p=: %w", ErrInvalidRecord, err)
+			}
+			record.PublicKey, hasKey = key, true
+		default:
+			if record.Tags == nil {
+				record.Tags = make(map[string]string)
+			}
+			record.Tags[name] = value
+		}
+	}
+	if !hasKey {
+		return nil, fmt.Errorf("%w: p= tag is missing", ErrInvalidRecord)
+	}
+
+	return record, nil
+}
+
+// String returns the record in the DKIM tag-value syntax.
+func (r *Record) String() string {
+	parts := []string{"v=" + Version, "k=" + string(r.KeyType)}
+	for _, name := range sortedKeys(r.Tags) {
+		parts = append(parts, name+"="+r.Tags[name])
+	}
+	parts = append(parts, "p="+base64.StdEncoding.EncodeToString(r.PublicKey))
+
+	return strings.Join(parts, "; ")
+}
+
+// TXT returns the record as TXT content of an rrset, split into quoted strings of at most 255 bytes.
+func (r *Record) TXT() string {
+	return v2.EncodeTXT(r.String())
+}
+
+// PublicKeyValue returns the public key of the record.
+func (r *Record) PublicKeyValue() (crypto.PublicKey, error) {
+	switch r.KeyType {
+	case KeyTypeRSA:
+		key, err := x509.ParsePKIXPublicKey(r.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRecord, err)
+		}
+
+		return key, nil
+	case KeyTypeEd25519:
+		if len(r.PublicKey) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: Ed25519 key has %d bytes", ErrInvalidRecord, len(r.PublicKey))
+		}
+
+		return ed25519.PublicKey(r.PublicKey), nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedKey, r.KeyType)
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
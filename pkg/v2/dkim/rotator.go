@@ -0,0 +1,270 @@
+package dkim
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/rrconv"
+)
+
+const (
+	// ManagedBy marks rrsets published by the package.
+	ManagedBy = "domains-go/dkim"
+
+	// defaultTTL represents the default TTL of published key records.
+	defaultTTL = 3600
+
+	// defaultGracePeriod represents the default time a replaced selector is kept.
+	defaultGracePeriod = 7 * 24 * time.Hour
+
+	// commentPrefix starts comments that hold the selector state.
+	commentPrefix = "dkim"
+
+	// domainKeyLabel separates the selector from the domain in record names.
+	domainKeyLabel = "._domainkey."
+)
+
+// States of selectors.
+const (
+	StateActive   State = "active"
+	StateRetiring State = "retiring"
+)
+
+var ErrInvalidSelector = errors.New("invalid DKIM selector")
+
+type (
+	// State represents the state of a selector.
+	State string
+
+	// Selector represents a DKIM key published in the zone.
+	Selector struct {
+		Name    string
+		RRSetID string
+		State   State
+		// RetireAfter is the time after which a retiring selector is removed by Cleanup.
+		RetireAfter time.Time
+		Record      *Record
+
+		rrset *v2.RRSet
+	}
+
+	// Rotator publishes and rotates DKIM keys of a domain.
+	Rotator struct {
+		// TTL of published key records. If zero, defaultTTL is used.
+		TTL int
+
+		// GracePeriod represents the time a replaced selector is kept published,
+		// so mail signed with its key can still be verified. If zero, defaultGracePeriod is used.
+		GracePeriod time.Duration
+
+		// Now returns the current time. If nil, time.Now is used.
+		Now func() time.Time
+
+		manager v2.RRSetManager[v2.RRSet]
+		zoneID  string
+		domain  string
+	}
+)
+
+// RecordName returns the name of the key record of the selector.
+func RecordName(selector, domain string) string {
+	return rrconv.CanonicalName(selector + domainKeyLabel + domain)
+}
+
+// NewRotator returns a rotator of keys of the domain kept in the zone.
+func NewRotator(manager v2.RRSetManager[v2.RRSet], zoneID, domain string) *Rotator {
+	return &Rotator{
+		TTL:         defaultTTL,
+		GracePeriod: defaultGracePeriod,
+		Now:         time.Now,
+		manager:     manager,
+		zoneID:      zoneID,
+		domain:      rrconv.CanonicalName(domain),
+	}
+}
+
+// Selectors returns selectors of the domain published by the package, ordered by name.
+func (r *Rotator) Selectors(ctx context.Context) ([]Selector, error) {
+	options := map[string]string{"rrset_types": string(v2.TXT)}
+	rrsets, err := v2.ListAllRRSets[v2.RRSet](ctx, r.manager, r.zoneID, &options)
+	if err != nil {
+		return nil, fmt.Errorf("list rrsets: %w", err)
+	}
+	var selectors []Selector
+	for _, rrset := range rrsets {
+		name := rrconv.CanonicalName(rrset.Name)
+		if rrset.Type != v2.TXT || rrset.ManagedBy != ManagedBy || !strings.HasSuffix(name, domainKeyLabel+r.domain) {
+			continue
+		}
+		selector, err := selectorOf(rrset, strings.TrimSuffix(name, domainKeyLabel+r.domain))
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+	}
+	sort.Slice(selectors, func(i, j int) bool { return selectors[i].Name < selectors[j].Name })
+
+	return selectors, nil
+}
+
+// Publish publishes the public key under the selector as an active key.
+func (r *Rotator) Publish(ctx context.Context, selector string, publicKey crypto.PublicKey) (*Selector, error) {
+	if _, ok := dns.IsDomainName(selector); !ok || selector == "" || strings.HasSuffix(selector, ".") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSelector, selector)
+	}
+	record, err := NewRecord(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	ttl := r.TTL
+	if ttl == 0 {
+		ttl = defaultTTL
+	}
+	//nolint: exhaustruct
+	rrset := &v2.RRSet{
+		Name:      RecordName(selector, r.domain),
+		Type:      v2.TXT,
+		TTL:       ttl,
+		Comment:   stateComment(StateActive, time.Time{}),
+		ManagedBy: ManagedBy,
+		Records:   []v2.RecordItem{{Content: record.TXT(), Disabled: false}},
+	}
+	created, err := r.manager.CreateRRSet(ctx, r.zoneID, rrset)
+	if err != nil {
+		return nil, fmt.Errorf("create rrset: %w", err)
+	}
+
+	return &Selector{
+		Name:        selector,
+		RRSetID:     created.ID,
+		State:       StateActive,
+		RetireAfter: time.Time{},
+		Record:      record,
+		rrset:       created,
+	}, nil
+}
+
+// Rotate publishes the public key under a new selector and marks all other active selectors
+// as retiring until the grace period is over.
+func (r *Rotator) Rotate(ctx context.Context, selector string, publicKey crypto.PublicKey) (*Selector, error) {
+	selectors, err := r.Selectors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	published, err := r.Publish(ctx, selector, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	grace := r.GracePeriod
+	if grace == 0 {
+		grace = defaultGracePeriod
+	}
+	retireAfter := r.now().Add(grace).UTC().Truncate(time.Second)
+	for _, old := range selectors {
+		if old.State != StateActive {
+			continue
+		}
+		rrset := *old.rrset
+		rrset.Comment = stateComment(StateRetiring, retireAfter)
+		if err := r.manager.UpdateRRSet(ctx, r.zoneID, old.RRSetID, &rrset); err != nil {
+			return published, fmt.Errorf("retire selector %s: %w", old.Name, err)
+		}
+	}
+
+	return published, nil
+}
+
+// Cleanup removes retiring selectors whose grace period is over and returns them.
+func (r *Rotator) Cleanup(ctx context.Context) ([]Selector, error) {
+	selectors, err := r.Selectors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := r.now()
+	var removed []Selector
+	var errs []error
+	for _, selector := range selectors {
+		if selector.State != StateRetiring || now.Before(selector.RetireAfter) {
+			continue
+		}
+		if err := r.manager.DeleteRRSet(ctx, r.zoneID, selector.RRSetID); err != nil {
+			errs = append(errs, fmt.Errorf("remove selector %s: %w", selector.Name, err))
+
+			continue
+		}
+		removed = append(removed, selector)
+	}
+
+	return removed, errors.Join(errs...)
+}
+
+func (r *Rotator) now() time.Time {
+	if r.Now == nil {
+		return time.Now()
+	}
+
+	return r.Now()
+}
+
+func selectorOf(rrset *v2.RRSet, name string) (Selector, error) {
+	//nolint: exhaustruct
+	selector := Selector{Name: name, RRSetID: rrset.ID, rrset: rrset}
+	selector.State, selector.RetireAfter = parseStateComment(rrset.Comment)
+	for _, item := range rrset.Records {
+		if item.Disabled {
+			continue
+		}
+		record, err := ParseRecord(item.Content)
+		if err != nil {
+			return selector, fmt.Errorf("selector %s: %w", name, err)
+		}
+		selector.Record = record
+
+		break
+	}
+
+	return selector, nil
+}
+
+// stateComment returns the rrset comment holding the selector state.
+func stateComment(state State, retireAfter time.Time) string {
+	comment := fmt.Sprintf("%s state=%s", commentPrefix, state)
+	if !retireAfter.IsZero() {
+		comment += " retire-after=" + retireAfter.Format(time.RFC3339)
+	}
+
+	return comment
+}
+
+// parseStateComment returns the selector state held by the comment.
+// Selectors with comments changed by hand are considered active, so they are never removed unattended.
+func parseStateComment(comment string) (State, time.Time) {
+	fields := strings.Fields(comment)
+	if len(fields) == 0 || fields[0] != commentPrefix {
+		return StateActive, time.Time{}
+	}
+	state, retireAfter := StateActive, time.Time{}
+	for _, field := range fields[1:] {
+		name, value, _ := strings.Cut(field, "=")
+		switch name {
+		case "state":
+			state = State(value)
+		case "retire-after":
+			if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+				retireAfter = parsed
+			}
+		}
+	}
+	if state == StateRetiring && retireAfter.IsZero() {
+		return StateActive, time.Time{}
+	}
+
+	return state, retireAfter
+}
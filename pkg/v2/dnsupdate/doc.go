@@ -0,0 +1,28 @@
+/*
+Package dnsupdate provides an RFC 2136 dynamic DNS UPDATE gateway for the
+Selectel Domains API V2.
+
+The Gateway is a dns.Handler that accepts UPDATE messages for configured
+zones, checks their prerequisites against rrsets returned by the API and
+translates additions and deletions into rrset create, update and delete
+calls.
+
+Example of serving dynamic updates with TSIG authentication
+
+  gateway := dnsupdate.NewGateway(client, map[string]string{
+    "example.com.": zoneID,
+  })
+  gateway.RequireTSIG = true
+
+  server := &dns.Server{
+    Addr:          ":53",
+    Net:           "udp",
+    Handler:       gateway,
+    MsgAcceptFunc: dnsupdate.AcceptFunc,
+    TsigSecret:    map[string]string{"ddns-key.": secret},
+  }
+  if err := server.ListenAndServe(); err != nil {
+    log.Fatal(err)
+  }
+*/
+package dnsupdate
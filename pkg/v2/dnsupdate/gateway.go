@@ -0,0 +1,155 @@
+package dnsupdate
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/rrconv"
+)
+
+// defaultTimeout represents the default time limit for API calls made while serving a single update.
+const defaultTimeout = 30 * time.Second
+
+// qrBit represents the query/response flag in the DNS header bits.
+const qrBit = 1 << 15
+
+// Gateway translates RFC 2136 UPDATE messages into rrset operations of the Domains API V2.
+type Gateway struct {
+	// RequireTSIG makes the gateway refuse updates without a TSIG signature.
+	RequireTSIG bool
+
+	// Timeout limits the API calls made while serving a single update.
+	// If zero, defaultTimeout is used.
+	Timeout time.Duration
+
+	// ErrorLog specifies an optional logger for API errors.
+	ErrorLog *log.Logger
+
+	manager v2.RRSetManager[v2.RRSet]
+	zones   map[string]string
+	mu      sync.Mutex
+}
+
+// NewGateway returns a gateway serving updates for zones,
+// which maps zone names to zone ids of the Domains API V2.
+func NewGateway(manager v2.RRSetManager[v2.RRSet], zones map[string]string) *Gateway {
+	gateway := &Gateway{
+		RequireTSIG: false,
+		Timeout:     defaultTimeout,
+		ErrorLog:    nil,
+		manager:     manager,
+		zones:       make(map[string]string, len(zones)),
+		mu:          sync.Mutex{},
+	}
+	for name, zoneID := range zones {
+		gateway.zones[rrconv.CanonicalName(name)] = zoneID
+	}
+
+	return gateway
+}
+
+// AcceptFunc is a dns.MsgAcceptFunc that lets UPDATE messages through to the handler.
+// Other messages are checked by dns.DefaultMsgAcceptFunc.
+func AcceptFunc(dh dns.Header) dns.MsgAcceptAction {
+	if dh.Bits&qrBit != 0 {
+		return dns.MsgIgnore
+	}
+	if opcode := int(dh.Bits>>11) & 0xF; opcode != dns.OpcodeUpdate {
+		return dns.DefaultMsgAcceptFunc(dh)
+	}
+	if dh.Qdcount != 1 {
+		return dns.MsgReject
+	}
+
+	return dns.MsgAccept
+}
+
+// ServeDNS implements dns.Handler.
+func (g *Gateway) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	response := new(dns.Msg)
+	response.SetRcode(r, g.serveUpdate(w, r))
+	if tsig := r.IsTsig(); tsig != nil && w.TsigStatus() == nil {
+		response.SetTsig(tsig.Hdr.Name, tsig.Algorithm, tsig.Fudge, time.Now().Unix())
+	}
+	_ = w.WriteMsg(response)
+}
+
+func (g *Gateway) serveUpdate(w dns.ResponseWriter, r *dns.Msg) int {
+	if r.Opcode != dns.OpcodeUpdate {
+		return dns.RcodeNotImplemented
+	}
+	if r.IsTsig() != nil {
+		if w.TsigStatus() != nil {
+			return dns.RcodeNotAuth
+		}
+	} else if g.RequireTSIG {
+		return dns.RcodeRefused
+	}
+	if len(r.Question) != 1 || r.Question[0].Qtype != dns.TypeSOA {
+		return dns.RcodeFormatError
+	}
+	zoneName := rrconv.CanonicalName(r.Question[0].Name)
+	zoneID, ok := g.zones[zoneName]
+	if !ok {
+		return dns.RcodeNotAuth
+	}
+
+	timeout := g.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Updates of a zone are applied one by one, so prerequisites are checked
+	// against the state the update is applied to.
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	rrsets, err := v2.ListAllRRSets[v2.RRSet](ctx, g.manager, zoneID, nil)
+	if err != nil {
+		g.logf("list rrsets of zone %s: %v", zoneName, err)
+
+		return dns.RcodeServerFailure
+	}
+	state := newZoneState(zoneName, rrsets)
+	if rcode := state.checkPrerequisites(r.Answer); rcode != dns.RcodeSuccess {
+		return rcode
+	}
+	if rcode := state.prescan(r.Ns); rcode != dns.RcodeSuccess {
+		return rcode
+	}
+	state.apply(r.Ns)
+	if err := state.commit(ctx, g.manager, zoneID); err != nil {
+		g.logf("update zone %s: %v", zoneName, err)
+
+		return dns.RcodeServerFailure
+	}
+
+	return dns.RcodeSuccess
+}
+
+func (g *Gateway) logf(format string, args ...interface{}) {
+	if g.ErrorLog != nil {
+		g.ErrorLog.Printf(format, args...)
+	}
+}
+
+func inZone(name, zoneName string) bool {
+	return name == zoneName || zoneName == "." || strings.HasSuffix(name, "."+zoneName)
+}
+
+func isMetaType(rrType uint16) bool {
+	switch rrType {
+	case dns.TypeANY, dns.TypeAXFR, dns.TypeIXFR, dns.TypeMAILA, dns.TypeMAILB,
+		dns.TypeOPT, dns.TypeTSIG, dns.TypeTKEY:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,350 @@
+package dnsupdate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/miekg/dns"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/rrconv"
+)
+
+type (
+	// rrsetState holds enabled records of an rrset and its TTL.
+	rrsetState struct {
+		ttl uint32
+		rrs []dns.RR
+	}
+
+	// zoneState represents the zone contents an update is checked and applied against.
+	zoneState struct {
+		zone string
+		// rrsets holds rrsets as returned by the API.
+		rrsets  map[rrconv.Key]*v2.RRSet
+		current map[rrconv.Key]*rrsetState
+		desired map[rrconv.Key]*rrsetState
+		// opaque holds owner names of rrsets that can't be represented as DNS records.
+		opaque map[string]bool
+	}
+)
+
+func newZoneState(zoneName string, rrsets []*v2.RRSet) *zoneState {
+	state := &zoneState{
+		zone:    zoneName,
+		rrsets:  make(map[rrconv.Key]*v2.RRSet),
+		current: make(map[rrconv.Key]*rrsetState),
+		desired: make(map[rrconv.Key]*rrsetState),
+		opaque:  make(map[string]bool),
+	}
+	for _, rrset := range rrsets {
+		name := rrconv.CanonicalName(rrset.Name)
+		rrType, err := rrconv.RRType(rrset.Type)
+		if err != nil {
+			state.opaque[name] = true
+
+			continue
+		}
+		rrs, err := rrconv.ToRRs(rrset)
+		if err != nil {
+			state.opaque[name] = true
+
+			continue
+		}
+		key := rrconv.Key{Name: name, Type: rrType}
+		state.rrsets[key] = rrset
+		state.current[key] = &rrsetState{ttl: uint32(rrset.TTL), rrs: rrs}
+		state.desired[key] = &rrsetState{ttl: uint32(rrset.TTL), rrs: append([]dns.RR(nil), rrs...)}
+	}
+
+	return state
+}
+
+func (s *zoneState) nameInUse(name string) bool {
+	if s.opaque[name] {
+		return true
+	}
+	for key, rrset := range s.current {
+		if key.Name == name && len(rrset.rrs) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *zoneState) currentRRs(key rrconv.Key) []dns.RR {
+	if rrset, ok := s.current[key]; ok {
+		return rrset.rrs
+	}
+
+	return nil
+}
+
+func (s *zoneState) desiredRRs(key rrconv.Key) []dns.RR {
+	if rrset, ok := s.desired[key]; ok {
+		return rrset.rrs
+	}
+
+	return nil
+}
+
+// checkPrerequisites checks the prerequisite section as described in RFC 2136, section 3.2.
+func (s *zoneState) checkPrerequisites(prerequisites []dns.RR) int {
+	expected := make(map[rrconv.Key][]dns.RR)
+	for _, rr := range prerequisites {
+		header := rr.Header()
+		if header.Ttl != 0 {
+			return dns.RcodeFormatError
+		}
+		key := rrconv.KeyOf(rr)
+		if !inZone(key.Name, s.zone) {
+			return dns.RcodeNotZone
+		}
+		switch header.Class {
+		case dns.ClassANY:
+			if header.Rdlength != 0 {
+				return dns.RcodeFormatError
+			}
+			if key.Type == dns.TypeANY {
+				if !s.nameInUse(key.Name) {
+					return dns.RcodeNameError
+				}
+			} else if len(s.currentRRs(key)) == 0 {
+				return dns.RcodeNXRrset
+			}
+		case dns.ClassNONE:
+			if header.Rdlength != 0 {
+				return dns.RcodeFormatError
+			}
+			if key.Type == dns.TypeANY {
+				if s.nameInUse(key.Name) {
+					return dns.RcodeYXDomain
+				}
+			} else if len(s.currentRRs(key)) > 0 {
+				return dns.RcodeYXRrset
+			}
+		case dns.ClassINET:
+			expected[key] = append(expected[key], rr)
+		default:
+			return dns.RcodeFormatError
+		}
+	}
+	for key, rrs := range expected {
+		if !rrconv.Equal(rrs, s.currentRRs(key)) {
+			return dns.RcodeNXRrset
+		}
+	}
+
+	return dns.RcodeSuccess
+}
+
+// prescan validates the update section as described in RFC 2136, section 3.4.1.
+func (s *zoneState) prescan(updates []dns.RR) int {
+	for _, rr := range updates {
+		header := rr.Header()
+		if !inZone(rrconv.CanonicalName(header.Name), s.zone) {
+			return dns.RcodeNotZone
+		}
+		switch header.Class {
+		case dns.ClassINET:
+			if isMetaType(header.Rrtype) {
+				return dns.RcodeFormatError
+			}
+			if _, ok := rrconv.RecordType(header.Rrtype); !ok {
+				return dns.RcodeRefused
+			}
+		case dns.ClassANY:
+			if header.Ttl != 0 || header.Rdlength != 0 ||
+				(isMetaType(header.Rrtype) && header.Rrtype != dns.TypeANY) {
+				return dns.RcodeFormatError
+			}
+		case dns.ClassNONE:
+			if header.Ttl != 0 || isMetaType(header.Rrtype) {
+				return dns.RcodeFormatError
+			}
+		default:
+			return dns.RcodeFormatError
+		}
+	}
+
+	return dns.RcodeSuccess
+}
+
+// apply applies the update section to the desired state as described in RFC 2136, section 3.4.2.
+// SOA records are maintained by the API, so changes to them are ignored.
+func (s *zoneState) apply(updates []dns.RR) {
+	for _, rr := range updates {
+		header := rr.Header()
+		key := rrconv.KeyOf(rr)
+		apex := key.Name == s.zone
+		switch header.Class {
+		case dns.ClassINET:
+			s.add(key, rr)
+		case dns.ClassANY:
+			for desiredKey, rrset := range s.desired {
+				if desiredKey.Name != key.Name || (key.Type != dns.TypeANY && desiredKey.Type != key.Type) {
+					continue
+				}
+				if apex && (desiredKey.Type == dns.TypeSOA || desiredKey.Type == dns.TypeNS) {
+					continue
+				}
+				rrset.rrs = nil
+			}
+		case dns.ClassNONE:
+			s.remove(key, rr, apex)
+		}
+	}
+}
+
+func (s *zoneState) add(key rrconv.Key, rr dns.RR) {
+	if key.Type == dns.TypeSOA {
+		return
+	}
+	cnameKey := rrconv.Key{Name: key.Name, Type: dns.TypeCNAME}
+	if key.Type == dns.TypeCNAME {
+		for desiredKey, rrset := range s.desired {
+			if desiredKey.Name == key.Name && desiredKey.Type != dns.TypeCNAME && len(rrset.rrs) > 0 {
+				return
+			}
+		}
+	} else if len(s.desiredRRs(cnameKey)) > 0 {
+		return
+	}
+
+	rrset, ok := s.desired[key]
+	if !ok {
+		rrset = &rrsetState{ttl: 0, rrs: nil}
+		s.desired[key] = rrset
+	}
+	rrset.ttl = rr.Header().Ttl
+	if key.Type == dns.TypeCNAME {
+		rrset.rrs = []dns.RR{rr}
+
+		return
+	}
+	for i, existing := range rrset.rrs {
+		if dns.IsDuplicate(existing, rr) {
+			rrset.rrs[i] = rr
+
+			return
+		}
+	}
+	rrset.rrs = append(rrset.rrs, rr)
+}
+
+func (s *zoneState) remove(key rrconv.Key, rr dns.RR, apex bool) {
+	rrset, ok := s.desired[key]
+	if !ok || key.Type == dns.TypeSOA {
+		return
+	}
+	// The record is sent with class NONE, compare it as if it was in the zone class.
+	target := dns.Copy(rr)
+	target.Header().Class = dns.ClassINET
+	for i, existing := range rrset.rrs {
+		if !dns.IsDuplicate(existing, target) {
+			continue
+		}
+		if apex && key.Type == dns.TypeNS && len(rrset.rrs) == 1 {
+			return
+		}
+		rrset.rrs = append(rrset.rrs[:i:i], rrset.rrs[i+1:]...)
+
+		return
+	}
+}
+
+// commit sends the difference between the current and the desired state to the API.
+// Deletions are sent first, so an rrset can be replaced by a CNAME in a single update.
+func (s *zoneState) commit(ctx context.Context, manager v2.RRSetManager[v2.RRSet], zoneID string) error {
+	keys := make([]rrconv.Key, 0, len(s.desired))
+	for key := range s.desired {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		iDeleted, jDeleted := len(s.desired[keys[i]].rrs) == 0, len(s.desired[keys[j]].rrs) == 0
+		if iDeleted != jDeleted {
+			return iDeleted
+		}
+
+		return keys[i].String() < keys[j].String()
+	})
+
+	for _, key := range keys {
+		desired := s.desired[key]
+		current, exists := s.current[key]
+		if exists && desired.ttl == current.ttl && rrconv.Equal(desired.rrs, current.rrs) {
+			continue
+		}
+		rrset := s.rrsets[key]
+		switch {
+		case len(desired.rrs) == 0 && rrset == nil:
+		case len(desired.rrs) == 0 && hasDisabled(rrset):
+			// Only the served records are removed, the rrset stays with its disabled ones.
+			updated := updatedRRSet(rrset, desired)
+			updated.TTL = rrset.TTL
+			if err := manager.UpdateRRSet(ctx, zoneID, rrset.ID, updated); err != nil {
+				return fmt.Errorf("update rrset %s: %w", key, err)
+			}
+		case len(desired.rrs) == 0:
+			if err := manager.DeleteRRSet(ctx, zoneID, rrset.ID); err != nil {
+				return fmt.Errorf("delete rrset %s: %w", key, err)
+			}
+		case rrset == nil:
+			if _, err := manager.CreateRRSet(ctx, zoneID, newRRSet(key, desired)); err != nil {
+				return fmt.Errorf("create rrset %s: %w", key, err)
+			}
+		default:
+			if err := manager.UpdateRRSet(ctx, zoneID, rrset.ID, updatedRRSet(rrset, desired)); err != nil {
+				return fmt.Errorf("update rrset %s: %w", key, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func newRRSet(key rrconv.Key, desired *rrsetState) *v2.RRSet {
+	recordType, _ := rrconv.RecordType(key.Type)
+	//nolint: exhaustruct
+	return &v2.RRSet{
+		Name:    key.Name,
+		TTL:     int(desired.ttl),
+		Type:    recordType,
+		Records: recordItems(desired.rrs),
+	}
+}
+
+// updatedRRSet returns the rrset with desired records and TTL.
+// Disabled records aren't visible over DNS, so they are kept as is.
+func updatedRRSet(rrset *v2.RRSet, desired *rrsetState) *v2.RRSet {
+	updated := *rrset
+	updated.TTL = int(desired.ttl)
+	updated.Records = recordItems(desired.rrs)
+	for _, record := range rrset.Records {
+		if record.Disabled {
+			updated.Records = append(updated.Records, record)
+		}
+	}
+
+	return &updated
+}
+
+func hasDisabled(rrset *v2.RRSet) bool {
+	for _, record := range rrset.Records {
+		if record.Disabled {
+			return true
+		}
+	}
+
+	return false
+}
+
+func recordItems(rrs []dns.RR) []v2.RecordItem {
+	items := make([]v2.RecordItem, 0, len(rrs))
+	for _, rr := range rrs {
+		items = append(items, v2.RecordItem{Content: rrconv.Content(rr), Disabled: false})
+	}
+
+	return items
+}
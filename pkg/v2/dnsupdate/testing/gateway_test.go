@@ -0,0 +1,274 @@
+package testing
+
+import (
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/selectel/domains-go/pkg/testutils"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/dnsupdate"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testZoneName   = "bonnie-test.com."
+	testTSIGKey    = "ddns-key."
+	testTSIGSecret = "c2VjcmV0LXRlc3Qta2V5LWZvci1ib25uaWU="
+)
+
+type (
+	GatewaySuite struct {
+		suite.Suite
+		api    *testutils.FakeAPI
+		zone   *v2.Zone
+		server *dns.Server
+		addr   string
+	}
+)
+
+//nolint:paralleltest
+func TestGateway(t *testing.T) {
+	suite.Run(t, new(GatewaySuite))
+}
+
+func (s *GatewaySuite) SetupTest() {
+	s.api = testutils.NewFakeAPI()
+	s.zone = s.api.AddZone(testZoneName)
+	www := testutils.NewRRSet("www."+testZoneName, v2.A, "10.0.0.1", "10.0.0.9")
+	www.Records[1].Disabled = true
+	s.api.AddRRSet(s.zone.ID, *www)
+
+	gateway := dnsupdate.NewGateway(s.api.Client(), map[string]string{testZoneName: s.zone.ID})
+	gateway.RequireTSIG = true
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	started := make(chan struct{})
+	//nolint: exhaustruct
+	s.server = &dns.Server{
+		PacketConn:        conn,
+		Handler:           gateway,
+		MsgAcceptFunc:     dnsupdate.AcceptFunc,
+		TsigSecret:        map[string]string{testTSIGKey: testTSIGSecret},
+		NotifyStartedFunc: func() { close(started) },
+	}
+	go func() {
+		_ = s.server.ActivateAndServe()
+	}()
+	<-started
+	s.addr = conn.LocalAddr().String()
+}
+
+func (s *GatewaySuite) TearDownTest() {
+	_ = s.server.Shutdown()
+	s.api.Close()
+}
+
+func (s *GatewaySuite) exchange(msg *dns.Msg, signed bool) *dns.Msg {
+	response, err := s.exchangeWithErr(msg, signed)
+	s.Require().NoError(err)
+
+	return response
+}
+
+func (s *GatewaySuite) exchangeWithErr(msg *dns.Msg, signed bool) (*dns.Msg, error) {
+	//nolint: exhaustruct
+	client := &dns.Client{TsigSecret: map[string]string{testTSIGKey: testTSIGSecret}, Timeout: 5 * time.Second}
+	if signed {
+		msg.SetTsig(testTSIGKey, dns.HmacSHA256, 300, time.Now().Unix())
+	}
+	response, _, err := client.Exchange(msg, s.addr)
+
+	return response, err
+}
+
+func (s *GatewaySuite) newUpdate() *dns.Msg {
+	msg := new(dns.Msg)
+	msg.SetUpdate(testZoneName)
+
+	return msg
+}
+
+func (s *GatewaySuite) rrset(name string, recordType v2.RecordType) *v2.RRSet {
+	for _, rrset := range s.api.RRSets(s.zone.ID) {
+		if rrset.Name == name && rrset.Type == recordType {
+			return &rrset
+		}
+	}
+
+	return nil
+}
+
+func mustRR(s string) dns.RR {
+	rr, err := dns.NewRR(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return rr
+}
+
+func (s *GatewaySuite) TestInsert_creates_rrset() {
+	msg := s.newUpdate()
+	msg.Insert([]dns.RR{
+		mustRR("mail.bonnie-test.com. 300 IN MX 10 mx1.bonnie-test.com."),
+		mustRR("mail.bonnie-test.com. 300 IN MX 20 mx2.bonnie-test.com."),
+	})
+
+	response := s.exchange(msg, true)
+
+	s.Equal(dns.RcodeSuccess, response.Rcode)
+	s.NotNil(response.IsTsig())
+	rrset := s.rrset("mail."+testZoneName, v2.MX)
+	s.Require().NotNil(rrset)
+	s.Equal(300, rrset.TTL)
+	s.Equal([]v2.RecordItem{
+		{Content: "10 mx1.bonnie-test.com.", Disabled: false},
+		{Content: "20 mx2.bonnie-test.com.", Disabled: false},
+	}, rrset.Records)
+}
+
+func (s *GatewaySuite) TestInsert_appends_record_and_keeps_disabled() {
+	msg := s.newUpdate()
+	msg.Insert([]dns.RR{mustRR("www.bonnie-test.com. 120 IN A 10.0.0.2")})
+
+	response := s.exchange(msg, true)
+
+	s.Equal(dns.RcodeSuccess, response.Rcode)
+	rrset := s.rrset("www."+testZoneName, v2.A)
+	s.Require().NotNil(rrset)
+	s.Equal(120, rrset.TTL)
+	s.Equal([]v2.RecordItem{
+		{Content: "10.0.0.1", Disabled: false},
+		{Content: "10.0.0.2", Disabled: false},
+		{Content: "10.0.0.9", Disabled: true},
+	}, rrset.Records)
+}
+
+func (s *GatewaySuite) addEnabledRRSet(name string) {
+	s.api.AddRRSet(s.zone.ID, *testutils.NewRRSet(name, v2.A, "10.0.0.5"))
+}
+
+func (s *GatewaySuite) TestRemoveRRset_deletes_rrset() {
+	s.addEnabledRRSet("api." + testZoneName)
+	msg := s.newUpdate()
+	msg.RemoveRRset([]dns.RR{mustRR("api.bonnie-test.com. 0 IN A 0.0.0.0")})
+
+	response := s.exchange(msg, true)
+
+	s.Equal(dns.RcodeSuccess, response.Rcode)
+	s.Nil(s.rrset("api."+testZoneName, v2.A))
+}
+
+func (s *GatewaySuite) TestRemoveRRset_keeps_disabled_records() {
+	msg := s.newUpdate()
+	msg.RemoveRRset([]dns.RR{mustRR("www.bonnie-test.com. 0 IN A 0.0.0.0")})
+
+	response := s.exchange(msg, true)
+
+	s.Equal(dns.RcodeSuccess, response.Rcode)
+	rrset := s.rrset("www."+testZoneName, v2.A)
+	s.Require().NotNil(rrset)
+	s.Equal(60, rrset.TTL)
+	s.Equal([]v2.RecordItem{{Content: "10.0.0.9", Disabled: true}}, rrset.Records)
+}
+
+func (s *GatewaySuite) TestCNAME_replaces_address_in_single_update() {
+	s.addEnabledRRSet("api." + testZoneName)
+	msg := s.newUpdate()
+	msg.RemoveName([]dns.RR{mustRR("api.bonnie-test.com. 0 IN A 0.0.0.0")})
+	msg.Insert([]dns.RR{mustRR("api.bonnie-test.com. 60 IN CNAME origin.example.org.")})
+
+	response := s.exchange(msg, true)
+
+	s.Equal(dns.RcodeSuccess, response.Rcode)
+	s.Nil(s.rrset("api."+testZoneName, v2.A))
+	s.NotNil(s.rrset("api."+testZoneName, v2.CNAME))
+}
+
+func (s *GatewaySuite) TestPrerequisite_rrset_not_used() {
+	msg := s.newUpdate()
+	msg.RRsetNotUsed([]dns.RR{mustRR("www.bonnie-test.com. 0 IN A 0.0.0.0")})
+	msg.Insert([]dns.RR{mustRR("www.bonnie-test.com. 60 IN A 10.0.0.3")})
+
+	response := s.exchange(msg, true)
+
+	s.Equal(dns.RcodeYXRrset, response.Rcode)
+	s.Len(s.rrset("www."+testZoneName, v2.A).Records, 2)
+}
+
+func (s *GatewaySuite) TestPrerequisite_name_used() {
+	msg := s.newUpdate()
+	msg.NameUsed([]dns.RR{mustRR("ftp.bonnie-test.com. 0 IN A 0.0.0.0")})
+
+	response := s.exchange(msg, true)
+
+	s.Equal(dns.RcodeNameError, response.Rcode)
+}
+
+func (s *GatewaySuite) TestPrerequisite_value_dependent() {
+	msg := s.newUpdate()
+	msg.Used([]dns.RR{mustRR("www.bonnie-test.com. 0 IN A 10.0.0.1")})
+	msg.Remove([]dns.RR{mustRR("www.bonnie-test.com. 0 IN A 10.0.0.1")})
+	msg.Insert([]dns.RR{mustRR("www.bonnie-test.com. 60 IN A 10.0.0.4")})
+
+	response := s.exchange(msg, true)
+
+	s.Equal(dns.RcodeSuccess, response.Rcode)
+	s.Equal([]v2.RecordItem{
+		{Content: "10.0.0.4", Disabled: false},
+		{Content: "10.0.0.9", Disabled: true},
+	}, s.rrset("www."+testZoneName, v2.A).Records)
+
+	msg = s.newUpdate()
+	msg.Used([]dns.RR{mustRR("www.bonnie-test.com. 0 IN A 10.0.0.1")})
+
+	response = s.exchange(msg, true)
+
+	s.Equal(dns.RcodeNXRrset, response.Rcode)
+}
+
+func (s *GatewaySuite) TestUnsigned_update_refused() {
+	msg := s.newUpdate()
+	msg.Insert([]dns.RR{mustRR("new.bonnie-test.com. 60 IN A 10.0.0.5")})
+
+	response := s.exchange(msg, false)
+
+	s.Equal(dns.RcodeRefused, response.Rcode)
+	s.Nil(s.rrset("new."+testZoneName, v2.A))
+}
+
+func (s *GatewaySuite) TestUnknown_zone_not_auth() {
+	msg := new(dns.Msg)
+	msg.SetUpdate("other-zone.com.")
+	msg.Insert([]dns.RR{mustRR("www.other-zone.com. 60 IN A 10.0.0.5")})
+
+	response, err := s.exchangeWithErr(msg, true)
+
+	// NOTAUTH shares its code with the TSIG BADSIG error, so the client reports it as such.
+	s.ErrorIs(err, dns.ErrAuth)
+	s.Require().NotNil(response)
+	s.Equal(dns.RcodeNotAuth, response.Rcode)
+}
+
+func (s *GatewaySuite) TestRecord_out_of_zone() {
+	msg := s.newUpdate()
+	msg.Insert([]dns.RR{mustRR("www.other-zone.com. 60 IN A 10.0.0.5")})
+
+	response := s.exchange(msg, true)
+
+	s.Equal(dns.RcodeNotZone, response.Rcode)
+}
+
+func (s *GatewaySuite) TestAPI_failure_server_failure() {
+	s.api.InjectFault(func(_ *http.Request) int { return http.StatusInternalServerError })
+	msg := s.newUpdate()
+	msg.Insert([]dns.RR{mustRR("new.bonnie-test.com. 60 IN A 10.0.0.5")})
+
+	response := s.exchange(msg, true)
+
+	s.Equal(dns.RcodeServerFailure, response.Rcode)
+}
@@ -1,4 +1,46 @@
 /*
 Package v2 provides a library to work with the Selectel Domains API V2.
+
+Example of decoding zones into an own model
+
+  client := v2.NewTypedClient[myZone, v2.RRSet](endpoint, httpClient, headers)
+  zone, err := client.GetZone(ctx, zoneID, nil)
+  if err != nil {
+    log.Fatal(err)
+  }
+  fmt.Printf("%+v\n", zone)
+
+Example of calling an endpoint the package doesn't cover
+
+  result, err := v2.Do[myResult](ctx, client, http.MethodGet, "/zones/"+zoneID+"/something", nil, nil, nil)
+  if err != nil {
+    log.Fatal(err)
+  }
+  fmt.Printf("%+v\n", result)
+
+Example of adding a record without overwriting concurrent changes
+
+  err := v2.UpdateRRSetWithMerge(ctx, client, zoneID, rrsetID, 3, func(current *v2.RRSet) (v2.Updatable, error) {
+    desired := *current
+    desired.Records = append(desired.Records, v2.RecordItem{Content: "10.0.0.2"})
+    return &desired, nil
+  })
+  if errors.Is(err, v2.ErrConflict) {
+    log.Println("rrset keeps changing, giving up")
+  }
+
+Example of using names relative to the zone
+
+  fmt.Println(zone.FQDN("www")) // www.example.com.
+  relativeClient := client.(*v2.Client).WithRelativeNames()
+  rrsets, err := relativeClient.ListRRSets(ctx, zone.ID, &map[string]string{"name": "www"})
+
+Example of publishing a TXT value longer than 255 bytes
+
+  rrset := &v2.RRSet{Name: "sel._domainkey.example.com.", Type: v2.TXT, TTL: 3600, Records: []v2.RecordItem{
+    v2.NewTXTRecordItem("v=DKIM1; k=rsa; p=" + publicKey),
+  }}
+  created, err := client.CreateRRSet(ctx, zoneID, rrset)
+  value, err := created.Records[0].TXTValue()
 */
 package v2
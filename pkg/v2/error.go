@@ -8,6 +8,9 @@ import (
 var (
 	ErrInvalidRequestObj = errors.New("failed to build request")
 	ErrNotFound          = errors.New("object not found")
+	ErrConflict          = errors.New("object was changed concurrently")
+	ErrNameOutsideZone   = errors.New("name is outside of the zone")
+	ErrInvalidTXT        = errors.New("invalid TXT record content")
 )
 
 type (
@@ -17,6 +20,15 @@ type (
 		Location    string `json:"location,omitempty"`
 		Code        int    `json:"code"`
 	}
+
+	// ConflictError is returned by conditional updates when the rrset doesn't match the expected version.
+	ConflictError struct {
+		RRSetID  string
+		Expected string
+		Actual   string
+		// Current holds the rrset as it was read for the comparison.
+		Current *RRSet
+	}
 )
 
 func (e BadResponseError) Error() string {
@@ -30,3 +42,12 @@ func (e BadResponseError) Error() string {
 
 	return err
 }
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("rrset %s was changed: expected version %s, got %s", e.RRSetID, e.Expected, e.Actual)
+}
+
+// Is makes errors.Is(err, ErrConflict) report true for conflict errors.
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
@@ -0,0 +1,67 @@
+package v2
+
+import (
+	"github.com/selectel/domains-go/pkg/idn"
+)
+
+// UnicodeName returns the zone name with ACE labels converted to Unicode, e.g. "пример.рф.".
+func (z *Zone) UnicodeName() string {
+	return idn.ToUnicode(z.Name)
+}
+
+// UnicodeName returns the rrset name with ACE labels converted to Unicode.
+func (s *RRSet) UnicodeName() string {
+	return idn.ToUnicode(s.Name)
+}
+
+// UnicodeContents returns contents of records with target names of CNAME, ALIAS, NS, MX and SRV records
+// converted to Unicode.
+func (s *RRSet) UnicodeContents() []string {
+	contents := make([]string, 0, len(s.Records))
+	for _, item := range s.Records {
+		contents = append(contents, idn.ContentToUnicode(string(s.Type), item.Content))
+	}
+
+	return contents
+}
+
+// asciiRecords returns records with target names converted to ASCII.
+func asciiRecords(recordType RecordType, records []RecordItem) ([]RecordItem, error) {
+	if records == nil {
+		return nil, nil
+	}
+	converted := make([]RecordItem, 0, len(records))
+	for _, item := range records {
+		content, err := idn.ContentToASCII(string(recordType), item.Content)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, RecordItem{Content: content, Disabled: item.Disabled})
+	}
+
+	return converted, nil
+}
+
+// asciiOptions returns a copy of options with names under the keys converted to ASCII.
+func asciiOptions(options *map[string]string, keys ...string) (*map[string]string, error) {
+	if options == nil {
+		return nil, nil
+	}
+	converted := make(map[string]string, len(*options))
+	for key, value := range *options {
+		converted[key] = value
+	}
+	for _, key := range keys {
+		value, ok := converted[key]
+		if !ok {
+			continue
+		}
+		name, err := idn.ToASCII(value)
+		if err != nil {
+			return nil, err
+		}
+		converted[key] = name
+	}
+
+	return &converted, nil
+}
@@ -0,0 +1,24 @@
+// Package backoff computes delays between retries of failed periodic work.
+package backoff
+
+import (
+	"math"
+	"time"
+)
+
+// Delay returns the interval doubled for every consecutive failure and capped at maxBackoff.
+// A maxBackoff of zero means the delay isn't capped.
+func Delay(interval, maxBackoff time.Duration, failures int) time.Duration {
+	delay := interval
+	for i := 0; i < failures && delay > 0 && delay <= math.MaxInt64/2; i++ {
+		if maxBackoff > 0 && delay >= maxBackoff {
+			break
+		}
+		delay *= 2
+	}
+	if maxBackoff > 0 && failures > 0 && delay > maxBackoff {
+		delay = maxBackoff
+	}
+
+	return delay
+}
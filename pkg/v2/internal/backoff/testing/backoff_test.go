@@ -0,0 +1,18 @@
+package testing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/selectel/domains-go/pkg/v2/internal/backoff"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelay(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, time.Minute, backoff.Delay(time.Minute, 10*time.Minute, 0))
+	assert.Equal(t, 4*time.Minute, backoff.Delay(time.Minute, 10*time.Minute, 2))
+	assert.Equal(t, 10*time.Minute, backoff.Delay(time.Minute, 10*time.Minute, 5))
+	assert.Equal(t, 32*time.Minute, backoff.Delay(time.Minute, 0, 5), "zero max backoff means no cap")
+	assert.Equal(t, time.Duration(1<<62), backoff.Delay(1, 0, 1000), "the delay doesn't overflow")
+}
@@ -0,0 +1,39 @@
+/*
+Package lint checks rrsets of a zone of the Selectel Domains API V2 for common
+DNS mistakes without sending any queries.
+
+Rules and their default severities:
+
+  cname-apex            error    CNAME at the zone apex
+  cname-coexistence     error    CNAME next to rrsets of other types
+  target-cname          warning  MX, NS and SRV targets that are CNAMEs
+  dangling-cname        warning  in-zone CNAME targets without rrsets
+  multiple-spf          error    more than one SPF record of a name
+  ttl-outlier           info     TTLs far from the zone median
+  missing-trailing-dot  warning  target names without a trailing dot
+  wildcard-shadowing    warning  names that stop a wildcard from matching
+  disabled-only         warning  rrsets with all records disabled
+
+Severities of rules are set in Config, SeverityOff disables a rule. Reports
+are encoded as JSON for CI gates and Failed tells whether findings reach a
+threshold.
+
+Example of failing a CI job on errors
+
+  rrsets, err := v2.ListAllRRSets[v2.RRSet](ctx, client, zone.ID, nil)
+  if err != nil {
+    log.Fatal(err)
+  }
+  config := &lint.Config{Rules: map[string]lint.Severity{lint.RuleTTLOutlier: lint.SeverityOff}}
+  report, err := lint.Lint(zone.Name, rrsets, config)
+  if err != nil {
+    log.Fatal(err)
+  }
+  if err := json.NewEncoder(os.Stdout).Encode(report); err != nil {
+    log.Fatal(err)
+  }
+  if report.Failed(lint.SeverityError) {
+    os.Exit(1)
+  }
+*/
+package lint
@@ -0,0 +1,230 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/rrconv"
+)
+
+// defaultTTLOutlierFactor represents how many times a TTL may differ from the zone median before it is reported.
+const defaultTTLOutlierFactor = 10
+
+// Severities of findings, ordered from the lowest. SeverityOff disables a rule.
+const (
+	SeverityOff Severity = iota
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+)
+
+var ErrUnknownRule = errors.New("unknown lint rule")
+
+var severityNames = map[Severity]string{
+	SeverityOff:     "off",
+	SeverityInfo:    "info",
+	SeverityWarning: "warning",
+	SeverityError:   "error",
+}
+
+type (
+	// Severity represents how serious a finding is.
+	Severity int
+
+	// Config represents lint options.
+	Config struct {
+		// Rules overrides default severities of rules by their ids.
+		Rules map[string]Severity
+
+		// TTLOutlierFactor is how many times a TTL may differ from the median TTL of the zone
+		// before the ttl-outlier rule reports it. If zero, defaultTTLOutlierFactor is used.
+		TTLOutlierFactor float64
+	}
+
+	// Finding describes a single problem found in the zone.
+	Finding struct {
+		Rule     string        `json:"rule"`
+		Severity Severity      `json:"severity"`
+		Name     string        `json:"name"`
+		Type     v2.RecordType `json:"type,omitempty"`
+		Message  string        `json:"message"`
+	}
+
+	// Report holds findings ordered by name, type and rule.
+	Report struct {
+		Zone     string    `json:"zone"`
+		Findings []Finding `json:"findings"`
+	}
+)
+
+// ParseSeverity parses a severity name such as "warning".
+func ParseSeverity(name string) (Severity, error) {
+	for severity, severityName := range severityNames {
+		if severityName == name {
+			return severity, nil
+		}
+	}
+
+	return SeverityOff, fmt.Errorf("unknown severity %q", name)
+}
+
+// String returns the severity name.
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// MarshalText returns the severity name, so reports are encoded with readable severities.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText parses the severity name.
+func (s *Severity) UnmarshalText(text []byte) error {
+	severity, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = severity
+
+	return nil
+}
+
+// Rules returns ids of all rules with their default severities.
+func Rules() map[string]Severity {
+	result := make(map[string]Severity, len(rules))
+	for _, r := range rules {
+		result[r.id] = r.severity
+	}
+
+	return result
+}
+
+// Validate checks that the config refers to known rules only.
+func (c *Config) Validate() error {
+	known := Rules()
+	for id := range c.Rules {
+		if _, ok := known[id]; !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownRule, id)
+		}
+	}
+
+	return nil
+}
+
+// Lint checks rrsets of the zone. A nil config uses default severities of all rules.
+func Lint(zoneName string, rrsets []*v2.RRSet, config *Config) (*Report, error) {
+	if config == nil {
+		config = &Config{Rules: nil, TTLOutlierFactor: 0}
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	index := newZoneIndex(zoneName, rrsets)
+	report := &Report{Zone: index.zone, Findings: []Finding{}}
+	for _, r := range rules {
+		severity := r.severity
+		if override, ok := config.Rules[r.id]; ok {
+			severity = override
+		}
+		if severity == SeverityOff {
+			continue
+		}
+		for _, finding := range r.check(index, config) {
+			finding.Rule, finding.Severity = r.id, severity
+			report.Findings = append(report.Findings, finding)
+		}
+	}
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+
+		return a.Rule < b.Rule
+	})
+
+	return report, nil
+}
+
+// Count returns the number of findings of at least the severity.
+func (r *Report) Count(severity Severity) int {
+	count := 0
+	for _, finding := range r.Findings {
+		if finding.Severity >= severity {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Failed reports whether the report has findings of at least the severity, which fails a CI gate.
+func (r *Report) Failed(threshold Severity) bool {
+	return r.Count(threshold) > 0
+}
+
+// WriteText writes findings one per line, like compiler diagnostics.
+func (r *Report) WriteText(w io.Writer) error {
+	for _, finding := range r.Findings {
+		name := finding.Name
+		if finding.Type != "" {
+			name += " " + string(finding.Type)
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s: %s [%s]\n", name, finding.Severity, finding.Message, finding.Rule); err != nil {
+			return fmt.Errorf("write report: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// zoneIndex holds rrsets of the zone by canonical name.
+type zoneIndex struct {
+	zone   string
+	names  []string
+	rrsets map[string][]*v2.RRSet
+}
+
+func newZoneIndex(zoneName string, rrsets []*v2.RRSet) *zoneIndex {
+	index := &zoneIndex{zone: rrconv.CanonicalName(zoneName), names: nil, rrsets: make(map[string][]*v2.RRSet)}
+	for _, rrset := range rrsets {
+		name := rrconv.CanonicalName(rrset.Name)
+		if _, ok := index.rrsets[name]; !ok {
+			index.names = append(index.names, name)
+		}
+		index.rrsets[name] = append(index.rrsets[name], rrset)
+	}
+	sort.Strings(index.names)
+
+	return index
+}
+
+// find returns the rrset of the name and type.
+func (z *zoneIndex) find(name string, recordType v2.RecordType) *v2.RRSet {
+	for _, rrset := range z.rrsets[rrconv.CanonicalName(name)] {
+		if rrset.Type == recordType {
+			return rrset
+		}
+	}
+
+	return nil
+}
+
+// each calls f for every rrset ordered by name.
+func (z *zoneIndex) each(f func(name string, rrset *v2.RRSet)) {
+	for _, name := range z.names {
+		for _, rrset := range z.rrsets[name] {
+			f(name, rrset)
+		}
+	}
+}
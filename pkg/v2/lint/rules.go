@@ -0,0 +1,289 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/miekg/dns"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/rrconv"
+	"github.com/selectel/domains-go/pkg/v2/spf"
+)
+
+// Ids of lint rules.
+const (
+	RuleCNAMEApex          = "cname-apex"
+	RuleCNAMECoexistence   = "cname-coexistence"
+	RuleTargetIsCNAME      = "target-cname"
+	RuleDanglingCNAME      = "dangling-cname"
+	RuleMultipleSPF        = "multiple-spf"
+	RuleTTLOutlier         = "ttl-outlier"
+	RuleMissingTrailingDot = "missing-trailing-dot"
+	RuleWildcardShadowing  = "wildcard-shadowing"
+	RuleDisabledOnly       = "disabled-only"
+)
+
+// minTTLSample represents the minimum number of rrsets the ttl-outlier rule needs to compute a median.
+const minTTLSample = 3
+
+// rule represents a single check with its default severity.
+type rule struct {
+	id       string
+	severity Severity
+	check    func(zone *zoneIndex, config *Config) []Finding
+}
+
+var rules = []rule{
+	{id: RuleCNAMEApex, severity: SeverityError, check: checkCNAMEApex},
+	{id: RuleCNAMECoexistence, severity: SeverityError, check: checkCNAMECoexistence},
+	{id: RuleTargetIsCNAME, severity: SeverityWarning, check: checkTargetIsCNAME},
+	{id: RuleDanglingCNAME, severity: SeverityWarning, check: checkDanglingCNAME},
+	{id: RuleMultipleSPF, severity: SeverityError, check: checkMultipleSPF},
+	{id: RuleTTLOutlier, severity: SeverityInfo, check: checkTTLOutlier},
+	{id: RuleMissingTrailingDot, severity: SeverityWarning, check: checkMissingTrailingDot},
+	{id: RuleWildcardShadowing, severity: SeverityWarning, check: checkWildcardShadowing},
+	{id: RuleDisabledOnly, severity: SeverityWarning, check: checkDisabledOnly},
+}
+
+func checkCNAMEApex(zone *zoneIndex, _ *Config) []Finding {
+	if zone.find(zone.zone, v2.CNAME) == nil {
+		return nil
+	}
+
+	return []Finding{newFinding(zone.zone, v2.CNAME, "CNAME at the zone apex conflicts with SOA and NS records")}
+}
+
+func checkCNAMECoexistence(zone *zoneIndex, _ *Config) []Finding {
+	var findings []Finding
+	for _, name := range zone.names {
+		if zone.find(name, v2.CNAME) == nil || len(zone.rrsets[name]) == 1 {
+			continue
+		}
+		var types []string
+		for _, rrset := range zone.rrsets[name] {
+			if rrset.Type != v2.CNAME {
+				types = append(types, string(rrset.Type))
+			}
+		}
+		sort.Strings(types)
+		findings = append(findings, newFinding(name, v2.CNAME,
+			fmt.Sprintf("CNAME coexists with %s rrsets", strings.Join(types, ", "))))
+	}
+
+	return findings
+}
+
+func checkTargetIsCNAME(zone *zoneIndex, _ *Config) []Finding {
+	var findings []Finding
+	zone.each(func(name string, rrset *v2.RRSet) {
+		if rrset.Type != v2.MX && rrset.Type != v2.NS && rrset.Type != v2.SRV {
+			return
+		}
+		for _, target := range targets(rrset) {
+			if zone.find(target, v2.CNAME) != nil {
+				findings = append(findings, newFinding(name, rrset.Type,
+					fmt.Sprintf("target %s is a CNAME", rrconv.CanonicalName(target))))
+			}
+		}
+	})
+
+	return findings
+}
+
+func checkDanglingCNAME(zone *zoneIndex, _ *Config) []Finding {
+	var findings []Finding
+	zone.each(func(name string, rrset *v2.RRSet) {
+		if rrset.Type != v2.CNAME {
+			return
+		}
+		for _, target := range targets(rrset) {
+			target = rrconv.CanonicalName(target)
+			if !dns.IsSubDomain(zone.zone, target) || len(zone.rrsets[target]) > 0 || zone.hasWildcard(target) {
+				continue
+			}
+			findings = append(findings, newFinding(name, rrset.Type,
+				fmt.Sprintf("target %s has no rrsets in the zone", target)))
+		}
+	})
+
+	return findings
+}
+
+func checkMultipleSPF(zone *zoneIndex, _ *Config) []Finding {
+	var findings []Finding
+	zone.each(func(name string, rrset *v2.RRSet) {
+		if rrset.Type != v2.TXT {
+			return
+		}
+		count := 0
+		for _, item := range rrset.Records {
+			if !item.Disabled && spf.IsSPF(v2.TXTData(item.Content)) {
+				count++
+			}
+		}
+		if count > 1 {
+			findings = append(findings, newFinding(name, rrset.Type,
+				fmt.Sprintf("%d SPF records make SPF evaluation fail", count)))
+		}
+	})
+
+	return findings
+}
+
+func checkTTLOutlier(zone *zoneIndex, config *Config) []Finding {
+	var ttls []int
+	zone.each(func(_ string, rrset *v2.RRSet) { ttls = append(ttls, rrset.TTL) })
+	if len(ttls) < minTTLSample {
+		return nil
+	}
+	sort.Ints(ttls)
+	median := float64(ttls[len(ttls)/2])
+	factor := config.TTLOutlierFactor
+	if factor == 0 {
+		factor = defaultTTLOutlierFactor
+	}
+	var findings []Finding
+	zone.each(func(name string, rrset *v2.RRSet) {
+		ttl := float64(rrset.TTL)
+		if ttl*factor < median || ttl > median*factor {
+			findings = append(findings, newFinding(name, rrset.Type,
+				fmt.Sprintf("TTL %d differs from the zone median %d more than %g times", rrset.TTL, int(median), factor)))
+		}
+	})
+
+	return findings
+}
+
+func checkMissingTrailingDot(zone *zoneIndex, _ *Config) []Finding {
+	var findings []Finding
+	zone.each(func(name string, rrset *v2.RRSet) {
+		for _, target := range targets(rrset) {
+			if !strings.HasSuffix(target, ".") {
+				findings = append(findings, newFinding(name, rrset.Type,
+					fmt.Sprintf("target %s has no trailing dot", target)))
+			}
+		}
+	})
+
+	return findings
+}
+
+// checkWildcardShadowing reports names that stop a wildcard from matching: an explicit name
+// next to a wildcard answers only for its own types, and an empty non-terminal answers for none.
+func checkWildcardShadowing(zone *zoneIndex, _ *Config) []Finding {
+	var findings []Finding
+	for _, wildcard := range zone.names {
+		if !strings.HasPrefix(wildcard, "*.") {
+			continue
+		}
+		parent := strings.TrimPrefix(wildcard, "*.")
+		wildcardTypes := zone.types(wildcard)
+		shadowing := make(map[string]bool)
+		var shadowed []string
+		for _, name := range zone.names {
+			if name == wildcard || name == parent || !dns.IsSubDomain(parent, name) {
+				continue
+			}
+			child := childOf(parent, name)
+			if !shadowing[child] {
+				shadowing[child] = true
+				shadowed = append(shadowed, child)
+			}
+		}
+		for _, child := range shadowed {
+			var missing []string
+			childTypes := zone.types(child)
+			for recordType := range wildcardTypes {
+				if !childTypes[recordType] {
+					missing = append(missing, string(recordType))
+				}
+			}
+			if len(missing) == 0 {
+				continue
+			}
+			sort.Strings(missing)
+			findings = append(findings, newFinding(child, "",
+				fmt.Sprintf("name shadows %s %s rrsets", wildcard, strings.Join(missing, ", "))))
+		}
+	}
+
+	return findings
+}
+
+func checkDisabledOnly(zone *zoneIndex, _ *Config) []Finding {
+	var findings []Finding
+	zone.each(func(name string, rrset *v2.RRSet) {
+		if len(rrset.Records) == 0 {
+			return
+		}
+		for _, item := range rrset.Records {
+			if !item.Disabled {
+				return
+			}
+		}
+		findings = append(findings, newFinding(name, rrset.Type, "all records are disabled, the rrset is not served"))
+	})
+
+	return findings
+}
+
+func newFinding(name string, recordType v2.RecordType, message string) Finding {
+	return Finding{Rule: "", Severity: SeverityOff, Name: name, Type: recordType, Message: message}
+}
+
+// types returns types of rrsets of the name.
+func (z *zoneIndex) types(name string) map[v2.RecordType]bool {
+	types := make(map[v2.RecordType]bool)
+	for _, rrset := range z.rrsets[name] {
+		types[rrset.Type] = true
+	}
+
+	return types
+}
+
+// hasWildcard reports whether a wildcard of the zone matches the name.
+func (z *zoneIndex) hasWildcard(name string) bool {
+	labels := dns.SplitDomainName(name)
+	for i := 1; i < len(labels); i++ {
+		parent := rrconv.CanonicalName(strings.Join(labels[i:], "."))
+		if !dns.IsSubDomain(z.zone, parent) {
+			break
+		}
+		if len(z.rrsets["*."+parent]) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// childOf returns the ancestor of the name that is a direct child of the parent.
+func childOf(parent, name string) string {
+	labels := dns.SplitDomainName(name)
+	depth := dns.CountLabel(parent)
+
+	return rrconv.CanonicalName(strings.Join(labels[len(labels)-depth-1:], "."))
+}
+
+// targets returns target names of CNAME, MX, NS and SRV records as written in enabled records.
+func targets(rrset *v2.RRSet) []string {
+	position := map[v2.RecordType]int{v2.CNAME: 0, v2.NS: 0, v2.MX: 1, v2.SRV: 3}
+	i, ok := position[rrset.Type]
+	if !ok {
+		return nil
+	}
+	var result []string
+	for _, item := range rrset.Records {
+		fields := strings.Fields(item.Content)
+		if item.Disabled || len(fields) <= i {
+			continue
+		}
+		// The root target of null MX and SRV records means there is no service.
+		if fields[i] != "." {
+			result = append(result, fields[i])
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,137 @@
+package testing
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/selectel/domains-go/pkg/testutils"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/lint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testZoneName = "bonnie-test.com."
+
+func findings(report *lint.Report, rule string) []lint.Finding {
+	var result []lint.Finding
+	for _, finding := range report.Findings {
+		if finding.Rule == rule {
+			result = append(result, finding)
+		}
+	}
+
+	return result
+}
+
+func names(found []lint.Finding) []string {
+	result := make([]string, 0, len(found))
+	for _, finding := range found {
+		result = append(result, finding.Name)
+	}
+
+	return result
+}
+
+func newZone() []*v2.RRSet {
+	disabled := testutils.NewRRSet("old."+testZoneName, v2.A, "192.0.2.9")
+	disabled.Records[0].Disabled = true
+
+	rrsets := []*v2.RRSet{
+		testutils.NewRRSet(testZoneName, v2.NS, "a.ns.selectel.ru.", "b.ns.selectel.ru."),
+		testutils.NewRRSet(testZoneName, v2.CNAME, "www.bonnie-test.com."),
+		testutils.NewRRSet(testZoneName, v2.MX, "10 mail.bonnie-test.com.", "20 mx2.bonnie-test.com"),
+		testutils.NewRRSet(testZoneName, v2.TXT, `"v=spf1 mx -all"`, `"v=spf1 " "-all"`, `"verification"`),
+		testutils.NewRRSet("www."+testZoneName, v2.A, "192.0.2.1"),
+		testutils.NewRRSet("www."+testZoneName, v2.TXT, `"www"`),
+		testutils.NewRRSet("mail."+testZoneName, v2.CNAME, "www.bonnie-test.com."),
+		testutils.NewRRSet("mx2."+testZoneName, v2.A, "192.0.2.2"),
+		testutils.NewRRSet("ftp."+testZoneName, v2.CNAME, "missing.bonnie-test.com."),
+		testutils.NewRRSet("ext."+testZoneName, v2.CNAME, "example.org."),
+		testutils.NewRRSet("_sip._tcp."+testZoneName, v2.SRV, "10 5 5060 mail.bonnie-test.com."),
+		testutils.NewRRSet("*.apps."+testZoneName, v2.A, "192.0.2.3"),
+		testutils.NewRRSet("*.apps."+testZoneName, v2.TXT, `"apps"`),
+		testutils.NewRRSet("api.apps."+testZoneName, v2.A, "192.0.2.4"),
+		testutils.NewRRSet("api.apps."+testZoneName, v2.TXT, `"api"`),
+		testutils.NewRRSet("web.apps."+testZoneName, v2.A, "192.0.2.5"),
+		testutils.NewRRSet("x.deep.apps."+testZoneName, v2.A, "192.0.2.6"),
+		testutils.NewRRSet("app1.apps."+testZoneName, v2.CNAME, "host.wild.apps.bonnie-test.com."),
+		disabled,
+	}
+	for _, rrset := range rrsets {
+		rrset.TTL = 3600
+	}
+
+	return append(rrsets, testutils.NewRRSet("fast."+testZoneName, v2.A, "192.0.2.7"))
+}
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+	report, err := lint.Lint(testZoneName, newZone(), nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, testZoneName, report.Zone)
+	assert.Equal(t, []string{testZoneName}, names(findings(report, lint.RuleCNAMEApex)))
+	assert.Equal(t, []string{testZoneName}, names(findings(report, lint.RuleCNAMECoexistence)))
+	assert.Equal(t, "CNAME coexists with MX, NS, TXT rrsets", findings(report, lint.RuleCNAMECoexistence)[0].Message)
+	assert.Equal(t, []string{"_sip._tcp." + testZoneName, testZoneName}, names(findings(report, lint.RuleTargetIsCNAME)))
+	assert.Equal(t, []string{"ftp." + testZoneName}, names(findings(report, lint.RuleDanglingCNAME)))
+	assert.Equal(t, []string{testZoneName}, names(findings(report, lint.RuleMultipleSPF)))
+	assert.Equal(t, []string{"fast." + testZoneName}, names(findings(report, lint.RuleTTLOutlier)))
+	assert.Equal(t, lint.SeverityInfo, findings(report, lint.RuleTTLOutlier)[0].Severity)
+	assert.Equal(t, "target mx2.bonnie-test.com has no trailing dot", findings(report, lint.RuleMissingTrailingDot)[0].Message)
+	shadowing := findings(report, lint.RuleWildcardShadowing)
+	assert.Equal(t, []string{"app1.apps." + testZoneName, "deep.apps." + testZoneName, "web.apps." + testZoneName},
+		names(shadowing))
+	assert.Equal(t, "name shadows *.apps.bonnie-test.com. A, TXT rrsets", shadowing[1].Message)
+	assert.Equal(t, []string{"old." + testZoneName}, names(findings(report, lint.RuleDisabledOnly)))
+	assert.True(t, report.Failed(lint.SeverityError))
+	assert.Equal(t, 3, report.Count(lint.SeverityError))
+}
+
+func TestLint_config(t *testing.T) {
+	t.Parallel()
+	config := &lint.Config{
+		Rules: map[string]lint.Severity{
+			lint.RuleCNAMEApex:        lint.SeverityOff,
+			lint.RuleCNAMECoexistence: lint.SeverityOff,
+			lint.RuleMultipleSPF:      lint.SeverityWarning,
+			lint.RuleDisabledOnly:     lint.SeverityError,
+		},
+		TTLOutlierFactor: 100,
+	}
+
+	report, err := lint.Lint(testZoneName, newZone(), config)
+
+	require.NoError(t, err)
+	assert.Empty(t, findings(report, lint.RuleCNAMEApex))
+	assert.Empty(t, findings(report, lint.RuleTTLOutlier))
+	assert.Equal(t, lint.SeverityWarning, findings(report, lint.RuleMultipleSPF)[0].Severity)
+	assert.Equal(t, 1, report.Count(lint.SeverityError))
+
+	_, err = lint.Lint(testZoneName, nil, &lint.Config{Rules: map[string]lint.Severity{"nope": lint.SeverityError}})
+	assert.ErrorIs(t, err, lint.ErrUnknownRule)
+}
+
+func TestReport_output(t *testing.T) {
+	t.Parallel()
+	report, err := lint.Lint(testZoneName, []*v2.RRSet{testutils.NewRRSet(testZoneName, v2.CNAME, "example.org.")}, nil)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(report)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"zone": "bonnie-test.com.", "findings": [{
+		"rule": "cname-apex", "severity": "error", "name": "bonnie-test.com.", "type": "CNAME",
+		"message": "CNAME at the zone apex conflicts with SOA and NS records"
+	}]}`, string(data))
+	var decoded lint.Report
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, report, &decoded)
+
+	var text bytes.Buffer
+	require.NoError(t, report.WriteText(&text))
+	assert.Equal(t, "bonnie-test.com. CNAME: error: CNAME at the zone apex conflicts with SOA and NS records [cname-apex]\n",
+		text.String())
+	assert.False(t, (&lint.Report{Zone: testZoneName, Findings: nil}).Failed(lint.SeverityInfo))
+}
@@ -0,0 +1,214 @@
+package mailpolicy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v2 "github.com/selectel/domains-go/pkg/v2"
+)
+
+const (
+	// DMARCVersion represents the version tag of DMARC records.
+	DMARCVersion = "DMARC1"
+
+	// DMARCLabel represents the label DMARC records are published under.
+	DMARCLabel = "_dmarc"
+)
+
+// Policies requested by DMARC records.
+const (
+	PolicyNone       Policy = "none"
+	PolicyQuarantine Policy = "quarantine"
+	PolicyReject     Policy = "reject"
+)
+
+// Alignment modes of DMARC identifiers.
+const (
+	AlignmentRelaxed Alignment = "r"
+	AlignmentStrict  Alignment = "s"
+)
+
+type (
+	// Policy represents the handling of messages failing DMARC.
+	Policy string
+
+	// Alignment represents the identifier alignment mode.
+	Alignment string
+
+	// DMARC represents a DMARC record of RFC 7489.
+	DMARC struct {
+		Policy Policy
+		// SubdomainPolicy is the sp= tag, if empty Policy applies to subdomains too.
+		SubdomainPolicy Policy
+		// Percent is the pct= tag, if nil the policy applies to all messages.
+		Percent *int
+		// AggregateURIs and ForensicURIs are mailto: URIs of the rua= and ruf= tags,
+		// optionally followed by a size limit like "!10m".
+		AggregateURIs []string
+		ForensicURIs  []string
+		// DKIMAlignment and SPFAlignment are the adkim= and aspf= tags, if empty relaxed alignment is used.
+		DKIMAlignment Alignment
+		SPFAlignment  Alignment
+		// FailureOptions is the fo= tag, a colon separated list of 0, 1, d and s.
+		FailureOptions string
+		// ReportInterval is the ri= tag in seconds, if zero the default of a day is used.
+		ReportInterval int
+	}
+)
+
+// DMARCName returns the name the DMARC record of the domain is published under.
+func DMARCName(domain string) string {
+	return recordName(DMARCLabel, domain)
+}
+
+// IsDMARC reports whether the TXT data is a DMARC record.
+func IsDMARC(data string) bool {
+	return hasVersion(data, DMARCVersion)
+}
+
+// ParseDMARC parses and validates a DMARC record. Quoted TXT content, as stored in rrsets, is accepted too.
+func ParseDMARC(data string) (*DMARC, error) {
+	tags, err := parseTags(data, DMARCVersion)
+	if err != nil {
+		return nil, err
+	}
+	//nolint: exhaustruct
+	record := &DMARC{}
+	for i, t := range tags {
+		switch t.name {
+		case "p":
+			if i != 0 {
+				return nil, fmt.Errorf("%w: p= must follow v=", ErrInvalidRecord)
+			}
+			record.Policy = Policy(strings.ToLower(t.value))
+		case "sp":
+			record.SubdomainPolicy = Policy(strings.ToLower(t.value))
+		case "pct":
+			percent, err := strconv.Atoi(t.value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: pct=%s", ErrInvalidRecord, t.value)
+			}
+			record.Percent = &percent
+		case "rua":
+			record.AggregateURIs = splitURIs(t.value)
+		case "ruf":
+			record.ForensicURIs = splitURIs(t.value)
+		case "adkim":
+			record.DKIMAlignment = Alignment(strings.ToLower(t.value))
+		case "aspf":
+			record.SPFAlignment = Alignment(strings.ToLower(t.value))
+		case "fo":
+			record.FailureOptions = t.value
+		case "ri":
+			interval, err := strconv.Atoi(t.value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: ri=%s", ErrInvalidRecord, t.value)
+			}
+			record.ReportInterval = interval
+		case "rf":
+			if !strings.EqualFold(t.value, "afrf") {
+				return nil, fmt.Errorf("%w: rf=%s", ErrInvalidRecord, t.value)
+			}
+		default:
+			// Unknown tags must be ignored by receivers.
+		}
+	}
+	if err := record.Validate(); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Validate checks tags of the record.
+func (r *DMARC) Validate() error {
+	if !validPolicy(r.Policy) {
+		return fmt.Errorf("%w: p=%s", ErrInvalidRecord, r.Policy)
+	}
+	if r.SubdomainPolicy != "" && !validPolicy(r.SubdomainPolicy) {
+		return fmt.Errorf("%w: sp=%s", ErrInvalidRecord, r.SubdomainPolicy)
+	}
+	if r.Percent != nil && (*r.Percent < 0 || *r.Percent > 100) {
+		return fmt.Errorf("%w: pct=%d", ErrInvalidRecord, *r.Percent)
+	}
+	for _, alignment := range []Alignment{r.DKIMAlignment, r.SPFAlignment} {
+		if alignment != "" && alignment != AlignmentRelaxed && alignment != AlignmentStrict {
+			return fmt.Errorf("%w: alignment %s", ErrInvalidRecord, alignment)
+		}
+	}
+	if r.FailureOptions != "" {
+		for _, option := range strings.Split(r.FailureOptions, ":") {
+			switch strings.TrimSpace(option) {
+			case "0", "1", "d", "s":
+			default:
+				return fmt.Errorf("%w: fo=%s", ErrInvalidRecord, r.FailureOptions)
+			}
+		}
+	}
+	if r.ReportInterval < 0 {
+		return fmt.Errorf("%w: ri=%d", ErrInvalidRecord, r.ReportInterval)
+	}
+	for _, uri := range append(append([]string(nil), r.AggregateURIs...), r.ForensicURIs...) {
+		if err := validateURI(uri, true, "mailto"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Label returns the label the record is published under.
+func (r *DMARC) Label() string {
+	return DMARCLabel
+}
+
+// Matches reports whether the TXT data is a DMARC record.
+func (r *DMARC) Matches(data string) bool {
+	return IsDMARC(data)
+}
+
+// String returns the record in the DMARC tag-value syntax.
+func (r *DMARC) String() string {
+	tags := []tag{{name: "p", value: string(r.Policy)}}
+	if r.SubdomainPolicy != "" {
+		tags = append(tags, tag{name: "sp", value: string(r.SubdomainPolicy)})
+	}
+	if r.Percent != nil {
+		tags = append(tags, tag{name: "pct", value: strconv.Itoa(*r.Percent)})
+	}
+	if len(r.AggregateURIs) > 0 {
+		tags = append(tags, tag{name: "rua", value: strings.Join(r.AggregateURIs, ",")})
+	}
+	if len(r.ForensicURIs) > 0 {
+		tags = append(tags, tag{name: "ruf", value: strings.Join(r.ForensicURIs, ",")})
+	}
+	if r.DKIMAlignment != "" {
+		tags = append(tags, tag{name: "adkim", value: string(r.DKIMAlignment)})
+	}
+	if r.SPFAlignment != "" {
+		tags = append(tags, tag{name: "aspf", value: string(r.SPFAlignment)})
+	}
+	if r.FailureOptions != "" {
+		tags = append(tags, tag{name: "fo", value: r.FailureOptions})
+	}
+	if r.ReportInterval != 0 {
+		tags = append(tags, tag{name: "ri", value: strconv.Itoa(r.ReportInterval)})
+	}
+
+	return formatTags(DMARCVersion, tags)
+}
+
+// TXT returns the record as TXT content of an rrset, split into quoted strings of at most 255 bytes.
+func (r *DMARC) TXT() string {
+	return v2.EncodeTXT(r.String())
+}
+
+func validPolicy(policy Policy) bool {
+	switch policy {
+	case PolicyNone, PolicyQuarantine, PolicyReject:
+		return true
+	}
+
+	return false
+}
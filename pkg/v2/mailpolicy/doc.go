@@ -0,0 +1,29 @@
+/*
+Package mailpolicy builds, parses and publishes mail policy records kept in
+TXT rrsets of the Selectel Domains API V2: DMARC records under _dmarc,
+MTA-STS records under _mta-sts and TLS-RPT records under _smtp._tls.
+
+Records are validated before publishing: tags must have allowed values and
+report URIs must be mailto: (or https: for TLS-RPT) URIs. Publish replaces
+the record of the same kind in the TXT rrset of a domain and keeps other TXT
+records, Rollout does the same in every zone of the account.
+
+Example of moving all zones to a reject DMARC policy
+
+  record, err := mailpolicy.ParseDMARC("v=DMARC1; p=reject; rua=mailto:dmarc@example.com")
+  if err != nil {
+    log.Fatal(err)
+  }
+  report, err := mailpolicy.Rollout(ctx, client, record, nil)
+  if err != nil {
+    log.Printf("%d zones failed: %v", len(report.Failed()), err)
+  }
+
+Example of announcing a new MTA-STS policy
+
+  record := &mailpolicy.MTASTS{ID: mailpolicy.NewPolicyID(time.Now())}
+  if _, err := mailpolicy.Publish(ctx, client, zone.ID, zone.Name, 300, record); err != nil {
+    log.Fatal(err)
+  }
+*/
+package mailpolicy
@@ -0,0 +1,96 @@
+package mailpolicy
+
+import (
+	"fmt"
+	"time"
+
+	v2 "github.com/selectel/domains-go/pkg/v2"
+)
+
+const (
+	// MTASTSVersion represents the version tag of MTA-STS records.
+	MTASTSVersion = "STSv1"
+
+	// MTASTSLabel represents the label MTA-STS records are published under.
+	MTASTSLabel = "_mta-sts"
+
+	// maxPolicyIDLength represents the maximum length of an MTA-STS policy id.
+	maxPolicyIDLength = 32
+
+	// policyIDLayout represents the layout of policy ids made by NewPolicyID.
+	policyIDLayout = "20060102150405"
+)
+
+// MTASTS represents an MTA-STS record of RFC 8461. The policy itself is served over HTTPS,
+// the record announces it and its id has to change every time the policy changes.
+type MTASTS struct {
+	ID string
+}
+
+// MTASTSName returns the name the MTA-STS record of the domain is published under.
+func MTASTSName(domain string) string {
+	return recordName(MTASTSLabel, domain)
+}
+
+// NewPolicyID returns a policy id made of the time, as commonly used by MTA-STS deployments.
+func NewPolicyID(t time.Time) string {
+	return t.UTC().Format(policyIDLayout)
+}
+
+// IsMTASTS reports whether the TXT data is an MTA-STS record.
+func IsMTASTS(data string) bool {
+	return hasVersion(data, MTASTSVersion)
+}
+
+// ParseMTASTS parses and validates an MTA-STS record. Quoted TXT content, as stored in rrsets, is accepted too.
+func ParseMTASTS(data string) (*MTASTS, error) {
+	tags, err := parseTags(data, MTASTSVersion)
+	if err != nil {
+		return nil, err
+	}
+	record := &MTASTS{ID: ""}
+	for _, t := range tags {
+		if t.name == "id" {
+			record.ID = t.value
+		}
+	}
+	if err := record.Validate(); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Validate checks the policy id of the record.
+func (r *MTASTS) Validate() error {
+	if r.ID == "" || len(r.ID) > maxPolicyIDLength {
+		return fmt.Errorf("%w: id must have 1 to %d characters", ErrInvalidRecord, maxPolicyIDLength)
+	}
+	for _, c := range r.ID {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			return fmt.Errorf("%w: id=%s is not alphanumeric", ErrInvalidRecord, r.ID)
+		}
+	}
+
+	return nil
+}
+
+// Label returns the label the record is published under.
+func (r *MTASTS) Label() string {
+	return MTASTSLabel
+}
+
+// Matches reports whether the TXT data is an MTA-STS record.
+func (r *MTASTS) Matches(data string) bool {
+	return IsMTASTS(data)
+}
+
+// String returns the record in the MTA-STS tag-value syntax.
+func (r *MTASTS) String() string {
+	return formatTags(MTASTSVersion, []tag{{name: "id", value: r.ID}})
+}
+
+// TXT returns the record as TXT content of an rrset.
+func (r *MTASTS) TXT() string {
+	return v2.EncodeTXT(r.String())
+}
@@ -0,0 +1,192 @@
+package mailpolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/rrconv"
+)
+
+// defaultTTL represents the default TTL of rrsets created for policy records.
+const defaultTTL = 3600
+
+var (
+	ErrInvalidRecord = errors.New("invalid policy record")
+	ErrInvalidURI    = errors.New("invalid report URI")
+)
+
+type (
+	// Record represents a policy record kept in a TXT rrset: *DMARC, *MTASTS or *TLSRPT.
+	Record interface {
+		// Label returns the label the record is published under.
+		Label() string
+		// Matches reports whether the TXT data is a record of the same kind.
+		Matches(data string) bool
+		Validate() error
+		String() string
+		TXT() string
+	}
+
+	// RolloutOpts represents options of a rollout.
+	RolloutOpts struct {
+		// TTL of published rrsets. If zero, existing rrsets keep their TTL
+		// and created rrsets get defaultTTL.
+		TTL int
+
+		// Filter selects zones to update. If nil, all zones are updated.
+		Filter func(zone *v2.Zone) bool
+	}
+
+	// ZoneResult describes the outcome of a rollout in a single zone.
+	ZoneResult struct {
+		Zone  *v2.Zone
+		RRSet *v2.RRSet
+		// Changed reports whether the rrset was created or updated,
+		// it is false when the zone already had the record.
+		Changed bool
+		Err     error
+	}
+
+	// RolloutReport holds results of a rollout in the order zones were listed.
+	RolloutReport struct {
+		Results []ZoneResult
+	}
+)
+
+// Changed returns the number of zones whose rrsets were created or updated.
+func (r *RolloutReport) Changed() int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Changed {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Failed returns results of zones that could not be updated.
+func (r *RolloutReport) Failed() []ZoneResult {
+	var failed []ZoneResult
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+
+	return failed
+}
+
+// Publish sets the policy record of the domain, replacing records of the same kind
+// in its TXT rrset and keeping other TXT records. If ttl is zero, the TTL of the existing rrset is kept.
+func Publish(
+	ctx context.Context, manager v2.RRSetManager[v2.RRSet], zoneID, domain string, ttl int, record Record,
+) (*v2.RRSet, error) {
+	if err := record.Validate(); err != nil {
+		return nil, err
+	}
+	rrset, _, err := publish(ctx, manager, zoneID, domain, ttl, record)
+
+	return rrset, err
+}
+
+// Rollout publishes the policy record in every zone returned by ListZones, so a policy change
+// such as moving DMARC from quarantine to reject is a single operation.
+// The report is always returned, the error joins errors of all failed zones.
+func Rollout(
+	ctx context.Context, manager v2.DNSManager[v2.Zone, v2.RRSet], record Record, opts *RolloutOpts,
+) (*RolloutReport, error) {
+	if opts == nil {
+		opts = &RolloutOpts{TTL: 0, Filter: nil}
+	}
+	if err := record.Validate(); err != nil {
+		return nil, err
+	}
+	zones, err := v2.ListAllZones[v2.Zone](ctx, manager, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list zones: %w", err)
+	}
+	report := &RolloutReport{Results: nil}
+	var errs []error
+	for _, zone := range zones {
+		if opts.Filter != nil && !opts.Filter(zone) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+
+			break
+		}
+		rrset, changed, err := publish(ctx, manager, zone.ID, zone.Name, opts.TTL, record)
+		report.Results = append(report.Results, ZoneResult{Zone: zone, RRSet: rrset, Changed: changed, Err: err})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("zone %s: %w", zone.Name, err))
+		}
+	}
+
+	return report, errors.Join(errs...)
+}
+
+// publish upserts the record and reports whether the rrset was changed.
+func publish(
+	ctx context.Context, manager v2.RRSetManager[v2.RRSet], zoneID, domain string, ttl int, record Record,
+) (*v2.RRSet, bool, error) {
+	name := recordName(record.Label(), domain)
+	content := record.TXT()
+	//nolint: exhaustruct
+	rrset := &v2.RRSet{Name: name, Type: v2.TXT, TTL: ttl}
+	existing, err := v2.FindRRSet(ctx, manager, zoneID, name, v2.TXT)
+	switch {
+	case err == nil:
+		if upToDate(existing, record, content, ttl) {
+			return existing, false, nil
+		}
+		rrset.Comment, rrset.ManagedBy = existing.Comment, existing.ManagedBy
+		if rrset.TTL == 0 {
+			rrset.TTL = existing.TTL
+		}
+		for _, item := range existing.Records {
+			if !record.Matches(item.Content) {
+				rrset.Records = append(rrset.Records, item)
+			}
+		}
+	case errors.Is(err, v2.ErrNotFound):
+		if rrset.TTL == 0 {
+			rrset.TTL = defaultTTL
+		}
+	default:
+		return nil, false, err
+	}
+	rrset.Records = append(rrset.Records, v2.RecordItem{Content: content, Disabled: false})
+	upserted, err := v2.UpsertFoundRRSet(ctx, manager, zoneID, existing, rrset)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return upserted, true, nil
+}
+
+// upToDate reports whether the rrset already holds the record as its only record of the kind.
+func upToDate(rrset *v2.RRSet, record Record, content string, ttl int) bool {
+	if ttl != 0 && rrset.TTL != ttl {
+		return false
+	}
+	found := 0
+	for _, item := range rrset.Records {
+		if !record.Matches(item.Content) {
+			continue
+		}
+		if item.Disabled || v2.TXTData(item.Content) != v2.TXTData(content) {
+			return false
+		}
+		found++
+	}
+
+	return found == 1
+}
+
+func recordName(label, domain string) string {
+	return rrconv.CanonicalName(label + "." + domain)
+}
@@ -0,0 +1,107 @@
+package mailpolicy
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+
+	v2 "github.com/selectel/domains-go/pkg/v2"
+)
+
+// tag represents a single tag=value pair of a tag-list record.
+type tag struct {
+	name  string
+	value string
+}
+
+// hasVersion reports whether the TXT data starts with the version tag.
+func hasVersion(data, version string) bool {
+	first, _, _ := strings.Cut(v2.TXTData(data), ";")
+	name, value, ok := strings.Cut(first, "=")
+
+	return ok && strings.TrimSpace(name) == "v" && strings.TrimSpace(value) == version
+}
+
+// parseTags parses a tag-list record whose first tag must be v=version.
+// Duplicate tags are rejected as all the records require.
+func parseTags(data, version string) ([]tag, error) {
+	data = v2.TXTData(data)
+	if !hasVersion(data, version) {
+		return nil, fmt.Errorf("%w: v=%s expected", ErrInvalidRecord, version)
+	}
+	var tags []tag
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(data, ";")[1:] {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: tag %q has no value", ErrInvalidRecord, part)
+		}
+		name, value = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(value)
+		if seen[name] {
+			return nil, fmt.Errorf("%w: duplicate tag %s", ErrInvalidRecord, name)
+		}
+		seen[name] = true
+		tags = append(tags, tag{name: name, value: value})
+	}
+
+	return tags, nil
+}
+
+// formatTags renders the tags as a tag-list record.
+func formatTags(version string, tags []tag) string {
+	parts := make([]string, 0, len(tags)+1)
+	parts = append(parts, "v="+version)
+	for _, t := range tags {
+		parts = append(parts, t.name+"="+t.value)
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// splitURIs splits a comma separated list of report URIs.
+func splitURIs(value string) []string {
+	var uris []string
+	for _, uri := range strings.Split(value, ",") {
+		if uri = strings.TrimSpace(uri); uri != "" {
+			uris = append(uris, uri)
+		}
+	}
+
+	return uris
+}
+
+// validateURI checks that the report URI has one of the schemes and a valid address.
+// A DMARC size limit suffix like "!10m" is allowed when sizeLimit is set.
+func validateURI(uri string, sizeLimit bool, schemes ...string) error {
+	if sizeLimit {
+		uri, _, _ = strings.Cut(uri, "!")
+	}
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidURI, err)
+	}
+	valid := false
+	for _, scheme := range schemes {
+		valid = valid || strings.EqualFold(parsed.Scheme, scheme)
+	}
+	if !valid {
+		return fmt.Errorf("%w: %s: scheme must be one of %s", ErrInvalidURI, uri, strings.Join(schemes, ", "))
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "mailto":
+		if _, err := mail.ParseAddress(parsed.Opaque); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalidURI, uri, err)
+		}
+	case "https":
+		if parsed.Host == "" {
+			return fmt.Errorf("%w: %s: host is missing", ErrInvalidURI, uri)
+		}
+	}
+
+	return nil
+}
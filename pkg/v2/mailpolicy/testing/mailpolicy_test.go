@@ -0,0 +1,182 @@
+package testing
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/selectel/domains-go/pkg/testutils"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/mailpolicy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testZoneName = "bonnie-test.com."
+
+func TestParseDMARC(t *testing.T) {
+	t.Parallel()
+	record, err := mailpolicy.ParseDMARC(
+		`"v=DMARC1; p=quarantine; sp=reject; pct=50; rua=mailto:dmarc@bonnie-test.com!10m,mailto:a@b.test; ` +
+			`adkim=s; fo=1:d; x=ignored"`)
+
+	require.NoError(t, err)
+	assert.Equal(t, mailpolicy.PolicyQuarantine, record.Policy)
+	assert.Equal(t, mailpolicy.PolicyReject, record.SubdomainPolicy)
+	assert.Equal(t, testutils.IntPtr(50), record.Percent)
+	assert.Equal(t, []string{"mailto:dmarc@bonnie-test.com!10m", "mailto:a@b.test"}, record.AggregateURIs)
+	assert.Equal(t, mailpolicy.AlignmentStrict, record.DKIMAlignment)
+	assert.Equal(t, "v=DMARC1; p=quarantine; sp=reject; pct=50; "+
+		"rua=mailto:dmarc@bonnie-test.com!10m,mailto:a@b.test; adkim=s; fo=1:d", record.String())
+}
+
+func TestParseDMARC_errors(t *testing.T) {
+	t.Parallel()
+	for _, data := range []string{
+		"v=DMARC2; p=none",
+		"v=DMARC1",
+		"v=DMARC1; p=block",
+		"v=DMARC1; rua=mailto:a@b.test; p=none",
+		"v=DMARC1; p=none; pct=101",
+		"v=DMARC1; p=none; adkim=x",
+		"v=DMARC1; p=none; fo=2",
+		"v=DMARC1; p=none; p=reject",
+	} {
+		_, err := mailpolicy.ParseDMARC(data)
+		assert.ErrorIs(t, err, mailpolicy.ErrInvalidRecord, data)
+	}
+	for _, data := range []string{
+		"v=DMARC1; p=none; rua=https://bonnie-test.com/report",
+		"v=DMARC1; p=none; ruf=mailto:not-an-address",
+	} {
+		_, err := mailpolicy.ParseDMARC(data)
+		assert.ErrorIs(t, err, mailpolicy.ErrInvalidURI, data)
+	}
+}
+
+func TestMTASTS(t *testing.T) {
+	t.Parallel()
+	record := &mailpolicy.MTASTS{ID: mailpolicy.NewPolicyID(time.Date(2026, 10, 1, 8, 57, 0, 0, time.UTC))}
+
+	assert.Equal(t, `"v=STSv1; id=20261001085700"`, record.TXT())
+	parsed, err := mailpolicy.ParseMTASTS(record.TXT())
+	require.NoError(t, err)
+	assert.Equal(t, record, parsed)
+	assert.Equal(t, "_mta-sts."+testZoneName, mailpolicy.MTASTSName(testZoneName))
+
+	for _, data := range []string{"v=STSv1;", "v=STSv1; id=2026-10-01", "v=STSv1; id=" + strings.Repeat("a", 33)} {
+		_, err := mailpolicy.ParseMTASTS(data)
+		assert.ErrorIs(t, err, mailpolicy.ErrInvalidRecord, data)
+	}
+}
+
+func TestTLSRPT(t *testing.T) {
+	t.Parallel()
+	record, err := mailpolicy.ParseTLSRPT("v=TLSRPTv1; rua=mailto:tls@bonnie-test.com, https://report.bonnie-test.com/v1")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mailto:tls@bonnie-test.com", "https://report.bonnie-test.com/v1"}, record.ReportURIs)
+	assert.Equal(t, "_smtp._tls."+testZoneName, mailpolicy.TLSRPTName(testZoneName))
+
+	_, err = mailpolicy.ParseTLSRPT("v=TLSRPTv1")
+	require.ErrorIs(t, err, mailpolicy.ErrInvalidRecord)
+	_, err = mailpolicy.ParseTLSRPT("v=TLSRPTv1; rua=ftp://bonnie-test.com")
+	assert.ErrorIs(t, err, mailpolicy.ErrInvalidURI)
+}
+
+func TestPublish(t *testing.T) {
+	t.Parallel()
+	api := testutils.NewFakeAPI()
+	defer api.Close()
+	zone := api.AddZone(testZoneName)
+	//nolint: exhaustruct
+	api.AddRRSet(zone.ID, v2.RRSet{
+		Name: "_dmarc." + testZoneName,
+		Type: v2.TXT,
+		TTL:  60,
+		Records: []v2.RecordItem{
+			{Content: `"v=DMARC1; p=none"`, Disabled: false},
+			{Content: `"verification"`, Disabled: false},
+		},
+	})
+	//nolint: exhaustruct
+	record := &mailpolicy.DMARC{Policy: mailpolicy.PolicyReject}
+
+	rrset, err := mailpolicy.Publish(context.Background(), api.Client(), zone.ID, testZoneName, 0, record)
+
+	require.NoError(t, err)
+	assert.Equal(t, 60, rrset.TTL)
+	assert.Equal(t, []string{
+		"GET /zones/" + zone.ID + "/rrset",
+		"PATCH /zones/" + zone.ID + "/rrset/" + rrset.ID,
+	}, api.Requests())
+	rrsets := api.RRSets(zone.ID)
+	require.Len(t, rrsets, 1)
+	assert.Equal(t, []v2.RecordItem{
+		{Content: `"verification"`, Disabled: false},
+		{Content: `"v=DMARC1; p=reject"`, Disabled: false},
+	}, rrsets[0].Records)
+
+	//nolint: exhaustruct
+	_, err = mailpolicy.Publish(context.Background(), api.Client(), zone.ID, testZoneName, 0, &mailpolicy.DMARC{})
+	assert.ErrorIs(t, err, mailpolicy.ErrInvalidRecord)
+}
+
+func TestRollout(t *testing.T) {
+	t.Parallel()
+	api := testutils.NewFakeAPI()
+	defer api.Close()
+	first := api.AddZone(testZoneName)
+	second := api.AddZone("bonnie-test.net.")
+	skipped := api.AddZone("skipped.test.")
+	record := &mailpolicy.TLSRPT{ReportURIs: []string{"mailto:tls@bonnie-test.com"}}
+	//nolint: exhaustruct
+	api.AddRRSet(second.ID, v2.RRSet{
+		Name:    "_smtp._tls.bonnie-test.net.",
+		Type:    v2.TXT,
+		TTL:     300,
+		Records: []v2.RecordItem{{Content: record.TXT(), Disabled: false}},
+	})
+	//nolint: exhaustruct
+	opts := &mailpolicy.RolloutOpts{Filter: func(zone *v2.Zone) bool { return zone.ID != skipped.ID }}
+
+	report, err := mailpolicy.Rollout(context.Background(), api.Client(), record, opts)
+
+	require.NoError(t, err)
+	require.Len(t, report.Results, 2)
+	assert.Equal(t, 1, report.Changed())
+	assert.True(t, report.Results[0].Changed)
+	assert.False(t, report.Results[1].Changed)
+	rrsets := api.RRSets(first.ID)
+	require.Len(t, rrsets, 1)
+	assert.Equal(t, "_smtp._tls."+testZoneName, rrsets[0].Name)
+	assert.Equal(t, 3600, rrsets[0].TTL)
+	assert.Empty(t, api.RRSets(skipped.ID))
+}
+
+func TestRollout_failedZone(t *testing.T) {
+	t.Parallel()
+	api := testutils.NewFakeAPI()
+	defer api.Close()
+	api.AddZone(testZoneName)
+	failing := api.AddZone("bonnie-test.net.")
+	api.InjectFault(func(r *http.Request) int {
+		if r.Method == http.MethodPost && strings.Contains(r.URL.Path, failing.ID) {
+			return http.StatusInternalServerError
+		}
+
+		return 0
+	})
+	//nolint: exhaustruct
+	record := &mailpolicy.DMARC{Policy: mailpolicy.PolicyNone}
+
+	report, err := mailpolicy.Rollout(context.Background(), api.Client(), record, nil)
+
+	require.Error(t, err)
+	assert.Equal(t, 1, report.Changed())
+	failed := report.Failed()
+	require.Len(t, failed, 1)
+	assert.Equal(t, failing.ID, failed[0].Zone.ID)
+}
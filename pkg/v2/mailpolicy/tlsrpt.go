@@ -0,0 +1,85 @@
+package mailpolicy
+
+import (
+	"fmt"
+	"strings"
+
+	v2 "github.com/selectel/domains-go/pkg/v2"
+)
+
+const (
+	// TLSRPTVersion represents the version tag of TLS-RPT records.
+	TLSRPTVersion = "TLSRPTv1"
+
+	// TLSRPTLabel represents the label TLS-RPT records are published under.
+	TLSRPTLabel = "_smtp._tls"
+)
+
+// TLSRPT represents a TLS-RPT record of RFC 8460.
+type TLSRPT struct {
+	// ReportURIs are mailto: or https: URIs of the rua= tag.
+	ReportURIs []string
+}
+
+// TLSRPTName returns the name the TLS-RPT record of the domain is published under.
+func TLSRPTName(domain string) string {
+	return recordName(TLSRPTLabel, domain)
+}
+
+// IsTLSRPT reports whether the TXT data is a TLS-RPT record.
+func IsTLSRPT(data string) bool {
+	return hasVersion(data, TLSRPTVersion)
+}
+
+// ParseTLSRPT parses and validates a TLS-RPT record. Quoted TXT content, as stored in rrsets, is accepted too.
+func ParseTLSRPT(data string) (*TLSRPT, error) {
+	tags, err := parseTags(data, TLSRPTVersion)
+	if err != nil {
+		return nil, err
+	}
+	record := &TLSRPT{ReportURIs: nil}
+	for _, t := range tags {
+		if t.name == "rua" {
+			record.ReportURIs = splitURIs(t.value)
+		}
+	}
+	if err := record.Validate(); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Validate checks report URIs of the record.
+func (r *TLSRPT) Validate() error {
+	if len(r.ReportURIs) == 0 {
+		return fmt.Errorf("%w: rua= tag is missing", ErrInvalidRecord)
+	}
+	for _, uri := range r.ReportURIs {
+		if err := validateURI(uri, false, "mailto", "https"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Label returns the label the record is published under.
+func (r *TLSRPT) Label() string {
+	return TLSRPTLabel
+}
+
+// Matches reports whether the TXT data is a TLS-RPT record.
+func (r *TLSRPT) Matches(data string) bool {
+	return IsTLSRPT(data)
+}
+
+// String returns the record in the TLS-RPT tag-value syntax.
+func (r *TLSRPT) String() string {
+	return formatTags(TLSRPTVersion, []tag{{name: "rua", value: strings.Join(r.ReportURIs, ",")}})
+}
+
+// TXT returns the record as TXT content of an rrset, split into quoted strings of at most 255 bytes.
+func (r *TLSRPT) TXT() string {
+	return v2.EncodeTXT(r.String())
+}
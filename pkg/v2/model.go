@@ -1,7 +1,7 @@
 package v2
 
 type (
-	List[T Zone | RRSet] struct {
+	List[T any] struct {
 		Count      int  `json:"count"`
 		NextOffset int  `json:"next_offset"`
 		Items      []*T `json:"result"` //nolint: tagliatelle
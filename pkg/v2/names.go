@@ -0,0 +1,151 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/selectel/domains-go/pkg/idn"
+)
+
+// apexName represents the relative name of the zone apex.
+const apexName = "@"
+
+// zoneNames caches zone names by zone ids for clients qualifying relative names.
+type zoneNames struct {
+	mu    sync.Mutex
+	names map[string]string
+}
+
+// NormalizeName returns the canonical form of an absolute name: lower-cased, in ASCII
+// and with a single trailing dot, e.g. "WWW.Example.com" becomes "www.example.com.".
+// Names that can't be converted to ASCII are only lower-cased.
+func NormalizeName(name string) string {
+	name = strings.TrimRight(name, ".")
+	if ascii, err := idn.ToASCII(name); err == nil {
+		name = ascii
+	}
+
+	return strings.ToLower(name) + "."
+}
+
+// Contains tells whether the absolute name is the zone apex or a name below it.
+// The trailing dot of the name is optional.
+func (z *Zone) Contains(name string) bool {
+	name, zoneName := NormalizeName(name), NormalizeName(z.Name)
+
+	return name == zoneName || strings.HasSuffix(name, "."+zoneName)
+}
+
+// FQDN returns the normalized absolute form of the name in the zone.
+// "@" and "" stand for the zone apex, names with a trailing dot and names ending
+// with the zone name are absolute, other names are relative to the zone:
+//
+//	www                 -> www.example.com.
+//	www.example.com     -> www.example.com.
+//	www.example.org.    -> www.example.org.
+func (z *Zone) FQDN(name string) string {
+	switch {
+	case name == "" || name == apexName:
+		return NormalizeName(z.Name)
+	case strings.HasSuffix(name, ".") || z.Contains(name):
+		return NormalizeName(name)
+	default:
+		return NormalizeName(name + "." + strings.TrimSuffix(z.Name, "."))
+	}
+}
+
+// RelativeName returns the name relative to the zone, "@" for the zone apex.
+// The name is treated as absolute, it fails with ErrNameOutsideZone when the name isn't in the zone.
+func (z *Zone) RelativeName(name string) (string, error) {
+	if !z.Contains(name) {
+		return "", fmt.Errorf("%w: %s not in %s", ErrNameOutsideZone, name, z.Name)
+	}
+	name, zoneName := NormalizeName(name), NormalizeName(z.Name)
+	if name == zoneName {
+		return apexName, nil
+	}
+
+	return strings.TrimSuffix(name, "."+zoneName), nil
+}
+
+// WithRelativeNames returns reference to a copy of the initial client that qualifies
+// relative names of rrsets passed as *RRSet to CreateRRSet and of the "name" filter
+// of ListRRSets with the zone name, see Zone.FQDN. Zone names are fetched once per zone.
+func (c *Client) WithRelativeNames() DNSClient[Zone, RRSet] {
+	temporaryClient := *c
+	//nolint: exhaustruct
+	temporaryClient.zoneNames = &zoneNames{names: map[string]string{}}
+
+	return &temporaryClient
+}
+
+// WithRelativeNames returns reference to a copy of the initial client that qualifies
+// relative names, see Client.WithRelativeNames.
+func (t *TypedClient[Z, S]) WithRelativeNames() DNSClient[Z, S] {
+	client, _ := t.client.WithRelativeNames().(*Client)
+
+	return &TypedClient[Z, S]{client: client}
+}
+
+// qualifyRRSet returns a copy of the rrset with its name qualified when the client qualifies relative names.
+func (c *Client) qualifyRRSet(ctx context.Context, zoneID string, rrset Creatable) (Creatable, error) {
+	s, ok := rrset.(*RRSet)
+	if c.zoneNames == nil || !ok {
+		return rrset, nil
+	}
+	zone, err := c.zone(ctx, zoneID)
+	if err != nil {
+		return nil, err
+	}
+	qualified := *s
+	qualified.Name = zone.FQDN(s.Name)
+
+	return &qualified, nil
+}
+
+// qualifyOptions returns a copy of options with the "name" filter qualified
+// when the client qualifies relative names.
+func (c *Client) qualifyOptions(
+	ctx context.Context, zoneID string, options *map[string]string,
+) (*map[string]string, error) {
+	if c.zoneNames == nil || options == nil {
+		return options, nil
+	}
+	name, ok := (*options)["name"]
+	if !ok {
+		return options, nil
+	}
+	zone, err := c.zone(ctx, zoneID)
+	if err != nil {
+		return nil, err
+	}
+	qualified := make(map[string]string, len(*options))
+	for key, value := range *options {
+		qualified[key] = value
+	}
+	qualified["name"] = zone.FQDN(name)
+
+	return &qualified, nil
+}
+
+// zone returns the zone with the cached name.
+func (c *Client) zone(ctx context.Context, zoneID string) (*Zone, error) {
+	c.zoneNames.mu.Lock()
+	name, ok := c.zoneNames.names[zoneID]
+	c.zoneNames.mu.Unlock()
+	if !ok {
+		zone, err := getZone[Zone](ctx, c, zoneID)
+		if err != nil {
+			return nil, fmt.Errorf("get zone to qualify names: %w", err)
+		}
+		name = zone.Name
+		c.zoneNames.mu.Lock()
+		c.zoneNames.names[zoneID] = name
+		c.zoneNames.mu.Unlock()
+	}
+
+	//nolint: exhaustruct
+	return &Zone{ID: zoneID, Name: name}, nil
+}
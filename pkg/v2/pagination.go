@@ -0,0 +1,53 @@
+package v2
+
+import (
+	"context"
+	"strconv"
+)
+
+const offsetParam = "offset"
+
+// ListAllZones returns every zone matched by options, following pagination.
+func ListAllZones[Z any](ctx context.Context, manager ZoneManager[Z], options *map[string]string) ([]*Z, error) {
+	return listAll(func(params *map[string]string) (Listable[Z], error) {
+		return manager.ListZones(ctx, params)
+	}, options)
+}
+
+// ListAllRRSets returns every rrset of the zone matched by options, following pagination.
+func ListAllRRSets[S any](
+	ctx context.Context, manager RRSetManager[S], zoneID string, options *map[string]string,
+) ([]*S, error) {
+	return listAll(func(params *map[string]string) (Listable[S], error) {
+		return manager.ListRRSets(ctx, zoneID, params)
+	}, options)
+}
+
+func listAll[T any](list func(params *map[string]string) (Listable[T], error), options *map[string]string) ([]*T, error) {
+	params := make(map[string]string)
+	if options != nil {
+		for key, value := range *options {
+			params[key] = value
+		}
+	}
+	offset, _ := strconv.Atoi(params[offsetParam])
+
+	var items []*T
+	for {
+		page, err := list(&params)
+		if err != nil {
+			return nil, err
+		}
+		if page == nil {
+			return items, nil
+		}
+		items = append(items, page.GetItems()...)
+
+		nextOffset := page.GetNextOffset()
+		if nextOffset <= offset || len(page.GetItems()) == 0 {
+			return items, nil
+		}
+		offset = nextOffset
+		params[offsetParam] = strconv.Itoa(offset)
+	}
+}
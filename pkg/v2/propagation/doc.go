@@ -0,0 +1,26 @@
+/*
+Package propagation waits until changes made through the Selectel Domains API
+V2 are served by authoritative nameservers.
+
+WaitForRRSet polls every nameserver until it answers with exactly the enabled
+records and the TTL of the rrset, WaitForRRSetDeletion polls until the rrset
+is gone. Both return a report with the status of every nameserver, so servers
+that haven't caught up before the timeout can be told apart from the others.
+
+Example of waiting for a new rrset to be served
+
+  rrset, err := client.CreateRRSet(ctx, zone.ID, newRRSet)
+  if err != nil {
+    log.Fatal(err)
+  }
+  report, err := propagation.WaitForRRSet(ctx, zone, rrset, &propagation.WaitOpts{
+    Interval: 5 * time.Second,
+    Timeout:  10 * time.Minute,
+  })
+  if err != nil {
+    for _, server := range report.Servers {
+      fmt.Println(server.Server, server.Synced, server.Err)
+    }
+  }
+*/
+package propagation
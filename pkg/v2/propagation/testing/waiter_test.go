@@ -0,0 +1,176 @@
+package testing
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/propagation"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testZoneName  = "bonnie-test.com."
+	testRRSetName = "www.bonnie-test.com."
+)
+
+type (
+	WaiterSuite struct {
+		suite.Suite
+		servers  []*dns.Server
+		handlers []*fakeNameserver
+		addrs    []string
+		zone     *v2.Zone
+		rrset    *v2.RRSet
+		opts     *propagation.WaitOpts
+	}
+
+	// fakeNameserver answers authoritatively with the records it currently holds.
+	fakeNameserver struct {
+		mu  sync.Mutex
+		rrs []dns.RR
+	}
+)
+
+//nolint:paralleltest
+func TestWaiter(t *testing.T) {
+	suite.Run(t, new(WaiterSuite))
+}
+
+func (s *WaiterSuite) SetupTest() {
+	s.servers, s.handlers, s.addrs = nil, nil, nil
+	for i := 0; i < 2; i++ {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		s.Require().NoError(err)
+		//nolint: exhaustruct
+		handler := &fakeNameserver{}
+		//nolint: exhaustruct
+		server := &dns.Server{PacketConn: conn, Handler: handler}
+		started := make(chan struct{})
+		server.NotifyStartedFunc = func() { close(started) }
+		go func() {
+			_ = server.ActivateAndServe()
+		}()
+		<-started
+		s.servers = append(s.servers, server)
+		s.handlers = append(s.handlers, handler)
+		s.addrs = append(s.addrs, conn.LocalAddr().String())
+	}
+
+	//nolint: exhaustruct
+	s.zone = &v2.Zone{Name: testZoneName, Nameservers: s.addrs}
+	//nolint: exhaustruct
+	s.rrset = &v2.RRSet{
+		Name: testRRSetName,
+		Type: v2.A,
+		TTL:  60,
+		Records: []v2.RecordItem{
+			{Content: "10.0.0.1", Disabled: false},
+			{Content: "10.0.0.2", Disabled: false},
+			{Content: "10.0.0.3", Disabled: true},
+		},
+	}
+	//nolint: exhaustruct
+	s.opts = &propagation.WaitOpts{Interval: 10 * time.Millisecond, Timeout: 2 * time.Second}
+}
+
+func (s *WaiterSuite) TearDownTest() {
+	for _, server := range s.servers {
+		_ = server.Shutdown()
+	}
+}
+
+func (s *WaiterSuite) TestWaitForRRSet() {
+	s.handlers[0].set(testRRSetName+" 60 IN A 10.0.0.2", testRRSetName+" 60 IN A 10.0.0.1")
+	s.handlers[1].set(testRRSetName + " 60 IN A 10.0.0.1")
+	time.AfterFunc(50*time.Millisecond, func() {
+		s.handlers[1].set(testRRSetName+" 60 IN A 10.0.0.1", testRRSetName+" 60 IN A 10.0.0.2")
+	})
+
+	report, err := propagation.WaitForRRSet(context.Background(), s.zone, s.rrset, s.opts)
+
+	s.Require().NoError(err)
+	s.True(report.Synced())
+	s.Require().Len(report.Servers, 2)
+	s.Equal(s.addrs[0], report.Servers[0].Server)
+	s.Equal(1, report.Servers[0].Attempts)
+	s.Greater(report.Servers[1].Attempts, 1)
+	s.NoError(report.Servers[1].Err)
+	s.False(report.Servers[1].SyncedAt.IsZero())
+}
+
+func (s *WaiterSuite) TestWaitForRRSet_timeout() {
+	s.handlers[0].set(testRRSetName+" 60 IN A 10.0.0.1", testRRSetName+" 60 IN A 10.0.0.2")
+	s.handlers[1].set(testRRSetName+" 300 IN A 10.0.0.1", testRRSetName+" 300 IN A 10.0.0.2")
+	s.opts.Timeout = 100 * time.Millisecond
+
+	report, err := propagation.WaitForRRSet(context.Background(), s.zone, s.rrset, s.opts)
+
+	s.ErrorIs(err, propagation.ErrTimeout)
+	s.Require().NotNil(report)
+	s.False(report.Synced())
+	s.True(report.Servers[0].Synced)
+	s.False(report.Servers[1].Synced)
+	s.ErrorIs(report.Servers[1].Err, propagation.ErrMismatch)
+}
+
+func (s *WaiterSuite) TestWaitForRRSetDeletion() {
+	s.handlers[0].set()
+	s.handlers[1].set(testRRSetName + " 60 IN A 10.0.0.1")
+	time.AfterFunc(50*time.Millisecond, func() {
+		s.handlers[1].set()
+	})
+
+	report, err := propagation.WaitForRRSetDeletion(context.Background(), s.zone, s.rrset, s.opts)
+
+	s.Require().NoError(err)
+	s.True(report.Synced())
+}
+
+func (s *WaiterSuite) TestWaitForRRSet_custom_nameservers() {
+	s.handlers[1].set(testRRSetName+" 60 IN A 10.0.0.1", testRRSetName+" 60 IN A 10.0.0.2")
+	s.opts.Nameservers = s.addrs[1:]
+
+	report, err := propagation.WaitForRRSet(context.Background(), s.zone, s.rrset, s.opts)
+
+	s.Require().NoError(err)
+	s.Require().Len(report.Servers, 1)
+	s.Equal(s.addrs[1], report.Servers[0].Server)
+}
+
+func (f *fakeNameserver) set(records ...string) {
+	rrs := make([]dns.RR, 0, len(records))
+	for _, record := range records {
+		rr, err := dns.NewRR(record)
+		if err != nil {
+			panic(err)
+		}
+		rrs = append(rrs, rr)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rrs = rrs
+}
+
+func (f *fakeNameserver) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	resp := new(dns.Msg)
+	resp.SetReply(r)
+	resp.Authoritative = true
+	question := r.Question[0]
+	for _, rr := range f.rrs {
+		if rr.Header().Rrtype == question.Qtype && dns.CanonicalName(rr.Header().Name) == question.Name {
+			resp.Answer = append(resp.Answer, rr)
+		}
+	}
+	if len(resp.Answer) == 0 {
+		resp.Rcode = dns.RcodeNameError
+	}
+	_ = w.WriteMsg(resp)
+}
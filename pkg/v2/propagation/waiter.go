@@ -0,0 +1,267 @@
+package propagation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/rrconv"
+)
+
+const (
+	// defaultInterval represents the default interval between polls of a nameserver.
+	defaultInterval = 2 * time.Second
+
+	// defaultTimeout represents the default time to wait for all nameservers.
+	defaultTimeout = 5 * time.Minute
+
+	// defaultQueryTimeout represents the default timeout of a single DNS query.
+	defaultQueryTimeout = 5 * time.Second
+
+	// defaultPort represents the port used for nameservers given without one.
+	defaultPort = "53"
+)
+
+var (
+	ErrNoNameservers = errors.New("no nameservers to query")
+	ErrTimeout       = errors.New("change is not served by all nameservers")
+	ErrBadRcode      = errors.New("unexpected response code")
+	ErrMismatch      = errors.New("answer doesn't match the rrset")
+)
+
+type (
+	// WaitOpts represents options of waiting.
+	WaitOpts struct {
+		// Nameservers lists host or host:port addresses of authoritative nameservers to query.
+		// If empty, nameservers of the zone are used.
+		Nameservers []string
+
+		// Interval represents the time between polls of a nameserver. If zero, defaultInterval is used.
+		Interval time.Duration
+
+		// Timeout limits the whole wait. If zero, defaultTimeout is used.
+		Timeout time.Duration
+
+		// QueryTimeout limits a single DNS query. If zero, defaultQueryTimeout is used.
+		QueryTimeout time.Duration
+	}
+
+	// Report describes the state of every nameserver at the end of a wait.
+	Report struct {
+		Servers []ServerStatus
+	}
+
+	// ServerStatus describes a single nameserver.
+	ServerStatus struct {
+		Server string
+		// Synced reports whether the server answers with the desired records.
+		Synced   bool
+		SyncedAt time.Time
+		Attempts int
+		// Err holds the error of the last query or the reason the answer didn't match.
+		Err error
+	}
+
+	// desiredState describes the answer expected from nameservers.
+	desiredState struct {
+		name   string
+		rrType uint16
+		ttl    uint32
+		rrs    []dns.RR
+	}
+)
+
+// Synced reports whether all nameservers serve the change.
+func (r *Report) Synced() bool {
+	for _, server := range r.Servers {
+		if !server.Synced {
+			return false
+		}
+	}
+
+	return true
+}
+
+// WaitForRRSet waits until every nameserver answers with the enabled records of the rrset and its TTL.
+// If the timeout is reached, the report is returned together with an ErrTimeout error.
+func WaitForRRSet(ctx context.Context, zone *v2.Zone, rrset *v2.RRSet, opts *WaitOpts) (*Report, error) {
+	rrType, err := rrconv.RRType(rrset.Type)
+	if err != nil {
+		return nil, err
+	}
+	rrs, err := rrconv.ToRRs(rrset)
+	if err != nil {
+		return nil, err
+	}
+	desired := &desiredState{
+		name:   rrconv.CanonicalName(rrset.Name),
+		rrType: rrType,
+		ttl:    uint32(rrset.TTL),
+		rrs:    rrs,
+	}
+
+	return wait(ctx, zone, desired, opts)
+}
+
+// WaitForRRSetDeletion waits until no nameserver answers with records of the rrset's name and type.
+// If the timeout is reached, the report is returned together with an ErrTimeout error.
+func WaitForRRSetDeletion(ctx context.Context, zone *v2.Zone, rrset *v2.RRSet, opts *WaitOpts) (*Report, error) {
+	rrType, err := rrconv.RRType(rrset.Type)
+	if err != nil {
+		return nil, err
+	}
+	desired := &desiredState{
+		name:   rrconv.CanonicalName(rrset.Name),
+		rrType: rrType,
+		ttl:    0,
+		rrs:    nil,
+	}
+
+	return wait(ctx, zone, desired, opts)
+}
+
+func wait(ctx context.Context, zone *v2.Zone, desired *desiredState, opts *WaitOpts) (*Report, error) {
+	if opts == nil {
+		//nolint: exhaustruct
+		opts = &WaitOpts{}
+	}
+	servers := opts.Nameservers
+	if len(servers) == 0 {
+		servers = zone.Nameservers
+	}
+	if len(servers) == 0 {
+		return nil, ErrNoNameservers
+	}
+	interval := valueOrDefault(opts.Interval, defaultInterval)
+	queryTimeout := valueOrDefault(opts.QueryTimeout, defaultQueryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, valueOrDefault(opts.Timeout, defaultTimeout))
+	defer cancel()
+
+	report := &Report{Servers: make([]ServerStatus, len(servers))}
+	for i, server := range servers {
+		//nolint: exhaustruct
+		report.Servers[i] = ServerStatus{Server: serverAddr(server)}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		var wg sync.WaitGroup
+		for i := range report.Servers {
+			status := &report.Servers[i]
+			if status.Synced {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				poll(ctx, status, desired, queryTimeout)
+			}()
+		}
+		wg.Wait()
+		if report.Synced() {
+			return report, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return report, fmt.Errorf("%w: %w", ErrTimeout, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll queries a single nameserver once and updates its status.
+func poll(ctx context.Context, status *ServerStatus, desired *desiredState, timeout time.Duration) {
+	status.Attempts++
+	msg := new(dns.Msg)
+	msg.SetQuestion(desired.name, desired.rrType)
+	msg.RecursionDesired = false
+
+	queryCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	//nolint: exhaustruct
+	client := &dns.Client{Timeout: timeout}
+	resp, _, err := client.ExchangeContext(queryCtx, msg, status.Server)
+	if err == nil && resp.Truncated {
+		client.Net = "tcp"
+		resp, _, err = client.ExchangeContext(queryCtx, msg, status.Server)
+	}
+	if err != nil {
+		// Keep the previous reason if the query was only interrupted by the end of the wait.
+		if expired(ctx) && status.Err != nil {
+			return
+		}
+		status.Err = fmt.Errorf("query %s: %w", status.Server, err)
+
+		return
+	}
+	status.Err = match(resp, desired)
+	if status.Err == nil {
+		status.Synced = true
+		status.SyncedAt = time.Now()
+	}
+}
+
+// match returns an error describing how the answer differs from the desired state.
+func match(resp *dns.Msg, desired *desiredState) error {
+	if resp.Rcode != dns.RcodeSuccess && !(resp.Rcode == dns.RcodeNameError && len(desired.rrs) == 0) {
+		return fmt.Errorf("%w: %s", ErrBadRcode, dns.RcodeToString[resp.Rcode])
+	}
+	var answer []dns.RR
+	for _, rr := range resp.Answer {
+		header := rr.Header()
+		if header.Rrtype == desired.rrType && rrconv.CanonicalName(header.Name) == desired.name {
+			answer = append(answer, rr)
+		}
+	}
+	if !rrconv.Equal(answer, desired.rrs) {
+		return fmt.Errorf("%w: served %q", ErrMismatch, contents(answer))
+	}
+	for _, rr := range answer {
+		if rr.Header().Ttl != desired.ttl {
+			return fmt.Errorf("%w: served TTL %d, want %d", ErrMismatch, rr.Header().Ttl, desired.ttl)
+		}
+	}
+
+	return nil
+}
+
+// expired reports whether the context is done or its deadline has passed.
+// Queries may fail on the deadline slightly before the context is marked as done.
+func expired(ctx context.Context) bool {
+	deadline, ok := ctx.Deadline()
+
+	return ctx.Err() != nil || (ok && !time.Now().Before(deadline))
+}
+
+func contents(rrs []dns.RR) []string {
+	result := make([]string, 0, len(rrs))
+	for _, rr := range rrs {
+		result = append(result, rrconv.Content(rr))
+	}
+
+	return result
+}
+
+func serverAddr(server string) string {
+	if _, _, err := net.SplitHostPort(server); err == nil {
+		return server
+	}
+
+	return net.JoinHostPort(strings.TrimSuffix(server, "."), defaultPort)
+}
+
+func valueOrDefault(value, defaultValue time.Duration) time.Duration {
+	if value == 0 {
+		return defaultValue
+	}
+
+	return value
+}
@@ -0,0 +1,68 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+type (
+	// Decoder decodes the body of a successful response.
+	Decoder[T any] func(body []byte) (*T, error)
+
+	// Requester builds and sends requests to the API.
+	// It is implemented by clients of this package, so they can be used to call endpoints
+	// the package doesn't cover with Do.
+	Requester interface {
+		NewRequest(
+			ctx context.Context, method, path string, body io.Reader, params *map[string]string,
+		) (*http.Request, error)
+		HTTPClient() *http.Client
+	}
+)
+
+//nolint:exhaustruct
+var _ Requester = &Client{}
+
+// DecodeJSON decodes a JSON body into a new value of T.
+func DecodeJSON[T any](body []byte) (*T, error) {
+	var result T
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Do sends a request to the path relative to the API URL and decodes a successful response with decode,
+// or as JSON if decode is nil. Error responses are handled as by the methods of Client:
+// ErrNotFound is returned for 404 and *BadResponseError for other errors.
+// An empty 204 response results in a nil value and a nil error.
+func Do[T any](
+	ctx context.Context,
+	requester Requester,
+	method, path string,
+	body io.Reader,
+	params *map[string]string,
+	decode Decoder[T],
+) (*T, error) {
+	if decode == nil {
+		decode = DecodeJSON[T]
+	}
+	request, err := requester.NewRequest(ctx, method, path, body, params)
+
+	return processRequestWithDecoder(requester.HTTPClient(), request, err, decode)
+}
+
+// NewRequest returns a request to the path relative to the API URL with the default headers of the client.
+func (c *Client) NewRequest(
+	ctx context.Context, method, path string, body io.Reader, params *map[string]string,
+) (*http.Request, error) {
+	return c.prepareRequest(ctx, method, path, body, params, nil)
+}
+
+// HTTPClient returns the HTTP client used to send requests.
+func (c *Client) HTTPClient() *http.Client {
+	return c.httpClient
+}
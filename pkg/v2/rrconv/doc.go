@@ -0,0 +1,18 @@
+/*
+Package rrconv converts rrsets of the Selectel Domains API V2 to DNS resource
+records and back.
+
+Record contents returned by the API use the zone file presentation format,
+so every enabled record of an rrset maps to a single dns.RR.
+
+Example of converting an rrset to resource records
+
+  rrs, err := rrconv.ToRRs(rrset)
+  if err != nil {
+    log.Fatal(err)
+  }
+  for _, rr := range rrs {
+    fmt.Println(rr)
+  }
+*/
+package rrconv
@@ -0,0 +1,151 @@
+package rrconv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+)
+
+var ErrUnsupportedType = errors.New("record type has no DNS representation")
+
+type (
+	// Key identifies an rrset by its canonical owner name and DNS type.
+	Key struct {
+		Name string
+		Type uint16
+	}
+)
+
+// KeyOf returns the key of the rrset rr belongs to.
+func KeyOf(rr dns.RR) Key {
+	return Key{Name: CanonicalName(rr.Header().Name), Type: rr.Header().Rrtype}
+}
+
+// String returns the key in "name TYPE" form.
+func (k Key) String() string {
+	return fmt.Sprintf("%s %s", k.Name, dns.TypeToString[k.Type])
+}
+
+// CanonicalName returns the lower-cased fully qualified form of name.
+func CanonicalName(name string) string {
+	return strings.ToLower(dns.Fqdn(name))
+}
+
+// RRType returns the DNS type code of the API record type.
+func RRType(recordType v2.RecordType) (uint16, error) {
+	if recordType == v2.ALIAS {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedType, recordType)
+	}
+	rrType, ok := dns.StringToType[string(recordType)]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedType, recordType)
+	}
+
+	return rrType, nil
+}
+
+// RecordType returns the API record type of the DNS type code.
+// The second value is false if the API doesn't support the type.
+func RecordType(rrType uint16) (v2.RecordType, bool) {
+	recordType := v2.RecordType(dns.TypeToString[rrType])
+	switch recordType {
+	case v2.A, v2.AAAA, v2.CAA, v2.CNAME, v2.MX, v2.NS, v2.SOA, v2.SRV, v2.SSHFP, v2.TXT:
+		return recordType, true
+	case v2.ALIAS:
+	}
+
+	return "", false
+}
+
+// ToRR converts a single record content into a resource record.
+func ToRR(name string, ttl int, recordType v2.RecordType, content string) (dns.RR, error) {
+	if _, err := RRType(recordType); err != nil {
+		return nil, err
+	}
+	rr, err := dns.NewRR(fmt.Sprintf("%s %d IN %s %s", dns.Fqdn(name), ttl, recordType, content))
+	if err != nil {
+		return nil, fmt.Errorf("parse %s record %q: %w", recordType, content, err)
+	}
+	if rr == nil {
+		return nil, fmt.Errorf("parse %s record %q: empty content", recordType, content)
+	}
+
+	return rr, nil
+}
+
+// ToRRs converts enabled records of the rrset into resource records.
+func ToRRs(rrset *v2.RRSet) ([]dns.RR, error) {
+	rrs := make([]dns.RR, 0, len(rrset.Records))
+	for _, record := range rrset.Records {
+		if record.Disabled {
+			continue
+		}
+		rr, err := ToRR(rrset.Name, rrset.TTL, rrset.Type, record.Content)
+		if err != nil {
+			return nil, err
+		}
+		rrs = append(rrs, rr)
+	}
+
+	return rrs, nil
+}
+
+// Content returns the rdata of rr in presentation format as the API expects it.
+func Content(rr dns.RR) string {
+	return strings.TrimPrefix(rr.String(), rr.Header().String())
+}
+
+// FromRRs groups resource records into rrsets by owner name and type.
+// The rrset TTL is taken from the first record of each group.
+func FromRRs(rrs []dns.RR) ([]*v2.RRSet, error) {
+	var rrsets []*v2.RRSet
+	index := make(map[Key]*v2.RRSet)
+	for _, rr := range rrs {
+		recordType, ok := RecordType(rr.Header().Rrtype)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, dns.TypeToString[rr.Header().Rrtype])
+		}
+		key := KeyOf(rr)
+		rrset, ok := index[key]
+		if !ok {
+			//nolint: exhaustruct
+			rrset = &v2.RRSet{
+				Name: key.Name,
+				TTL:  int(rr.Header().Ttl),
+				Type: recordType,
+			}
+			index[key] = rrset
+			rrsets = append(rrsets, rrset)
+		}
+		rrset.Records = append(rrset.Records, v2.RecordItem{Content: Content(rr), Disabled: false})
+	}
+
+	return rrsets, nil
+}
+
+// Equal reports whether a and b hold the same records regardless of order and TTL.
+func Equal(a, b []dns.RR) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	matched := make([]bool, len(b))
+	for _, rrA := range a {
+		found := false
+		for i, rrB := range b {
+			if !matched[i] && dns.IsDuplicate(rrA, rrB) {
+				matched[i] = true
+				found = true
+
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,79 @@
+package testing
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/rrconv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToRRs_skips_disabled_records(t *testing.T) {
+	t.Parallel()
+	//nolint: exhaustruct
+	rrset := &v2.RRSet{
+		Name: "mail.bonnie-test.com.",
+		Type: v2.MX,
+		TTL:  300,
+		Records: []v2.RecordItem{
+			{Content: "10 mx1.bonnie-test.com.", Disabled: false},
+			{Content: "20 mx2.bonnie-test.com.", Disabled: true},
+		},
+	}
+
+	rrs, err := rrconv.ToRRs(rrset)
+
+	require.NoError(t, err)
+	require.Len(t, rrs, 1)
+	mx, ok := rrs[0].(*dns.MX)
+	require.True(t, ok)
+	assert.Equal(t, uint16(10), mx.Preference)
+	assert.Equal(t, "mx1.bonnie-test.com.", mx.Mx)
+	assert.Equal(t, uint32(300), mx.Hdr.Ttl)
+}
+
+func TestToRR_errors(t *testing.T) {
+	t.Parallel()
+	_, err := rrconv.ToRR("alias.bonnie-test.com.", 60, v2.ALIAS, "origin.com.")
+	assert.ErrorIs(t, err, rrconv.ErrUnsupportedType)
+
+	_, err = rrconv.ToRR("www.bonnie-test.com.", 60, v2.A, "not-an-address")
+	assert.Error(t, err)
+}
+
+func TestFromRRs_groups_by_name_and_type(t *testing.T) {
+	t.Parallel()
+	rrs := make([]dns.RR, 0, 3)
+	for _, s := range []string{
+		`WWW.bonnie-test.com. 60 IN A 10.0.0.1`,
+		`www.bonnie-test.com. 60 IN A 10.0.0.2`,
+		`txt.bonnie-test.com. 120 IN TXT "hello world"`,
+	} {
+		rr, err := dns.NewRR(s)
+		require.NoError(t, err)
+		rrs = append(rrs, rr)
+	}
+
+	rrsets, err := rrconv.FromRRs(rrs)
+
+	require.NoError(t, err)
+	require.Len(t, rrsets, 2)
+	assert.Equal(t, "www.bonnie-test.com.", rrsets[0].Name)
+	assert.Equal(t, v2.A, rrsets[0].Type)
+	assert.Len(t, rrsets[0].Records, 2)
+	assert.Equal(t, `"hello world"`, rrsets[1].Records[0].Content)
+	assert.Equal(t, 120, rrsets[1].TTL)
+}
+
+func TestEqual_ignores_order_and_ttl(t *testing.T) {
+	t.Parallel()
+	a1, _ := dns.NewRR("www.bonnie-test.com. 60 IN A 10.0.0.1")
+	a2, _ := dns.NewRR("www.bonnie-test.com. 60 IN A 10.0.0.2")
+	a2Long, _ := dns.NewRR("www.bonnie-test.com. 3600 IN A 10.0.0.2")
+
+	assert.True(t, rrconv.Equal([]dns.RR{a1, a2}, []dns.RR{a2Long, a1}))
+	assert.False(t, rrconv.Equal([]dns.RR{a1, a1}, []dns.RR{a1, a2}))
+	assert.False(t, rrconv.Equal([]dns.RR{a1}, []dns.RR{a1, a2}))
+}
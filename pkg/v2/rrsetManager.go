@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+
+	"github.com/selectel/domains-go/pkg/idn"
 )
 
 // Types of domain name.
@@ -64,11 +66,22 @@ type (
 )
 
 func (s *RRSet) CreationForm() (io.Reader, error) {
+	name, err := idn.ToASCII(s.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTXTRecords(s.Type, s.Records); err != nil {
+		return nil, err
+	}
+	records, err := asciiRecords(s.Type, s.Records)
+	if err != nil {
+		return nil, err
+	}
 	form := rrsetCreationForm{
-		Name:      s.Name,
+		Name:      name,
 		TTL:       s.TTL,
 		Type:      s.Type,
-		Records:   s.Records,
+		Records:   records,
 		Comment:   s.Comment,
 		ManagedBy: s.ManagedBy,
 	}
@@ -78,9 +91,16 @@ func (s *RRSet) CreationForm() (io.Reader, error) {
 }
 
 func (s *RRSet) UpdateForm() (io.Reader, error) {
+	if err := validateTXTRecords(s.Type, s.Records); err != nil {
+		return nil, err
+	}
+	records, err := asciiRecords(s.Type, s.Records)
+	if err != nil {
+		return nil, err
+	}
 	form := rrsetUpdateForm{
 		TTL:       s.TTL,
-		Records:   s.Records,
+		Records:   records,
 		Comment:   s.Comment,
 		ManagedBy: s.ManagedBy,
 	}
@@ -91,15 +111,7 @@ func (s *RRSet) UpdateForm() (io.Reader, error) {
 
 // CreateRRSet request to create a new rrset for the zone.
 func (c *Client) CreateRRSet(ctx context.Context, zoneID string, rrset Creatable) (*RRSet, error) {
-	form, err := rrset.CreationForm()
-	if err != nil {
-		return nil, fmt.Errorf("rrset creation form: %w", err)
-	}
-	r, e := c.prepareRequest(
-		ctx, http.MethodPost, fmt.Sprintf(rrsetPath, zoneID), form, nil, nil,
-	)
-
-	return processRequest[RRSet](c.httpClient, r, e)
+	return createRRSet[RRSet](ctx, c, zoneID, rrset)
 }
 
 // DeleteRRSet request to delete the rrset from zone by zoneID and rrsetID.
@@ -114,20 +126,12 @@ func (c *Client) DeleteRRSet(ctx context.Context, zoneID, rrsetID string) error
 
 // GetRRSet returns a single rrset from zone by zoneID and rrsetID.
 func (c *Client) GetRRSet(ctx context.Context, zoneID, rrsetID string) (*RRSet, error) {
-	r, e := c.prepareRequest(
-		ctx, http.MethodGet, fmt.Sprintf(singleRRSetPath, zoneID, rrsetID), nil, nil, nil,
-	)
-
-	return processRequest[RRSet](c.httpClient, r, e)
+	return getRRSet[RRSet](ctx, c, zoneID, rrsetID)
 }
 
 // ListRRSets returns a list of rrsets by zoneID and options.
 func (c *Client) ListRRSets(ctx context.Context, zoneID string, options *map[string]string) (Listable[RRSet], error) {
-	r, e := c.prepareRequest(
-		ctx, http.MethodGet, fmt.Sprintf(rrsetPath, zoneID), nil, options, nil,
-	)
-
-	return processRequest[List[RRSet]](c.httpClient, r, e)
+	return listRRSets[RRSet](ctx, c, zoneID, options)
 }
 
 // UpdateRRSet request to update the rrset for zone by zoneID and rrsetID.
@@ -143,3 +147,43 @@ func (c *Client) UpdateRRSet(ctx context.Context, zoneID, rrsetID string, rrset
 
 	return err
 }
+
+func createRRSet[S any](ctx context.Context, c *Client, zoneID string, rrset Creatable) (*S, error) {
+	rrset, err := c.qualifyRRSet(ctx, zoneID, rrset)
+	if err != nil {
+		return nil, err
+	}
+	form, err := rrset.CreationForm()
+	if err != nil {
+		return nil, fmt.Errorf("rrset creation form: %w", err)
+	}
+	r, e := c.prepareRequest(
+		ctx, http.MethodPost, fmt.Sprintf(rrsetPath, zoneID), form, nil, nil,
+	)
+
+	return processRequest[S](c.httpClient, r, e)
+}
+
+func getRRSet[S any](ctx context.Context, c *Client, zoneID, rrsetID string) (*S, error) {
+	r, e := c.prepareRequest(
+		ctx, http.MethodGet, fmt.Sprintf(singleRRSetPath, zoneID, rrsetID), nil, nil, nil,
+	)
+
+	return processRequest[S](c.httpClient, r, e)
+}
+
+func listRRSets[S any](ctx context.Context, c *Client, zoneID string, options *map[string]string) (Listable[S], error) {
+	options, err := c.qualifyOptions(ctx, zoneID, options)
+	if err != nil {
+		return nil, err
+	}
+	options, err = asciiOptions(options, "name")
+	if err != nil {
+		return nil, err
+	}
+	r, e := c.prepareRequest(
+		ctx, http.MethodGet, fmt.Sprintf(rrsetPath, zoneID), nil, options, nil,
+	)
+
+	return processRequest[List[S]](c.httpClient, r, e)
+}
@@ -0,0 +1,66 @@
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FindRRSet returns the rrset of the zone with the given name and type.
+// ErrNotFound is returned if the zone has no such rrset.
+func FindRRSet(
+	ctx context.Context, manager RRSetManager[RRSet], zoneID, name string, recordType RecordType,
+) (*RRSet, error) {
+	options := map[string]string{"name": name, "rrset_types": string(recordType)}
+	rrsets, err := ListAllRRSets[RRSet](ctx, manager, zoneID, &options)
+	if err != nil {
+		return nil, err
+	}
+	for _, rrset := range rrsets {
+		if rrset.Type == recordType && sameName(rrset.Name, name) {
+			return rrset, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: rrset %s %s", ErrNotFound, name, recordType)
+}
+
+// UpsertRRSet creates the rrset or updates the existing rrset with the same name and type.
+// The returned rrset carries the id of the created or updated rrset.
+func UpsertRRSet(ctx context.Context, manager RRSetManager[RRSet], zoneID string, rrset *RRSet) (*RRSet, error) {
+	existing, err := FindRRSet(ctx, manager, zoneID, rrset.Name, rrset.Type)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("find rrset: %w", err)
+	}
+
+	return UpsertFoundRRSet(ctx, manager, zoneID, existing, rrset)
+}
+
+// UpsertFoundRRSet updates the existing rrset the caller has already found, e.g. with FindRRSet,
+// or creates the rrset if existing is nil. It saves the lookup of UpsertRRSet for callers
+// that build the rrset from the existing one.
+func UpsertFoundRRSet(
+	ctx context.Context, manager RRSetManager[RRSet], zoneID string, existing, rrset *RRSet,
+) (*RRSet, error) {
+	if existing != nil {
+		if err := manager.UpdateRRSet(ctx, zoneID, existing.ID, rrset); err != nil {
+			return nil, fmt.Errorf("update rrset: %w", err)
+		}
+		updated := *rrset
+		updated.ID, updated.ZoneID, updated.Name = existing.ID, existing.ZoneID, existing.Name
+
+		return &updated, nil
+	}
+
+	created, err := manager.CreateRRSet(ctx, zoneID, rrset)
+	if err != nil {
+		return nil, fmt.Errorf("create rrset: %w", err)
+	}
+
+	return created, nil
+}
+
+func sameName(a, b string) bool {
+	return strings.EqualFold(strings.TrimSuffix(a, "."), strings.TrimSuffix(b, "."))
+}
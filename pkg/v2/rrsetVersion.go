@@ -0,0 +1,93 @@
+package v2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// defaultMergeAttempts represents the default number of attempts of UpdateRRSetWithMerge.
+const defaultMergeAttempts = 3
+
+type (
+	// MergeFunc returns the desired state of an rrset based on its current state.
+	MergeFunc func(current *RRSet) (Updatable, error)
+
+	rrsetVersionForm struct {
+		TTL       int          `json:"ttl"`
+		Comment   string       `json:"comment"`
+		ManagedBy string       `json:"managed_by"`
+		Records   []RecordItem `json:"records"`
+	}
+)
+
+// Version returns a hash of the rrset contents that can be changed by an update.
+// The order of records doesn't affect the version.
+func (s *RRSet) Version() string {
+	records := append([]RecordItem(nil), s.Records...)
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Content != records[j].Content {
+			return records[i].Content < records[j].Content
+		}
+
+		return !records[i].Disabled && records[j].Disabled
+	})
+	//nolint: errchkjson
+	data, _ := json.Marshal(rrsetVersionForm{
+		TTL:       s.TTL,
+		Comment:   s.Comment,
+		ManagedBy: s.ManagedBy,
+		Records:   records,
+	})
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// UpdateRRSetIfUnchanged re-reads the rrset and updates it only if its version equals expected,
+// otherwise a *ConflictError is returned. The API has no conditional requests,
+// so this narrows the window for lost updates rather than closing it completely.
+func UpdateRRSetIfUnchanged(
+	ctx context.Context, manager RRSetManager[RRSet], zoneID, rrsetID, expected string, desired Updatable,
+) error {
+	current, err := manager.GetRRSet(ctx, zoneID, rrsetID)
+	if err != nil {
+		return fmt.Errorf("get rrset: %w", err)
+	}
+	if actual := current.Version(); actual != expected {
+		return &ConflictError{RRSetID: rrsetID, Expected: expected, Actual: actual, Current: current}
+	}
+
+	return manager.UpdateRRSet(ctx, zoneID, rrsetID, desired)
+}
+
+// UpdateRRSetWithMerge reads the rrset, builds its desired state with merge and updates it
+// if it wasn't changed in the meantime. On a conflict merge is called again with the new state,
+// up to attempts times; if attempts is zero, defaultMergeAttempts is used.
+func UpdateRRSetWithMerge(
+	ctx context.Context, manager RRSetManager[RRSet], zoneID, rrsetID string, attempts int, merge MergeFunc,
+) error {
+	if attempts <= 0 {
+		attempts = defaultMergeAttempts
+	}
+	current, err := manager.GetRRSet(ctx, zoneID, rrsetID)
+	if err != nil {
+		return fmt.Errorf("get rrset: %w", err)
+	}
+	for attempt := 1; ; attempt++ {
+		desired, err := merge(current)
+		if err != nil {
+			return fmt.Errorf("merge rrset: %w", err)
+		}
+		err = UpdateRRSetIfUnchanged(ctx, manager, zoneID, rrsetID, current.Version(), desired)
+		var conflict *ConflictError
+		if !errors.As(err, &conflict) || attempt >= attempts {
+			return err
+		}
+		current = conflict.Current
+	}
+}
@@ -0,0 +1,36 @@
+/*
+Package spf builds, parses and checks SPF records kept in TXT rrsets of the
+Selectel Domains API V2.
+
+Records are parsed into terms and rendered back, long records are split into
+quoted strings of at most 255 bytes. CountLookups follows includes and
+redirects and counts the DNS lookups a receiver has to do, which RFC 7208
+limits to 10. Names of zones loaded into a ZoneResolver are resolved from the
+API data, other names go to a fallback resolver such as net.DefaultResolver.
+Flatten replaces lookups with the addresses they resolve to.
+
+Example of checking and publishing a record
+
+  record, err := spf.Parse("v=spf1 mx include:_spf.google.com ~all")
+  if err != nil {
+    log.Fatal(err)
+  }
+  resolver := spf.NewZoneResolver(net.DefaultResolver)
+  if err := resolver.LoadZone(ctx, client, zone); err != nil {
+    log.Fatal(err)
+  }
+  report, err := spf.CountLookups(ctx, resolver, zone.Name, record)
+  if err != nil {
+    log.Fatal(err)
+  }
+  if report.Exceeded() {
+    record, err = spf.Flatten(ctx, resolver, zone.Name, record)
+    if err != nil {
+      log.Fatal(err)
+    }
+  }
+  if _, err := spf.Publish(ctx, client, zone.ID, zone.Name, 300, record); err != nil {
+    log.Fatal(err)
+  }
+*/
+package spf
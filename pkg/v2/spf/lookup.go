@@ -0,0 +1,380 @@
+package spf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Limits of RFC 7208 on DNS lookups done while evaluating a record.
+const (
+	MaxLookups     = 10
+	MaxVoidLookups = 2
+)
+
+var (
+	ErrNoRecord        = errors.New("no SPF record")
+	ErrMultipleRecords = errors.New("multiple SPF records")
+	ErrLoop            = errors.New("SPF record includes itself")
+)
+
+type (
+	// Resolver looks up DNS data of external domains, *net.Resolver satisfies it.
+	Resolver interface {
+		LookupTXT(ctx context.Context, name string) ([]string, error)
+		LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+		LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+	}
+
+	// Lookup describes a term that requires a DNS lookup.
+	Lookup struct {
+		// Domain is the domain whose record contains the term.
+		Domain string
+		Term   Term
+		// Depth is the number of includes and redirects leading to the record.
+		Depth int
+	}
+
+	// LookupReport describes DNS lookups needed to evaluate a record.
+	LookupReport struct {
+		Count int
+		// VoidCount is the number of includes and redirects pointing to domains without an SPF record.
+		VoidCount int
+		Lookups   []Lookup
+		// Warnings lists terms that could not be followed, e.g. because of macros.
+		Warnings []string
+	}
+
+	lookupWalker struct {
+		resolver Resolver
+		report   *LookupReport
+	}
+)
+
+// Exceeded reports whether the record needs more lookups than RFC 7208 allows.
+func (r *LookupReport) Exceeded() bool {
+	return r.Count > MaxLookups || r.VoidCount > MaxVoidLookups
+}
+
+// LookupRecord returns the SPF record published for the domain.
+func LookupRecord(ctx context.Context, resolver Resolver, domain string) (*Record, error) {
+	txts, err := resolver.LookupTXT(ctx, domain)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrNoRecord, domain)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lookup TXT %s: %w", domain, err)
+	}
+	var records []string
+	for _, txt := range txts {
+		if IsSPF(txt) {
+			records = append(records, txt)
+		}
+	}
+	switch len(records) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s", ErrNoRecord, domain)
+	case 1:
+		return Parse(records[0])
+	}
+
+	return nil, fmt.Errorf("%w: %s has %d", ErrMultipleRecords, domain, len(records))
+}
+
+// CountLookups counts DNS lookups done by include, a, mx, ptr and exists mechanisms
+// and the redirect modifier of the record published for domain, following includes and redirects.
+// If record is not nil, it is used instead of the published one, which allows checking a record before publishing it.
+func CountLookups(ctx context.Context, resolver Resolver, domain string, record *Record) (*LookupReport, error) {
+	if record == nil {
+		var err error
+		record, err = LookupRecord(ctx, resolver, domain)
+		if err != nil {
+			return nil, err
+		}
+	}
+	//nolint: exhaustruct
+	walker := &lookupWalker{resolver: resolver, report: &LookupReport{}}
+	if err := walker.walk(ctx, domain, record, []string{normalizeDomain(domain)}); err != nil {
+		return walker.report, err
+	}
+
+	return walker.report, nil
+}
+
+func (w *lookupWalker) walk(ctx context.Context, domain string, record *Record, stack []string) error {
+	for _, term := range record.Terms {
+		if !needsLookup(term) {
+			continue
+		}
+		w.report.Count++
+		w.report.Lookups = append(w.report.Lookups, Lookup{Domain: domain, Term: term, Depth: len(stack) - 1})
+
+		if term.Mechanism != MechanismInclude && !(term.Modifier == ModifierRedirect && !hasAll(record)) {
+			continue
+		}
+		target, nested, err := w.follow(ctx, term, stack)
+		if err != nil {
+			return err
+		}
+		if nested == nil {
+			continue
+		}
+		if err := w.walk(ctx, target, nested, append(stack, normalizeDomain(target))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// follow returns the record an include or a redirect points to.
+// A nil record without an error means the target can't or needn't be followed.
+func (w *lookupWalker) follow(ctx context.Context, term Term, stack []string) (string, *Record, error) {
+	target := term.Value
+	if hasMacro(target) {
+		w.report.Warnings = append(w.report.Warnings, fmt.Sprintf("%s: macros are not expanded", term))
+
+		return target, nil, nil
+	}
+	for _, visited := range stack {
+		if visited == normalizeDomain(target) {
+			return target, nil, fmt.Errorf("%w: %s", ErrLoop, target)
+		}
+	}
+	nested, err := LookupRecord(ctx, w.resolver, target)
+	if errors.Is(err, ErrNoRecord) {
+		w.report.VoidCount++
+		w.report.Warnings = append(w.report.Warnings, fmt.Sprintf("%s: %s", term, err))
+
+		return target, nil, nil
+	}
+	if err != nil {
+		return target, nil, err
+	}
+
+	return target, nested, nil
+}
+
+// Flatten returns a record that gives the same results for senders but needs fewer lookups:
+// a and mx mechanisms are replaced with addresses they resolve to and includes and redirects
+// are replaced with addresses of the records they point to. Terms that can't be flattened,
+// such as ptr, exists and terms with macros, are kept as is, and so are includes of such records
+// and of records with failing, softfailing or neutral terms.
+func Flatten(ctx context.Context, resolver Resolver, domain string, record *Record) (*Record, error) {
+	if record == nil {
+		var err error
+		record, err = LookupRecord(ctx, resolver, domain)
+		if err != nil {
+			return nil, err
+		}
+	}
+	terms, err := flattenTerms(ctx, resolver, domain, record, []string{normalizeDomain(domain)})
+	if err != nil {
+		return nil, err
+	}
+	for _, term := range record.Terms {
+		if term.IsModifier() && term.Modifier != ModifierRedirect {
+			terms = append(terms, term)
+		}
+	}
+
+	return &Record{Terms: dedupe(terms)}, nil
+}
+
+func flattenTerms(ctx context.Context, resolver Resolver, domain string, record *Record, stack []string) ([]Term, error) {
+	var terms []Term
+	var redirect *Term
+	for i, term := range record.Terms {
+		switch {
+		case term.IsModifier():
+			if term.Modifier == ModifierRedirect && !hasAll(record) {
+				redirect = &record.Terms[i]
+			}
+		case hasMacro(term.Value):
+			terms = append(terms, term)
+		case term.Mechanism == MechanismA || term.Mechanism == MechanismMX:
+			addrs, err := resolveTerm(ctx, resolver, domain, term)
+			if err != nil {
+				return nil, err
+			}
+			terms = append(terms, addrs...)
+		case term.Mechanism == MechanismInclude:
+			nested, err := flattenNested(ctx, resolver, term, stack)
+			if err != nil {
+				return nil, err
+			}
+			if nested == nil || !onlyAddresses(nested) || !onlyPasses(nested) {
+				terms = append(terms, term)
+
+				continue
+			}
+			for _, nestedTerm := range nested {
+				if nestedTerm.Mechanism != MechanismAll {
+					nestedTerm.Qualifier = term.Qualifier
+					terms = append(terms, nestedTerm)
+				}
+			}
+		default:
+			terms = append(terms, term)
+		}
+	}
+	if redirect == nil {
+		return terms, nil
+	}
+
+	// The redirect applies only when no mechanism matches, wherever it's written.
+	nested, err := flattenNested(ctx, resolver, *redirect, stack)
+	if err != nil {
+		return nil, err
+	}
+	if nested == nil {
+		// The redirect can't be flattened, keep it as the last term.
+		return append(terms, *redirect), nil
+	}
+
+	return append(terms, nested...), nil
+}
+
+// flattenNested returns flattened terms of the record an include or a redirect points to.
+func flattenNested(ctx context.Context, resolver Resolver, term Term, stack []string) ([]Term, error) {
+	target := term.Value
+	if hasMacro(target) {
+		return nil, nil
+	}
+	for _, visited := range stack {
+		if visited == normalizeDomain(target) {
+			return nil, fmt.Errorf("%w: %s", ErrLoop, target)
+		}
+	}
+	nested, err := LookupRecord(ctx, resolver, target)
+	if err != nil {
+		return nil, err
+	}
+
+	return flattenTerms(ctx, resolver, target, nested, append(stack, normalizeDomain(target)))
+}
+
+// resolveTerm returns ip4 and ip6 terms with addresses matched by an a or mx mechanism.
+func resolveTerm(ctx context.Context, resolver Resolver, domain string, term Term) ([]Term, error) {
+	name := term.Value
+	if name == "" {
+		name = domain
+	}
+	hosts := []string{name}
+	if term.Mechanism == MechanismMX {
+		mxs, err := resolver.LookupMX(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("lookup MX %s: %w", name, err)
+		}
+		hosts = hosts[:0]
+		for _, mx := range mxs {
+			hosts = append(hosts, mx.Host)
+		}
+	}
+	cidr4, cidr6 := splitCIDR(term.CIDR)
+	var terms []Term
+	for _, host := range hosts {
+		addrs, err := resolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, fmt.Errorf("lookup addresses of %s: %w", host, err)
+		}
+		for _, addr := range addrs {
+			//nolint: exhaustruct
+			ipTerm := Term{Qualifier: term.Qualifier, Mechanism: MechanismIP6, Value: addr.IP.String() + cidr6}
+			if addr.IP.To4() != nil {
+				ipTerm.Mechanism, ipTerm.Value = MechanismIP4, addr.IP.To4().String()+cidr4
+			}
+			terms = append(terms, ipTerm)
+		}
+	}
+
+	return terms, nil
+}
+
+// splitCIDR splits a dual CIDR length suffix like "/24//64" into IPv4 and IPv6 parts.
+func splitCIDR(cidr string) (string, string) {
+	if i := strings.Index(cidr, "//"); i >= 0 {
+		return cidr[:i], cidr[i+1:]
+	}
+
+	return cidr, ""
+}
+
+func needsLookup(term Term) bool {
+	switch term.Mechanism {
+	case MechanismInclude, MechanismA, MechanismMX, MechanismPTR, MechanismExists:
+		return true
+	}
+
+	return term.Modifier == ModifierRedirect
+}
+
+func onlyAddresses(terms []Term) bool {
+	for _, term := range terms {
+		switch term.Mechanism {
+		case MechanismIP4, MechanismIP6, MechanismAll:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// onlyPasses reports whether an include of the terms matches exactly when one of its terms but all does.
+// A failing term excludes addresses from later terms and a passing all matches any address,
+// so includes of such records can't be replaced with their terms.
+func onlyPasses(terms []Term) bool {
+	for _, term := range terms {
+		passes := qualifierOf(term) == QualifierPass
+		if passes == (term.Mechanism == MechanismAll) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasAll(record *Record) bool {
+	for _, term := range record.Terms {
+		if term.Mechanism == MechanismAll {
+			return true
+		}
+	}
+
+	return false
+}
+
+func qualifierOf(term Term) Qualifier {
+	if term.Qualifier == 0 {
+		return QualifierPass
+	}
+
+	return term.Qualifier
+}
+
+func hasMacro(value string) bool {
+	return strings.Contains(value, "%{")
+}
+
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(domain), ".")
+}
+
+func dedupe(terms []Term) []Term {
+	seen := make(map[string]bool, len(terms))
+	result := make([]Term, 0, len(terms))
+	for _, term := range terms {
+		key := term.String()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, term)
+	}
+
+	return result
+}
@@ -0,0 +1,220 @@
+package spf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	v2 "github.com/selectel/domains-go/pkg/v2"
+)
+
+// Version represents the version tag every SPF record starts with.
+const Version = "v=spf1"
+
+// Qualifiers of mechanisms.
+const (
+	QualifierPass     Qualifier = '+'
+	QualifierFail     Qualifier = '-'
+	QualifierSoftFail Qualifier = '~'
+	QualifierNeutral  Qualifier = '?'
+)
+
+// Mechanisms defined by RFC 7208.
+const (
+	MechanismAll     = "all"
+	MechanismInclude = "include"
+	MechanismA       = "a"
+	MechanismMX      = "mx"
+	MechanismPTR     = "ptr"
+	MechanismIP4     = "ip4"
+	MechanismIP6     = "ip6"
+	MechanismExists  = "exists"
+)
+
+// Modifiers defined by RFC 7208.
+const (
+	ModifierRedirect = "redirect"
+	ModifierExp      = "exp"
+)
+
+var (
+	ErrNotSPF      = errors.New("not an SPF record")
+	ErrInvalidTerm = errors.New("invalid SPF term")
+)
+
+type (
+	// Qualifier represents the result a mechanism produces when it matches.
+	Qualifier byte
+
+	// Term represents a single mechanism or modifier of an SPF record.
+	Term struct {
+		// Qualifier of a mechanism, zero means the default pass qualifier.
+		Qualifier Qualifier
+		// Mechanism holds the mechanism name, it is empty for modifiers.
+		Mechanism string
+		// Modifier holds the modifier name, it is empty for mechanisms.
+		Modifier string
+		// Value holds the domain-spec, the address or the modifier value.
+		Value string
+		// CIDR holds the prefix length suffix of a and mx mechanisms, e.g. "/24" or "/24//64".
+		CIDR string
+	}
+
+	// Record represents a parsed SPF record.
+	Record struct {
+		Terms []Term
+	}
+)
+
+// IsSPF reports whether the TXT data is an SPF record.
+func IsSPF(data string) bool {
+	data = strings.ToLower(strings.TrimSpace(data))
+
+	return data == Version || strings.HasPrefix(data, Version+" ")
+}
+
+// Parse parses an SPF record. Quoted TXT content, as stored in rrsets, is accepted too.
+func Parse(data string) (*Record, error) {
+	data = v2.TXTData(data)
+	if !IsSPF(data) {
+		return nil, ErrNotSPF
+	}
+	fields := strings.Fields(data)[1:]
+	record := &Record{Terms: make([]Term, 0, len(fields))}
+	for _, field := range fields {
+		term, err := ParseTerm(field)
+		if err != nil {
+			return nil, err
+		}
+		record.Terms = append(record.Terms, term)
+	}
+
+	return record, nil
+}
+
+// ParseTerm parses a single mechanism or modifier.
+func ParseTerm(field string) (Term, error) {
+	//nolint: exhaustruct
+	term := Term{}
+	if name, value, ok := strings.Cut(field, "="); ok && isName(name) {
+		term.Modifier, term.Value = strings.ToLower(name), value
+		if value == "" && (term.Modifier == ModifierRedirect || term.Modifier == ModifierExp) {
+			return term, fmt.Errorf("%w: %q requires a value", ErrInvalidTerm, field)
+		}
+
+		return term, nil
+	}
+
+	rest := field
+	switch Qualifier(rest[0]) {
+	case QualifierPass, QualifierFail, QualifierSoftFail, QualifierNeutral:
+		term.Qualifier, rest = Qualifier(rest[0]), rest[1:]
+	}
+	name, value, hasValue := strings.Cut(rest, ":")
+	if !hasValue {
+		name, term.CIDR = cutCIDR(name)
+	}
+	term.Mechanism = strings.ToLower(name)
+	switch term.Mechanism {
+	case MechanismAll:
+		if hasValue || term.CIDR != "" {
+			return term, fmt.Errorf("%w: %q takes no arguments", ErrInvalidTerm, field)
+		}
+	case MechanismInclude, MechanismExists, MechanismIP4, MechanismIP6:
+		if value == "" {
+			return term, fmt.Errorf("%w: %q requires a value", ErrInvalidTerm, field)
+		}
+		term.Value = value
+	case MechanismA, MechanismMX:
+		if hasValue {
+			term.Value, term.CIDR = cutCIDR(value)
+			if term.Value == "" {
+				return term, fmt.Errorf("%w: %q has an empty domain", ErrInvalidTerm, field)
+			}
+		}
+	case MechanismPTR:
+		term.Value = value
+	default:
+		return term, fmt.Errorf("%w: unknown mechanism %q", ErrInvalidTerm, field)
+	}
+
+	return term, nil
+}
+
+// IsModifier reports whether the term is a modifier.
+func (t Term) IsModifier() bool {
+	return t.Modifier != ""
+}
+
+// String returns the term in the SPF syntax.
+func (t Term) String() string {
+	if t.IsModifier() {
+		return t.Modifier + "=" + t.Value
+	}
+	var builder strings.Builder
+	if t.Qualifier != 0 && t.Qualifier != QualifierPass {
+		builder.WriteByte(byte(t.Qualifier))
+	}
+	builder.WriteString(t.Mechanism)
+	if t.Value != "" {
+		builder.WriteString(":" + t.Value)
+	}
+	builder.WriteString(t.CIDR)
+
+	return builder.String()
+}
+
+// String returns the record in the SPF syntax.
+func (r *Record) String() string {
+	parts := make([]string, 0, len(r.Terms)+1)
+	parts = append(parts, Version)
+	for _, term := range r.Terms {
+		parts = append(parts, term.String())
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// TXT returns the record as TXT content of an rrset, split into quoted strings of at most 255 bytes.
+func (r *Record) TXT() string {
+	return v2.EncodeTXT(r.String())
+}
+
+// Modifier returns the value of the modifier with the given name.
+func (r *Record) Modifier(name string) (string, bool) {
+	for _, term := range r.Terms {
+		if term.Modifier == name {
+			return term.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// cutCIDR splits a domain-spec from its dual CIDR length suffix.
+func cutCIDR(value string) (string, string) {
+	if i := strings.Index(value, "/"); i >= 0 {
+		return value[:i], value[i:]
+	}
+
+	return value, ""
+}
+
+// isName reports whether the value is a valid modifier name.
+func isName(value string) bool {
+	if value == "" || !isLetter(value[0]) {
+		return false
+	}
+	for i := 1; i < len(value); i++ {
+		c := value[i]
+		if !isLetter(c) && !(c >= '0' && c <= '9') && c != '-' && c != '_' && c != '.' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,256 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/selectel/domains-go/pkg/testutils"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/spf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testZoneName = "bonnie-test.com."
+
+// fakeResolver resolves TXT records of external domains from a static map.
+type fakeResolver struct {
+	txt map[string][]string
+}
+
+func (r *fakeResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	if txts, ok := r.txt[strings.TrimSuffix(name, ".")]; ok {
+		return txts, nil
+	}
+
+	return nil, notFound(name)
+}
+
+func (r *fakeResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	return nil, notFound(host)
+}
+
+func (r *fakeResolver) LookupMX(_ context.Context, name string) ([]*net.MX, error) {
+	return nil, notFound(name)
+}
+
+func notFound(name string) error {
+	//nolint: exhaustruct
+	return &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func newResolver() *spf.ZoneResolver {
+	resolver := spf.NewZoneResolver(&fakeResolver{
+		txt: map[string][]string{
+			"ext.test":   {"v=spf1 include:ext2.test ip6:2001:db8::/32 -all"},
+			"ext2.test":  {"v=spf1 ip4:198.51.100.0/24 -all", "some-verification=1"},
+			"loop.test":  {"v=spf1 include:loop2.test -all"},
+			"loop2.test": {"v=spf1 include:loop.test -all"},
+			"mixed.test": {"v=spf1 -ip4:198.51.100.4 ip4:198.51.100.0/24 -all"},
+		},
+	})
+	resolver.AddZone(testZoneName, []*v2.RRSet{
+		testutils.NewRRSet(testZoneName, v2.TXT, `"v=spf1 mx include:_spf.bonnie-test.com include:ext.test -all"`),
+		testutils.NewRRSet(testZoneName, v2.MX, "10 mail.bonnie-test.com."),
+		testutils.NewRRSet("_spf."+testZoneName, v2.TXT, `"v=spf1 a:mail.bonnie-test.com ip4:192.0.2.1 ~all"`),
+		testutils.NewRRSet("mail."+testZoneName, v2.A, "192.0.2.10"),
+	})
+
+	return resolver
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	data := "v=spf1 ip4:192.0.2.0/24 +a mx:mail.bonnie-test.com/24//64 -include:ext.test " +
+		"?exists:%{i}.bonnie-test.com ~ptr redirect=_spf.bonnie-test.com exp=explain.bonnie-test.com"
+
+	record, err := spf.Parse(data)
+
+	require.NoError(t, err)
+	require.Len(t, record.Terms, 8)
+	assert.Equal(t, spf.MechanismA, record.Terms[1].Mechanism)
+	assert.Equal(t, spf.QualifierPass, record.Terms[1].Qualifier)
+	assert.Equal(t, "mail.bonnie-test.com", record.Terms[2].Value)
+	assert.Equal(t, "/24//64", record.Terms[2].CIDR)
+	assert.Equal(t, spf.QualifierFail, record.Terms[3].Qualifier)
+	value, ok := record.Modifier(spf.ModifierRedirect)
+	assert.True(t, ok)
+	assert.Equal(t, "_spf.bonnie-test.com", value)
+	assert.Equal(t, strings.Replace(data, "+a", "a", 1), record.String())
+}
+
+func TestParse_quoted(t *testing.T) {
+	t.Parallel()
+	record, err := spf.Parse(`"v=spf1 " "a -all"`)
+
+	require.NoError(t, err)
+	assert.Equal(t, "v=spf1 a -all", record.String())
+}
+
+func TestParse_errors(t *testing.T) {
+	t.Parallel()
+	_, err := spf.Parse("v=spf10 -all")
+	require.ErrorIs(t, err, spf.ErrNotSPF)
+
+	for _, data := range []string{"v=spf1 foo", "v=spf1 include:", "v=spf1 all:x", "v=spf1 redirect="} {
+		_, err := spf.Parse(data)
+		assert.ErrorIs(t, err, spf.ErrInvalidTerm, data)
+	}
+}
+
+func TestRecordTXT_splits_long_records(t *testing.T) {
+	t.Parallel()
+	record := &spf.Record{Terms: nil}
+	for i := 0; i < 20; i++ {
+		term, err := spf.ParseTerm(fmt.Sprintf("ip4:192.0.2.%d", i))
+		require.NoError(t, err)
+		record.Terms = append(record.Terms, term)
+	}
+
+	content := record.TXT()
+
+	assert.Equal(t, 255+2, strings.Index(content, `" "`)+1)
+	parsed, err := spf.Parse(content)
+	require.NoError(t, err)
+	assert.Equal(t, record.String(), parsed.String())
+}
+
+func TestFindDuplicates(t *testing.T) {
+	t.Parallel()
+	rrsets := []*v2.RRSet{
+		testutils.NewRRSet(testZoneName, v2.TXT, `"v=spf1 -all"`, `"v=spf1 mx -all"`, `"verification"`),
+		testutils.NewRRSet("www."+testZoneName, v2.TXT, `"v=spf1 -all"`),
+	}
+
+	duplicates := spf.FindDuplicates(rrsets)
+
+	require.Len(t, duplicates, 1)
+	assert.Equal(t, testZoneName, duplicates[0].Name)
+	assert.Equal(t, []string{"v=spf1 -all", "v=spf1 mx -all"}, duplicates[0].Records)
+}
+
+func TestCountLookups(t *testing.T) {
+	t.Parallel()
+	report, err := spf.CountLookups(context.Background(), newResolver(), testZoneName, nil)
+
+	require.NoError(t, err)
+	// mx, include:_spf, a:mail, include:ext.test, include:ext2.test.
+	assert.Equal(t, 5, report.Count)
+	assert.False(t, report.Exceeded())
+	assert.Equal(t, "ext2.test", report.Lookups[4].Term.Value)
+	assert.Equal(t, 1, report.Lookups[4].Depth)
+}
+
+func TestCountLookups_exceeded(t *testing.T) {
+	t.Parallel()
+	terms := make([]string, 0, 11)
+	for i := 0; i < 11; i++ {
+		terms = append(terms, fmt.Sprintf("a:host%d.bonnie-test.com", i))
+	}
+	record, err := spf.Parse("v=spf1 " + strings.Join(terms, " ") + " include:missing.test -all")
+	require.NoError(t, err)
+
+	report, err := spf.CountLookups(context.Background(), newResolver(), testZoneName, record)
+
+	require.NoError(t, err)
+	assert.Equal(t, 12, report.Count)
+	assert.Equal(t, 1, report.VoidCount)
+	assert.True(t, report.Exceeded())
+}
+
+func TestCountLookups_after_void_include(t *testing.T) {
+	t.Parallel()
+	record, err := spf.Parse("v=spf1 include:missing.test a:mail.bonnie-test.com include:ext.test -all")
+	require.NoError(t, err)
+
+	report, err := spf.CountLookups(context.Background(), newResolver(), testZoneName, record)
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Count)
+	assert.Equal(t, 1, report.VoidCount)
+	require.Len(t, report.Lookups, 4)
+	assert.Equal(t, "include:ext2.test", report.Lookups[3].Term.String())
+	assert.Equal(t, 1, report.Lookups[3].Depth)
+}
+
+func TestCountLookups_loop(t *testing.T) {
+	t.Parallel()
+	_, err := spf.CountLookups(context.Background(), newResolver(), "loop.test", nil)
+
+	assert.ErrorIs(t, err, spf.ErrLoop)
+}
+
+func TestFlatten(t *testing.T) {
+	t.Parallel()
+	resolver := newResolver()
+
+	record, err := spf.Flatten(context.Background(), resolver, testZoneName, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "v=spf1 ip4:192.0.2.10 ip4:192.0.2.1 ip4:198.51.100.0/24 ip6:2001:db8::/32 -all", record.String())
+	report, err := spf.CountLookups(context.Background(), resolver, testZoneName, record)
+	require.NoError(t, err)
+	assert.Zero(t, report.Count)
+}
+
+func TestFlatten_include_with_failing_terms(t *testing.T) {
+	t.Parallel()
+	record, err := spf.Parse("v=spf1 include:mixed.test include:ext2.test -all")
+	require.NoError(t, err)
+
+	flattened, err := spf.Flatten(context.Background(), newResolver(), testZoneName, record)
+
+	require.NoError(t, err)
+	assert.Equal(t, "v=spf1 include:mixed.test ip4:198.51.100.0/24 -all", flattened.String())
+}
+
+func TestFlatten_redirect_before_mechanisms(t *testing.T) {
+	t.Parallel()
+	record, err := spf.Parse("v=spf1 redirect=ext2.test ip4:192.0.2.1")
+	require.NoError(t, err)
+
+	flattened, err := spf.Flatten(context.Background(), newResolver(), testZoneName, record)
+
+	require.NoError(t, err)
+	assert.Equal(t, "v=spf1 ip4:192.0.2.1 ip4:198.51.100.0/24 -all", flattened.String())
+}
+
+func TestPublish(t *testing.T) {
+	t.Parallel()
+	api := testutils.NewFakeAPI()
+	defer api.Close()
+	zone := api.AddZone(testZoneName)
+	api.AddRRSet(zone.ID, *testutils.NewRRSet(testZoneName, v2.TXT, `"verification"`, `"v=spf1 -all"`))
+	record, err := spf.Parse("v=spf1 mx -all")
+	require.NoError(t, err)
+
+	rrset, err := spf.Publish(context.Background(), api.Client(), zone.ID, testZoneName, 0, record)
+
+	require.NoError(t, err)
+	assert.Equal(t, 60, rrset.TTL)
+	assert.Equal(t, []string{
+		"GET /zones/" + zone.ID + "/rrset",
+		"PATCH /zones/" + zone.ID + "/rrset/" + rrset.ID,
+	}, api.Requests())
+	rrsets := api.RRSets(zone.ID)
+	require.Len(t, rrsets, 1)
+	assert.Equal(t, []v2.RecordItem{
+		{Content: `"verification"`, Disabled: false},
+		{Content: `"v=spf1 mx -all"`, Disabled: false},
+	}, rrsets[0].Records)
+
+	rrset, err = spf.Publish(context.Background(), api.Client(), zone.ID, "www."+testZoneName, 300, record)
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, rrset.ID)
+	assert.Equal(t, 300, rrset.TTL)
+	assert.Len(t, api.RRSets(zone.ID), 2)
+
+	rrset, err = spf.Publish(context.Background(), api.Client(), zone.ID, "mail."+testZoneName, 0, record)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3600, rrset.TTL)
+}
@@ -0,0 +1,229 @@
+package spf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/miekg/dns"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/rrconv"
+)
+
+// defaultTTL represents the TTL of TXT rrsets created by Publish.
+const defaultTTL = 3600
+
+type (
+	// ZoneResolver answers lookups for names of zones loaded from the API
+	// and passes lookups of other names to the fallback resolver.
+	// CNAME records of loaded zones are not followed.
+	ZoneResolver struct {
+		// Fallback resolves names outside the loaded zones. If nil, such names are reported as not found.
+		Fallback Resolver
+
+		zones map[string][]*v2.RRSet
+	}
+
+	// Duplicate describes a name with more than one SPF record.
+	Duplicate struct {
+		Name    string
+		Records []string
+	}
+)
+
+// NewZoneResolver returns a resolver without zones.
+func NewZoneResolver(fallback Resolver) *ZoneResolver {
+	return &ZoneResolver{Fallback: fallback, zones: make(map[string][]*v2.RRSet)}
+}
+
+// AddZone makes the resolver answer lookups of the zone with the given rrsets.
+func (r *ZoneResolver) AddZone(name string, rrsets []*v2.RRSet) {
+	r.zones[rrconv.CanonicalName(name)] = rrsets
+}
+
+// LoadZone lists rrsets of the zone and adds them to the resolver.
+func (r *ZoneResolver) LoadZone(ctx context.Context, manager v2.RRSetManager[v2.RRSet], zone *v2.Zone) error {
+	rrsets, err := v2.ListAllRRSets[v2.RRSet](ctx, manager, zone.ID, nil)
+	if err != nil {
+		return fmt.Errorf("list rrsets of %s: %w", zone.Name, err)
+	}
+	r.AddZone(zone.Name, rrsets)
+
+	return nil
+}
+
+// LookupTXT returns TXT data of the name.
+func (r *ZoneResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	rrsets, ok := r.zoneOf(name)
+	if !ok {
+		if r.Fallback == nil {
+			return nil, notFound(name)
+		}
+
+		return r.Fallback.LookupTXT(ctx, name)
+	}
+	var txts []string
+	for _, content := range contents(rrsets, name, v2.TXT) {
+		txts = append(txts, v2.TXTData(content))
+	}
+	if len(txts) == 0 {
+		return nil, notFound(name)
+	}
+
+	return txts, nil
+}
+
+// LookupIPAddr returns IPv4 and IPv6 addresses of the host.
+func (r *ZoneResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	rrsets, ok := r.zoneOf(host)
+	if !ok {
+		if r.Fallback == nil {
+			return nil, notFound(host)
+		}
+
+		return r.Fallback.LookupIPAddr(ctx, host)
+	}
+	var addrs []net.IPAddr
+	for _, recordType := range []v2.RecordType{v2.A, v2.AAAA} {
+		for _, content := range contents(rrsets, host, recordType) {
+			if ip := net.ParseIP(content); ip != nil {
+				//nolint: exhaustruct
+				addrs = append(addrs, net.IPAddr{IP: ip})
+			}
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, notFound(host)
+	}
+
+	return addrs, nil
+}
+
+// LookupMX returns mail exchangers of the name ordered by preference.
+func (r *ZoneResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	rrsets, ok := r.zoneOf(name)
+	if !ok {
+		if r.Fallback == nil {
+			return nil, notFound(name)
+		}
+
+		return r.Fallback.LookupMX(ctx, name)
+	}
+	var mxs []*net.MX
+	for _, content := range contents(rrsets, name, v2.MX) {
+		rr, err := rrconv.ToRR(name, 0, v2.MX, content)
+		if err != nil {
+			return nil, err
+		}
+		mx, _ := rr.(*dns.MX)
+		mxs = append(mxs, &net.MX{Host: mx.Mx, Pref: mx.Preference})
+	}
+	if len(mxs) == 0 {
+		return nil, notFound(name)
+	}
+	sort.SliceStable(mxs, func(i, j int) bool { return mxs[i].Pref < mxs[j].Pref })
+
+	return mxs, nil
+}
+
+// zoneOf returns rrsets of the closest loaded zone containing the name.
+func (r *ZoneResolver) zoneOf(name string) ([]*v2.RRSet, bool) {
+	name = rrconv.CanonicalName(name)
+	best := ""
+	for zoneName := range r.zones {
+		if dns.IsSubDomain(zoneName, name) && len(zoneName) > len(best) {
+			best = zoneName
+		}
+	}
+	if best == "" {
+		return nil, false
+	}
+
+	return r.zones[best], true
+}
+
+// FindDuplicates returns names that have more than one enabled SPF record in the rrsets.
+// Such names make SPF evaluation fail with a permanent error.
+func FindDuplicates(rrsets []*v2.RRSet) []Duplicate {
+	records := make(map[string][]string)
+	var names []string
+	for _, rrset := range rrsets {
+		if rrset.Type != v2.TXT {
+			continue
+		}
+		name := rrconv.CanonicalName(rrset.Name)
+		for _, item := range rrset.Records {
+			if item.Disabled || !IsSPF(v2.TXTData(item.Content)) {
+				continue
+			}
+			if _, ok := records[name]; !ok {
+				names = append(names, name)
+			}
+			records[name] = append(records[name], v2.TXTData(item.Content))
+		}
+	}
+	var duplicates []Duplicate
+	for _, name := range names {
+		if len(records[name]) > 1 {
+			duplicates = append(duplicates, Duplicate{Name: name, Records: records[name]})
+		}
+	}
+
+	return duplicates
+}
+
+// Publish sets the SPF record of the name, replacing any SPF records of its TXT rrset
+// and keeping other TXT records. If ttl is zero, the TTL of the existing rrset is kept
+// and a created rrset gets defaultTTL.
+func Publish(
+	ctx context.Context, manager v2.RRSetManager[v2.RRSet], zoneID, name string, ttl int, record *Record,
+) (*v2.RRSet, error) {
+	//nolint: exhaustruct
+	rrset := &v2.RRSet{Name: name, Type: v2.TXT, TTL: ttl}
+	existing, err := v2.FindRRSet(ctx, manager, zoneID, name, v2.TXT)
+	switch {
+	case err == nil:
+		rrset.Comment, rrset.ManagedBy = existing.Comment, existing.ManagedBy
+		if rrset.TTL == 0 {
+			rrset.TTL = existing.TTL
+		}
+		for _, item := range existing.Records {
+			if !IsSPF(v2.TXTData(item.Content)) {
+				rrset.Records = append(rrset.Records, item)
+			}
+		}
+	case errors.Is(err, v2.ErrNotFound):
+		if rrset.TTL == 0 {
+			rrset.TTL = defaultTTL
+		}
+	default:
+		return nil, err
+	}
+	rrset.Records = append(rrset.Records, v2.RecordItem{Content: record.TXT(), Disabled: false})
+
+	return v2.UpsertFoundRRSet(ctx, manager, zoneID, existing, rrset)
+}
+
+func contents(rrsets []*v2.RRSet, name string, recordType v2.RecordType) []string {
+	name = rrconv.CanonicalName(name)
+	var result []string
+	for _, rrset := range rrsets {
+		if rrset.Type != recordType || rrconv.CanonicalName(rrset.Name) != name {
+			continue
+		}
+		for _, item := range rrset.Records {
+			if !item.Disabled {
+				result = append(result, item.Content)
+			}
+		}
+	}
+
+	return result
+}
+
+func notFound(name string) error {
+	//nolint: exhaustruct
+	return &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
@@ -0,0 +1,34 @@
+/*
+Package sshfp publishes SSHFP rrsets of the Selectel Domains API V2 from SSH
+host keys.
+
+OpenSSH public key files and known_hosts lines, such as the output of
+ssh-keyscan, are parsed into host keys of RSA, ECDSA and Ed25519 types.
+SHA-1 and SHA-256 fingerprints are computed from them, and Sync makes the
+SSHFP rrset of a host hold exactly the fingerprints of the given keys.
+
+Example of syncing fingerprints of host keys
+
+  keys, err := sshfp.ReadPublicKeys(
+    "/etc/ssh/ssh_host_ed25519_key.pub",
+    "/etc/ssh/ssh_host_ecdsa_key.pub",
+  )
+  if err != nil {
+    log.Fatal(err)
+  }
+  result, err := sshfp.Sync(ctx, client, zone.ID, "host.example.com.", keys, nil)
+  if err != nil {
+    log.Fatal(err)
+  }
+  log.Printf("added %d, removed %d fingerprints", len(result.Added), len(result.Removed))
+
+Example of reading keys collected by ssh-keyscan
+
+  file, err := os.Open("known_hosts")
+  if err != nil {
+    log.Fatal(err)
+  }
+  defer file.Close()
+  keys, err := sshfp.ParseKnownHosts(file)
+*/
+package sshfp
@@ -0,0 +1,215 @@
+package sshfp
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/miekg/dns"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/rrconv"
+)
+
+// Key algorithms of SSHFP records defined by RFC 4255, RFC 6594 and RFC 7479.
+const (
+	AlgorithmRSA     Algorithm = 1
+	AlgorithmECDSA   Algorithm = 3
+	AlgorithmEd25519 Algorithm = 4
+)
+
+// Fingerprint types of SSHFP records.
+const (
+	FingerprintSHA1   FingerprintType = 1
+	FingerprintSHA256 FingerprintType = 2
+)
+
+var (
+	ErrUnsupportedKey = errors.New("unsupported SSH host key")
+	ErrInvalidKey     = errors.New("invalid SSH public key")
+)
+
+type (
+	// Algorithm represents the public key algorithm of an SSHFP record.
+	Algorithm uint8
+
+	// FingerprintType represents the hash algorithm of an SSHFP record.
+	FingerprintType uint8
+
+	// HostKey represents an OpenSSH public host key.
+	HostKey struct {
+		// Type is the key type name, e.g. "ssh-ed25519".
+		Type      string
+		Algorithm Algorithm
+		// Blob holds the key in the SSH wire format fingerprints are computed of.
+		Blob    []byte
+		Comment string
+		// Hosts lists host patterns of a known_hosts line, it is empty for public key files.
+		Hosts []string
+	}
+
+	// Fingerprint represents an SSHFP record. Fields match the Algorithm, FingerprintType
+	// and Fingerprint fields of v1 SSHFP records.
+	Fingerprint struct {
+		Algorithm Algorithm
+		Type      FingerprintType
+		// Fingerprint holds the lowercase hexadecimal hash of the key.
+		Fingerprint string
+	}
+)
+
+// ParsePublicKey parses a line of an OpenSSH public key file like "ssh-ed25519 AAAA... root@host".
+func ParsePublicKey(line string) (*HostKey, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("%w: key type and key data expected", ErrInvalidKey)
+	}
+	algorithm, ok := algorithmOf(fields[0])
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedKey, fields[0])
+	}
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+	if blobType, ok := readString(blob); !ok || blobType != fields[0] {
+		return nil, fmt.Errorf("%w: key data doesn't hold a %s key", ErrInvalidKey, fields[0])
+	}
+
+	return &HostKey{
+		Type:      fields[0],
+		Algorithm: algorithm,
+		Blob:      blob,
+		Comment:   strings.Join(fields[2:], " "),
+		Hosts:     nil,
+	}, nil
+}
+
+// ParseKnownHosts parses lines in the known_hosts format, as printed by ssh-keyscan.
+// Keys of unsupported types, revoked keys and certificate authorities are skipped.
+func ParseKnownHosts(r io.Reader) ([]*HostKey, error) {
+	var keys []*HostKey
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, "@") {
+			continue
+		}
+		fields := strings.Fields(text)
+		key, err := ParsePublicKey(strings.Join(fields[1:], " "))
+		if errors.Is(err, ErrUnsupportedKey) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		key.Hosts = strings.Split(fields[0], ",")
+		keys = append(keys, key)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read known hosts: %w", err)
+	}
+
+	return keys, nil
+}
+
+// ReadPublicKeys reads OpenSSH public key files such as /etc/ssh/ssh_host_ed25519_key.pub.
+func ReadPublicKeys(paths ...string) ([]*HostKey, error) {
+	keys := make([]*HostKey, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read public key: %w", err)
+		}
+		key, err := ParsePublicKey(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// Fingerprint returns the fingerprint of the key of the given type.
+func (k *HostKey) Fingerprint(fingerprintType FingerprintType) (Fingerprint, error) {
+	var sum []byte
+	switch fingerprintType {
+	case FingerprintSHA1:
+		hash := sha1.Sum(k.Blob)
+		sum = hash[:]
+	case FingerprintSHA256:
+		hash := sha256.Sum256(k.Blob)
+		sum = hash[:]
+	default:
+		return Fingerprint{Algorithm: 0, Type: 0, Fingerprint: ""},
+			fmt.Errorf("%w: fingerprint type %d", ErrUnsupportedKey, fingerprintType)
+	}
+
+	return Fingerprint{Algorithm: k.Algorithm, Type: fingerprintType, Fingerprint: hex.EncodeToString(sum)}, nil
+}
+
+// Fingerprints returns SHA-1 and SHA-256 fingerprints of the key.
+func (k *HostKey) Fingerprints() []Fingerprint {
+	fingerprints := make([]Fingerprint, 0, 2)
+	for _, fingerprintType := range []FingerprintType{FingerprintSHA1, FingerprintSHA256} {
+		fingerprint, _ := k.Fingerprint(fingerprintType)
+		fingerprints = append(fingerprints, fingerprint)
+	}
+
+	return fingerprints
+}
+
+// ParseFingerprint parses SSHFP rrset content like "4 2 193557d5...".
+func ParseFingerprint(content string) (Fingerprint, error) {
+	rr, err := rrconv.ToRR(".", 0, v2.SSHFP, content)
+	if err != nil {
+		return Fingerprint{Algorithm: 0, Type: 0, Fingerprint: ""}, fmt.Errorf("parse SSHFP: %w", err)
+	}
+	sshfp, _ := rr.(*dns.SSHFP)
+
+	return Fingerprint{
+		Algorithm:   Algorithm(sshfp.Algorithm),
+		Type:        FingerprintType(sshfp.Type),
+		Fingerprint: strings.ToLower(sshfp.FingerPrint),
+	}, nil
+}
+
+// Content returns the fingerprint as SSHFP rrset content.
+func (f Fingerprint) Content() string {
+	return fmt.Sprintf("%d %d %s", f.Algorithm, f.Type, f.Fingerprint)
+}
+
+func algorithmOf(keyType string) (Algorithm, bool) {
+	switch keyType {
+	case "ssh-rsa":
+		return AlgorithmRSA, true
+	case "ecdsa-sha2-nistp256", "ecdsa-sha2-nistp384", "ecdsa-sha2-nistp521":
+		return AlgorithmECDSA, true
+	case "ssh-ed25519":
+		return AlgorithmEd25519, true
+	}
+
+	return 0, false
+}
+
+// readString reads the length-prefixed string the key blob starts with.
+func readString(data []byte) (string, bool) {
+	const lengthSize = 4
+	if len(data) < lengthSize {
+		return "", false
+	}
+	length := binary.BigEndian.Uint32(data)
+	if uint64(len(data)-lengthSize) < uint64(length) {
+		return "", false
+	}
+
+	return string(data[lengthSize : lengthSize+int(length)]), true
+}
@@ -0,0 +1,146 @@
+package sshfp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	v2 "github.com/selectel/domains-go/pkg/v2"
+)
+
+// defaultTTL represents the default TTL of created SSHFP rrsets.
+const defaultTTL = 3600
+
+type (
+	// SyncOpts represents options of a sync.
+	SyncOpts struct {
+		// TTL of the rrset. If zero, an existing rrset keeps its TTL and a created one gets defaultTTL.
+		TTL int
+
+		// Types lists fingerprint types to publish. If empty, SHA-1 and SHA-256 fingerprints are published.
+		Types []FingerprintType
+	}
+
+	// SyncResult describes changes made by a sync.
+	SyncResult struct {
+		// RRSet is the synced rrset, it is nil if the rrset was deleted or there were no keys.
+		RRSet   *v2.RRSet
+		Added   []Fingerprint
+		Removed []Fingerprint
+	}
+)
+
+// Changed reports whether the sync changed the zone.
+func (r *SyncResult) Changed() bool {
+	return len(r.Added) > 0 || len(r.Removed) > 0
+}
+
+// Sync makes the SSHFP rrset of the host hold fingerprints of exactly the given keys:
+// fingerprints of new keys are added and fingerprints of keys no longer present are removed.
+// The rrset is deleted when no keys are given.
+func Sync(
+	ctx context.Context, manager v2.RRSetManager[v2.RRSet], zoneID, hostname string, keys []*HostKey, opts *SyncOpts,
+) (*SyncResult, error) {
+	if opts == nil {
+		opts = &SyncOpts{TTL: 0, Types: nil}
+	}
+	desired, err := fingerprints(keys, opts.Types)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := v2.FindRRSet(ctx, manager, zoneID, hostname, v2.SSHFP)
+	if err != nil && !errors.Is(err, v2.ErrNotFound) {
+		return nil, err
+	}
+	current := make(map[string]Fingerprint)
+	if existing != nil {
+		for _, item := range existing.Records {
+			fingerprint, err := ParseFingerprint(item.Content)
+			if err != nil {
+				return nil, err
+			}
+			current[fingerprint.Content()] = fingerprint
+		}
+	}
+	result := diff(current, desired)
+	result.RRSet = existing
+
+	switch {
+	case existing == nil && len(desired) == 0:
+		return result, nil
+	case existing != nil && len(desired) == 0:
+		if err := manager.DeleteRRSet(ctx, zoneID, existing.ID); err != nil {
+			return nil, fmt.Errorf("delete rrset: %w", err)
+		}
+		result.RRSet = nil
+
+		return result, nil
+	case existing != nil && !result.Changed() && (opts.TTL == 0 || opts.TTL == existing.TTL):
+		return result, nil
+	}
+
+	//nolint: exhaustruct
+	rrset := &v2.RRSet{Name: hostname, Type: v2.SSHFP, TTL: opts.TTL}
+	if existing != nil {
+		rrset.Comment, rrset.ManagedBy = existing.Comment, existing.ManagedBy
+		if rrset.TTL == 0 {
+			rrset.TTL = existing.TTL
+		}
+	} else if rrset.TTL == 0 {
+		rrset.TTL = defaultTTL
+	}
+	for _, fingerprint := range desired {
+		rrset.Records = append(rrset.Records, v2.RecordItem{Content: fingerprint.Content(), Disabled: false})
+	}
+	result.RRSet, err = v2.UpsertFoundRRSet(ctx, manager, zoneID, existing, rrset)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// fingerprints returns sorted unique fingerprints of the keys.
+func fingerprints(keys []*HostKey, types []FingerprintType) ([]Fingerprint, error) {
+	if len(types) == 0 {
+		types = []FingerprintType{FingerprintSHA1, FingerprintSHA256}
+	}
+	seen := make(map[string]bool)
+	var result []Fingerprint
+	for _, key := range keys {
+		for _, fingerprintType := range types {
+			fingerprint, err := key.Fingerprint(fingerprintType)
+			if err != nil {
+				return nil, err
+			}
+			if seen[fingerprint.Content()] {
+				continue
+			}
+			seen[fingerprint.Content()] = true
+			result = append(result, fingerprint)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Content() < result[j].Content() })
+
+	return result, nil
+}
+
+func diff(current map[string]Fingerprint, desired []Fingerprint) *SyncResult {
+	result := &SyncResult{RRSet: nil, Added: nil, Removed: nil}
+	wanted := make(map[string]bool, len(desired))
+	for _, fingerprint := range desired {
+		wanted[fingerprint.Content()] = true
+		if _, ok := current[fingerprint.Content()]; !ok {
+			result.Added = append(result.Added, fingerprint)
+		}
+	}
+	for content, fingerprint := range current {
+		if !wanted[content] {
+			result.Removed = append(result.Removed, fingerprint)
+		}
+	}
+	sort.Slice(result.Removed, func(i, j int) bool { return result.Removed[i].Content() < result.Removed[j].Content() })
+
+	return result
+}
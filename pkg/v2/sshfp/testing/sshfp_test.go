@@ -0,0 +1,180 @@
+package testing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/selectel/domains-go/pkg/testutils"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/sshfp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testZoneName = "bonnie-test.com."
+	testHostname = "host.bonnie-test.com."
+
+	testEd25519Key = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEPH6wQ7WYRGBM3d164lRQzp1i+hp9QZC35KIyTTYCf4 root@bonnie"
+	testECDSAKey   = "ecdsa-sha2-nistp256 AAAAE2VjZHNhLXNoYTItbmlzdHAyNTYAAAAIbmlzdHAyNTYAAABBBCVAmCwezyx49K8WzRJO" +
+		"uvWaX4g6XqtkqYyEpubFnNcwwvdHRlzh9rTs8OsKEmqdFdf2v5Cy6/W8bDpzZE+jC1I= root@bonnie"
+	testRSAKey = "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAAgQCg1gagryniILbxpjoJQusIeVz4+TBNpy7rrXgDHElpGbti5Sj2tllZa4E2" +
+		"/7lsjgYYIsLkXzi1zeOZGPjh/X13YrN//BEhhcyok9QIby3IBlZu3fqGuBONDTbgW0RU0DUL1FozQ3Smaj6JZ3S8gieOuSP6nxqF" +
+		"MCGBF9wqDHIp+w== root@bonnie"
+)
+
+// Fingerprints as printed by ssh-keygen -r.
+var (
+	ed25519Fingerprints = []string{
+		"4 1 30c949973ff094dc2a24f4207e7ef6a72e007dd2",
+		"4 2 193557d59682f8ed2d0b696bf92937f82056a28868eb5aa4f3f1ccbc1137426f",
+	}
+	ecdsaFingerprints = []string{
+		"3 1 24f5644f26d10f9eab170976d265b89ac88151de",
+		"3 2 6ea5763be9e511202c1465a5a130301556243db76993435fac88647bc460277d",
+	}
+	rsaFingerprints = []string{
+		"1 1 ac7d5b0cb2fa7e64ef471105790511634b3de32a",
+		"1 2 3dd1e18562ff5fdc986a25f2a370f04ec041786259a8f6d68f8a3a525d2dfad1",
+	}
+)
+
+func contents(fingerprints []sshfp.Fingerprint) []string {
+	result := make([]string, 0, len(fingerprints))
+	for _, fingerprint := range fingerprints {
+		result = append(result, fingerprint.Content())
+	}
+
+	return result
+}
+
+func parseKeys(t *testing.T, lines ...string) []*sshfp.HostKey {
+	t.Helper()
+	keys := make([]*sshfp.HostKey, 0, len(lines))
+	for _, line := range lines {
+		key, err := sshfp.ParsePublicKey(line)
+		require.NoError(t, err)
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func TestFingerprints(t *testing.T) {
+	t.Parallel()
+	for line, expected := range map[string][]string{
+		testEd25519Key: ed25519Fingerprints,
+		testECDSAKey:   ecdsaFingerprints,
+		testRSAKey:     rsaFingerprints,
+	} {
+		key, err := sshfp.ParsePublicKey(line)
+
+		require.NoError(t, err)
+		assert.Equal(t, "root@bonnie", key.Comment)
+		assert.Equal(t, expected, contents(key.Fingerprints()))
+	}
+}
+
+func TestParsePublicKey_errors(t *testing.T) {
+	t.Parallel()
+	_, err := sshfp.ParsePublicKey("ssh-dss AAAAB3NzaC1kc3M=")
+	require.ErrorIs(t, err, sshfp.ErrUnsupportedKey)
+
+	for _, line := range []string{"ssh-ed25519", "ssh-ed25519 !!!", strings.Replace(testRSAKey, "ssh-rsa", "ssh-ed25519", 1)} {
+		_, err := sshfp.ParsePublicKey(line)
+		assert.ErrorIs(t, err, sshfp.ErrInvalidKey, line)
+	}
+}
+
+func TestParseKnownHosts(t *testing.T) {
+	t.Parallel()
+	data := "# host.bonnie-test.com:22 SSH-2.0-OpenSSH_9.6\n" +
+		"host.bonnie-test.com,192.0.2.1 " + testEd25519Key + "\n" +
+		"\n" +
+		"@revoked * " + testRSAKey + "\n" +
+		"[host.bonnie-test.com]:2222\t" + testECDSAKey + "\n" +
+		"host.bonnie-test.com ssh-dss AAAAB3NzaC1kc3M=\n"
+
+	keys, err := sshfp.ParseKnownHosts(strings.NewReader(data))
+
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, []string{"host.bonnie-test.com", "192.0.2.1"}, keys[0].Hosts)
+	assert.Equal(t, sshfp.AlgorithmEd25519, keys[0].Algorithm)
+	assert.Equal(t, []string{"[host.bonnie-test.com]:2222"}, keys[1].Hosts)
+	assert.Equal(t, sshfp.AlgorithmECDSA, keys[1].Algorithm)
+}
+
+func TestReadPublicKeys(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "ssh_host_ed25519_key.pub")
+	require.NoError(t, os.WriteFile(path, []byte(testEd25519Key+"\n"), 0o600))
+
+	keys, err := sshfp.ReadPublicKeys(path)
+
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "ssh-ed25519", keys[0].Type)
+}
+
+func TestSync(t *testing.T) {
+	t.Parallel()
+	api := testutils.NewFakeAPI()
+	defer api.Close()
+	zone := api.AddZone(testZoneName)
+	ctx := context.Background()
+
+	result, err := sshfp.Sync(ctx, api.Client(), zone.ID, testHostname, parseKeys(t, testRSAKey, testEd25519Key), nil)
+
+	require.NoError(t, err)
+	assert.Len(t, result.Added, 4)
+	assert.Equal(t, 3600, result.RRSet.TTL)
+
+	// The RSA key is replaced with an ECDSA key.
+	requests := len(api.Requests())
+	result, err = sshfp.Sync(ctx, api.Client(), zone.ID, testHostname, parseKeys(t, testEd25519Key, testECDSAKey), nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"GET /zones/" + zone.ID + "/rrset",
+		"PATCH /zones/" + zone.ID + "/rrset/" + result.RRSet.ID,
+	}, api.Requests()[requests:])
+	assert.Equal(t, ecdsaFingerprints, contents(result.Added))
+	assert.Equal(t, rsaFingerprints, contents(result.Removed))
+	rrsets := api.RRSets(zone.ID)
+	require.Len(t, rrsets, 1)
+	records := make([]string, 0, len(rrsets[0].Records))
+	for _, item := range rrsets[0].Records {
+		records = append(records, item.Content)
+	}
+	assert.ElementsMatch(t, append(append([]string(nil), ed25519Fingerprints...), ecdsaFingerprints...), records)
+
+	result, err = sshfp.Sync(ctx, api.Client(), zone.ID, testHostname, parseKeys(t, testECDSAKey, testEd25519Key), nil)
+
+	require.NoError(t, err)
+	assert.False(t, result.Changed())
+
+	result, err = sshfp.Sync(ctx, api.Client(), zone.ID, testHostname, nil, nil)
+
+	require.NoError(t, err)
+	assert.Len(t, result.Removed, 4)
+	assert.Nil(t, result.RRSet)
+	assert.Empty(t, api.RRSets(zone.ID))
+}
+
+func TestSync_types(t *testing.T) {
+	t.Parallel()
+	api := testutils.NewFakeAPI()
+	defer api.Close()
+	zone := api.AddZone(testZoneName)
+	opts := &sshfp.SyncOpts{TTL: 300, Types: []sshfp.FingerprintType{sshfp.FingerprintSHA256}}
+
+	result, err := sshfp.Sync(context.Background(), api.Client(), zone.ID, testHostname, parseKeys(t, testEd25519Key), opts)
+
+	require.NoError(t, err)
+	assert.Equal(t, 300, result.RRSet.TTL)
+	assert.Equal(t, []v2.RecordItem{{Content: ed25519Fingerprints[1], Disabled: false}}, api.RRSets(zone.ID)[0].Records)
+}
@@ -0,0 +1,106 @@
+package testing
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/selectel/domains-go/pkg/idn"
+	"github.com/selectel/domains-go/pkg/testutils"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeForm(t *testing.T, form io.Reader) map[string]interface{} {
+	t.Helper()
+	var decoded map[string]interface{}
+	require.NoError(t, json.NewDecoder(form).Decode(&decoded))
+
+	return decoded
+}
+
+func TestZone_idn(t *testing.T) {
+	t.Parallel()
+	//nolint: exhaustruct
+	zone := &v2.Zone{Name: "Пример.РФ."}
+
+	form, err := zone.CreationForm()
+
+	require.NoError(t, err)
+	assert.Equal(t, "xn--e1afmkfd.xn--p1ai.", decodeForm(t, form)["name"])
+	//nolint: exhaustruct
+	assert.Equal(t, "пример.рф.", (&v2.Zone{Name: "xn--e1afmkfd.xn--p1ai."}).UnicodeName())
+
+	//nolint: exhaustruct
+	_, err = (&v2.Zone{Name: "☃.example.com."}).CreationForm()
+	assert.ErrorIs(t, err, idn.ErrInvalidName)
+}
+
+func TestRRSet_idn(t *testing.T) {
+	t.Parallel()
+	//nolint: exhaustruct
+	rrset := &v2.RRSet{
+		Name: "почта.пример.рф.",
+		Type: v2.MX,
+		TTL:  testTTL,
+		Records: []v2.RecordItem{
+			{Content: "10 mx.пример.рф.", Disabled: false},
+			{Content: "20 mx.example.com.", Disabled: true},
+		},
+	}
+
+	form, err := rrset.CreationForm()
+
+	require.NoError(t, err)
+	decoded := decodeForm(t, form)
+	assert.Equal(t, "xn--80a1acny.xn--e1afmkfd.xn--p1ai.", decoded["name"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"content": "10 mx.xn--e1afmkfd.xn--p1ai.", "disabled": false},
+		map[string]interface{}{"content": "20 mx.example.com.", "disabled": true},
+	}, decoded["records"])
+	assert.Equal(t, "10 mx.пример.рф.", rrset.Records[0].Content)
+
+	form, err = rrset.UpdateForm()
+	require.NoError(t, err)
+	assert.Equal(t, "10 mx.xn--e1afmkfd.xn--p1ai.",
+		decodeForm(t, form)["records"].([]interface{})[0].(map[string]interface{})["content"])
+
+	//nolint: exhaustruct
+	fetched := &v2.RRSet{
+		Name:    "xn--80a1acny.xn--e1afmkfd.xn--p1ai.",
+		Type:    v2.MX,
+		Records: []v2.RecordItem{{Content: "10 mx.xn--e1afmkfd.xn--p1ai.", Disabled: false}},
+	}
+	assert.Equal(t, "почта.пример.рф.", fetched.UnicodeName())
+	assert.Equal(t, []string{"10 mx.пример.рф."}, fetched.UnicodeContents())
+
+	rrset.Records[0].Content = "10 ☃.example.com."
+	_, err = rrset.UpdateForm()
+	assert.ErrorIs(t, err, idn.ErrInvalidName)
+}
+
+func TestListFilters_idn(t *testing.T) {
+	t.Parallel()
+	api := testutils.NewFakeAPI()
+	defer api.Close()
+	zone := api.AddZone("xn--e1afmkfd.xn--p1ai.")
+	//nolint: exhaustruct
+	api.AddRRSet(zone.ID, v2.RRSet{Name: "xn--80a1acny.xn--e1afmkfd.xn--p1ai.", Type: v2.A, TTL: testTTL})
+
+	zones, err := api.Client().ListZones(testCtx, &map[string]string{"filter": "пример.рф"})
+
+	require.NoError(t, err)
+	require.Equal(t, 1, zones.GetCount())
+	assert.Equal(t, "пример.рф.", zones.GetItems()[0].UnicodeName())
+
+	options := map[string]string{"name": "почта.пример.рф."}
+	rrsets, err := api.Client().ListRRSets(testCtx, zone.ID, &options)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, rrsets.GetCount())
+	assert.Equal(t, "почта.пример.рф.", options["name"])
+
+	_, err = api.Client().ListZones(testCtx, &map[string]string{"filter": "☃"})
+	assert.ErrorIs(t, err, idn.ErrInvalidName)
+}
@@ -0,0 +1,100 @@
+package testing
+
+import (
+	"testing"
+
+	"github.com/selectel/domains-go/pkg/testutils"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "www.example.com.", v2.NormalizeName("WWW.Example.com"))
+	assert.Equal(t, "www.example.com.", v2.NormalizeName("www.example.com.."))
+	assert.Equal(t, "www.xn--e1afmkfd.xn--p1ai.", v2.NormalizeName("www.Пример.рф"))
+}
+
+func TestZone_names(t *testing.T) {
+	t.Parallel()
+	//nolint: exhaustruct
+	zone := &v2.Zone{Name: "example.com."}
+	fqdn := map[string]string{
+		"":                   "example.com.",
+		"@":                  "example.com.",
+		"www":                "www.example.com.",
+		"WWW.Example.COM":    "www.example.com.",
+		"www.example.com.":   "www.example.com.",
+		"_sip._tcp":          "_sip._tcp.example.com.",
+		"www.example.org.":   "www.example.org.",
+		"www.example.org":    "www.example.org.example.com.",
+		"notexample.com":     "notexample.com.example.com.",
+		"*.apps.example.com": "*.apps.example.com.",
+	}
+	for name, expected := range fqdn {
+		assert.Equal(t, expected, zone.FQDN(name), name)
+	}
+
+	assert.True(t, zone.Contains("example.com"))
+	assert.True(t, zone.Contains("a.b.EXAMPLE.com."))
+	assert.False(t, zone.Contains("notexample.com."))
+
+	relative, err := zone.RelativeName("www.Example.com.")
+	require.NoError(t, err)
+	assert.Equal(t, "www", relative)
+	relative, err = zone.RelativeName("example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "@", relative)
+	_, err = zone.RelativeName("www.example.org.")
+	assert.ErrorIs(t, err, v2.ErrNameOutsideZone)
+}
+
+func TestClient_WithRelativeNames(t *testing.T) {
+	t.Parallel()
+	api := testutils.NewFakeAPI()
+	defer api.Close()
+	zone := api.AddZone("example.com.")
+	client, ok := api.Client().(*v2.Client)
+	require.True(t, ok)
+	relativeClient := client.WithRelativeNames()
+
+	//nolint: exhaustruct
+	created, err := relativeClient.CreateRRSet(testCtx, zone.ID, &v2.RRSet{
+		Name:    "www",
+		Type:    v2.A,
+		TTL:     testTTL,
+		Records: []v2.RecordItem{{Content: testIPv4, Disabled: false}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "www.example.com.", created.Name)
+
+	//nolint: exhaustruct
+	_, err = relativeClient.CreateRRSet(testCtx, zone.ID, &v2.RRSet{
+		Name:    "@",
+		Type:    v2.TXT,
+		TTL:     testTTL,
+		Records: []v2.RecordItem{{Content: `"apex"`, Disabled: false}},
+	})
+	require.NoError(t, err)
+
+	for name, expected := range map[string]string{"www": "www.example.com.", "@": "example.com."} {
+		rrsets, err := relativeClient.ListRRSets(testCtx, zone.ID, &map[string]string{"name": name})
+
+		require.NoError(t, err)
+		require.Equal(t, 1, rrsets.GetCount(), name)
+		assert.Equal(t, expected, rrsets.GetItems()[0].Name)
+	}
+
+	rrsets, err := client.ListRRSets(testCtx, zone.ID, &map[string]string{"name": "www"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, rrsets.GetCount())
+
+	zoneRequests := 0
+	for _, request := range api.Requests() {
+		if request == "GET /zones/"+zone.ID {
+			zoneRequests++
+		}
+	}
+	assert.Equal(t, 1, zoneRequests)
+}
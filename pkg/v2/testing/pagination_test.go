@@ -0,0 +1,52 @@
+package testing
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/selectel/domains-go/pkg/testutils"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAll_follows_next_offset(t *testing.T) {
+	t.Parallel()
+	api := testutils.NewFakeAPI()
+	defer api.Close()
+	api.PageSize = 2
+	zone := api.AddZone(testDomainName)
+	for i := 0; i < 5; i++ {
+		api.AddZone(fmt.Sprintf("zone-%d.com.", i))
+		//nolint: exhaustruct
+		api.AddRRSet(zone.ID, v2.RRSet{
+			Name:    fmt.Sprintf("host-%d.%s", i, testDomainName),
+			Type:    v2.A,
+			TTL:     testTTL,
+			Records: []v2.RecordItem{{Content: testIPv4, Disabled: false}},
+		})
+	}
+	client := api.Client()
+
+	zones, err := v2.ListAllZones[v2.Zone](testCtx, client, nil)
+	require.NoError(t, err)
+	assert.Len(t, zones, 6)
+
+	rrsets, err := v2.ListAllRRSets[v2.RRSet](testCtx, client, zone.ID, nil)
+	require.NoError(t, err)
+	require.Len(t, rrsets, 5)
+	assert.Equal(t, "host-4."+testDomainName, rrsets[4].Name)
+}
+
+func TestListAll_returns_error(t *testing.T) {
+	t.Parallel()
+	api := testutils.NewFakeAPI()
+	defer api.Close()
+	api.InjectFault(func(_ *http.Request) int { return http.StatusInternalServerError })
+
+	zones, err := v2.ListAllZones[v2.Zone](testCtx, api.Client(), nil)
+
+	assert.Nil(t, zones)
+	assert.Error(t, err)
+}
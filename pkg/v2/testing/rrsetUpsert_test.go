@@ -0,0 +1,85 @@
+package testing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/selectel/domains-go/pkg/testutils"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindRRSet(t *testing.T) {
+	t.Parallel()
+	api := testutils.NewFakeAPI()
+	defer api.Close()
+	zone := api.AddZone(testDomainName)
+	created := newVersionedRRSet(api, zone.ID, testIPv4)
+
+	rrset, err := v2.FindRRSet(testCtx, api.Client(), zone.ID, strings.ToUpper(created.Name), v2.A)
+
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, rrset.ID)
+
+	_, err = v2.FindRRSet(testCtx, api.Client(), zone.ID, created.Name, v2.AAAA)
+
+	assert.ErrorIs(t, err, v2.ErrNotFound)
+}
+
+func TestUpsertRRSet(t *testing.T) {
+	t.Parallel()
+	api := testutils.NewFakeAPI()
+	defer api.Close()
+	zone := api.AddZone(testDomainName)
+	//nolint: exhaustruct
+	rrset := &v2.RRSet{
+		Name:    "www." + testDomainName,
+		Type:    v2.A,
+		TTL:     testTTL,
+		Records: []v2.RecordItem{{Content: "10.0.0.1", Disabled: false}},
+	}
+
+	created, err := v2.UpsertRRSet(testCtx, api.Client(), zone.ID, rrset)
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+
+	rrset.Records = []v2.RecordItem{{Content: "10.0.0.2", Disabled: false}}
+	updated, err := v2.UpsertRRSet(testCtx, api.Client(), zone.ID, rrset)
+
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, updated.ID)
+	rrsets := api.RRSets(zone.ID)
+	require.Len(t, rrsets, 1)
+	assert.Equal(t, "10.0.0.2", rrsets[0].Records[0].Content)
+}
+
+func TestUpsertFoundRRSet(t *testing.T) {
+	t.Parallel()
+	api := testutils.NewFakeAPI()
+	defer api.Close()
+	zone := api.AddZone(testDomainName)
+	//nolint: exhaustruct
+	rrset := &v2.RRSet{
+		Name:    "www." + testDomainName,
+		Type:    v2.A,
+		TTL:     testTTL,
+		Records: []v2.RecordItem{{Content: "10.0.0.1", Disabled: false}},
+	}
+
+	created, err := v2.UpsertFoundRRSet(testCtx, api.Client(), zone.ID, nil, rrset)
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+
+	rrset.Records = []v2.RecordItem{{Content: "10.0.0.2", Disabled: false}}
+	updated, err := v2.UpsertFoundRRSet(testCtx, api.Client(), zone.ID, created, rrset)
+
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, updated.ID)
+	assert.Equal(t, []string{
+		"POST /zones/" + zone.ID + "/rrset",
+		"PATCH /zones/" + zone.ID + "/rrset/" + created.ID,
+	}, api.Requests())
+}
@@ -0,0 +1,120 @@
+package testing
+
+import (
+	"testing"
+
+	"github.com/selectel/domains-go/pkg/testutils"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newVersionedRRSet(api *testutils.FakeAPI, zoneID string, contents ...string) *v2.RRSet {
+	//nolint: exhaustruct
+	rrset := v2.RRSet{Name: "www." + testDomainName, Type: v2.A, TTL: testTTL}
+	for _, content := range contents {
+		rrset.Records = append(rrset.Records, v2.RecordItem{Content: content, Disabled: false})
+	}
+
+	return api.AddRRSet(zoneID, rrset)
+}
+
+func TestRRSetVersion(t *testing.T) {
+	t.Parallel()
+	//nolint: exhaustruct
+	rrset := v2.RRSet{
+		ID:  testID,
+		TTL: testTTL,
+		Records: []v2.RecordItem{
+			{Content: "10.0.0.1", Disabled: false},
+			{Content: "10.0.0.2", Disabled: false},
+		},
+	}
+	reordered := rrset
+	reordered.ID = "another-id"
+	reordered.Records = []v2.RecordItem{rrset.Records[1], rrset.Records[0]}
+	changed := rrset
+	changed.TTL = testTTL + 1
+
+	assert.Len(t, rrset.Version(), 64)
+	assert.Equal(t, rrset.Version(), reordered.Version())
+	assert.NotEqual(t, rrset.Version(), changed.Version())
+}
+
+func TestUpdateRRSetIfUnchanged(t *testing.T) {
+	t.Parallel()
+	api := testutils.NewFakeAPI()
+	defer api.Close()
+	zone := api.AddZone(testDomainName)
+	rrset := newVersionedRRSet(api, zone.ID, "10.0.0.1")
+	client := api.Client()
+	version := rrset.Version()
+
+	desired := *rrset
+	desired.Records = []v2.RecordItem{{Content: "10.0.0.2", Disabled: false}}
+	err := v2.UpdateRRSetIfUnchanged(testCtx, client, zone.ID, rrset.ID, version, &desired)
+	require.NoError(t, err)
+
+	// The version read before the first update is stale now.
+	desired.Records = []v2.RecordItem{{Content: "10.0.0.3", Disabled: false}}
+	err = v2.UpdateRRSetIfUnchanged(testCtx, client, zone.ID, rrset.ID, version, &desired)
+
+	require.ErrorIs(t, err, v2.ErrConflict)
+	var conflict *v2.ConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, version, conflict.Expected)
+	assert.Equal(t, "10.0.0.2", conflict.Current.Records[0].Content)
+	assert.Equal(t, "10.0.0.2", api.RRSets(zone.ID)[0].Records[0].Content)
+}
+
+func TestUpdateRRSetWithMerge_retries_on_conflict(t *testing.T) {
+	t.Parallel()
+	api := testutils.NewFakeAPI()
+	defer api.Close()
+	zone := api.AddZone(testDomainName)
+	rrset := newVersionedRRSet(api, zone.ID, "10.0.0.1")
+	client := api.Client()
+
+	calls := 0
+	err := v2.UpdateRRSetWithMerge(testCtx, client, zone.ID, rrset.ID, 0, func(current *v2.RRSet) (v2.Updatable, error) {
+		calls++
+		if calls == 1 {
+			// Another writer adds a record between the read and the update.
+			concurrent := *current
+			concurrent.Records = append(concurrent.Records, v2.RecordItem{Content: "10.0.0.2", Disabled: false})
+			require.NoError(t, client.UpdateRRSet(testCtx, zone.ID, rrset.ID, &concurrent))
+		}
+		desired := *current
+		desired.Records = append(append([]v2.RecordItem(nil), current.Records...), v2.RecordItem{
+			Content: "10.0.0.3", Disabled: false,
+		})
+
+		return &desired, nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Len(t, api.RRSets(zone.ID)[0].Records, 3)
+}
+
+func TestUpdateRRSetWithMerge_gives_up(t *testing.T) {
+	t.Parallel()
+	api := testutils.NewFakeAPI()
+	defer api.Close()
+	zone := api.AddZone(testDomainName)
+	rrset := newVersionedRRSet(api, zone.ID, "10.0.0.1")
+	client := api.Client()
+
+	calls := 0
+	err := v2.UpdateRRSetWithMerge(testCtx, client, zone.ID, rrset.ID, 2, func(current *v2.RRSet) (v2.Updatable, error) {
+		calls++
+		concurrent := *current
+		concurrent.TTL += 60
+		require.NoError(t, client.UpdateRRSet(testCtx, zone.ID, rrset.ID, &concurrent))
+
+		return current, nil
+	})
+
+	require.ErrorIs(t, err, v2.ErrConflict)
+	assert.Equal(t, 2, calls)
+}
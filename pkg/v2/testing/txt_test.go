@@ -0,0 +1,97 @@
+package testing
+
+import (
+	"strings"
+	"testing"
+
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeTXT(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, `"v=spf1 -all"`, v2.EncodeTXT("v=spf1 -all"))
+	assert.Equal(t, `"say \"hi\" \\ bye\008"`, v2.EncodeTXT("say \"hi\" \\ bye\b"))
+	assert.Equal(t, `""`, v2.EncodeTXT(""))
+
+	long := strings.Repeat("a", 300)
+	assert.Equal(t, `"`+strings.Repeat("a", 255)+`" "`+strings.Repeat("a", 45)+`"`, v2.EncodeTXT(long))
+
+	// Chunks end before the two bytes of "ж" instead of splitting it.
+	unicode := strings.Repeat("a", 254) + "ж"
+	chunks, err := v2.SplitTXT(v2.EncodeTXT(unicode))
+	require.NoError(t, err)
+	assert.Equal(t, []string{strings.Repeat("a", 254), "ж"}, chunks)
+}
+
+func TestDecodeTXT(t *testing.T) {
+	t.Parallel()
+	cases := map[string]string{
+		`"v=DKIM1; " "p=MIIB"`: "v=DKIM1; p=MIIB",
+		`"a\"b\\c" "\065\066"`: `a"b\cAB`,
+		`bare words`:           "barewords",
+		`  "padded"	"tabs"  `:  "paddedtabs",
+		`""`:                   "",
+	}
+	for content, expected := range cases {
+		actual, err := v2.DecodeTXT(content)
+
+		require.NoError(t, err, content)
+		assert.Equal(t, expected, actual, content)
+	}
+
+	value := strings.Repeat(`x"\`, 200)
+	decoded, err := v2.NewTXTRecordItem(value).TXTValue()
+	require.NoError(t, err)
+	assert.Equal(t, value, decoded)
+}
+
+func TestTXTData(t *testing.T) {
+	t.Parallel()
+	cases := map[string]string{
+		` "v=spf1 " "-all" `: "v=spf1 -all",
+		`v=spf1 -all`:        "v=spf1 -all",
+		`"unterminated`:      `"unterminated`,
+	}
+	for content, expected := range cases {
+		assert.Equal(t, expected, v2.TXTData(content), content)
+	}
+}
+
+func TestValidateTXT(t *testing.T) {
+	t.Parallel()
+	for _, content := range []string{
+		`"unterminated`,
+		`"dangling\`,
+		`"bad \12x escape"`,
+		`"out of range \256"`,
+		`"no"space`,
+		`mid"quote`,
+		`   `,
+		`"` + strings.Repeat("a", 256) + `"`,
+	} {
+		assert.ErrorIs(t, v2.ValidateTXT(content), v2.ErrInvalidTXT, content)
+	}
+	assert.NoError(t, v2.ValidateTXT(`"`+strings.Repeat("a", 255)+`"`))
+}
+
+func TestRRSet_invalidTXT(t *testing.T) {
+	t.Parallel()
+	//nolint: exhaustruct
+	rrset := &v2.RRSet{
+		Name:    "www.example.com.",
+		Type:    v2.TXT,
+		TTL:     testTTL,
+		Records: []v2.RecordItem{v2.NewTXTRecordItem("ok"), {Content: `"broken`, Disabled: false}},
+	}
+
+	_, err := rrset.CreationForm()
+	assert.ErrorIs(t, err, v2.ErrInvalidTXT)
+	_, err = rrset.UpdateForm()
+	assert.ErrorIs(t, err, v2.ErrInvalidTXT)
+
+	rrset.Records = rrset.Records[:1]
+	_, err = rrset.CreationForm()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,125 @@
+package testing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/stretchr/testify/suite"
+)
+
+type (
+	TypedClientSuite struct {
+		suite.Suite
+		client *v2.TypedClient[customZone, v2.RRSet]
+	}
+
+	// customZone has a field v2.Zone doesn't know about.
+	customZone struct {
+		ID              string `json:"id"`
+		Name            string `json:"name"`
+		LastCheckStatus bool   `json:"last_check_status"`
+	}
+
+	customResource struct {
+		ID    string `json:"id"`
+		Value string `json:"value"`
+	}
+)
+
+//nolint:paralleltest
+func TestTypedClient(t *testing.T) {
+	suite.Run(t, new(TypedClientSuite))
+}
+
+func (s *TypedClientSuite) SetupTest() {
+	httpmock.Activate()
+	s.client = v2.NewTypedClient[customZone, v2.RRSet](testAPIURL, testHTTPClient, make(http.Header))
+}
+
+func (s *TypedClientSuite) TearDownTest() {
+	httpmock.DeactivateAndReset()
+}
+
+func (s *TypedClientSuite) TestGetZone_decodes_custom_model() {
+	httpmock.RegisterResponder(
+		http.MethodGet,
+		fmt.Sprintf("%s%s", testAPIURL, fmt.Sprintf(zonePath, testID)),
+		httpmock.NewStringResponder(http.StatusOK, mockGetZoneResponse()),
+	)
+
+	zone, err := s.client.GetZone(testCtx, testID, nil)
+
+	s.Require().NoError(err)
+	s.Equal(testID, zone.ID)
+	s.Equal(testDomainName, zone.Name)
+}
+
+func (s *TypedClientSuite) TestListZones_with_pagination_helper() {
+	httpmock.RegisterResponder(
+		http.MethodGet,
+		fmt.Sprintf("%s%s", testAPIURL, rootPath),
+		httpmock.NewStringResponder(http.StatusOK, mockListZonesResponse(2)),
+	)
+
+	zones, err := v2.ListAllZones[customZone](testCtx, s.client, nil)
+
+	s.Require().NoError(err)
+	s.Len(zones, 2)
+	s.IsType(&customZone{}, zones[0])
+}
+
+func (s *TypedClientSuite) TestDo_custom_endpoint() {
+	httpmock.RegisterResponder(
+		http.MethodPost,
+		fmt.Sprintf("%s/zones/%s/custom", testAPIURL, testID),
+		func(r *http.Request) (*http.Response, error) {
+			s.Equal("application/json", r.Header.Get("Content-Type"))
+
+			return httpmock.NewStringResponse(http.StatusOK, `{"id": "1", "value": "v"}`), nil
+		},
+	)
+	client := s.client.WithHeaders(http.Header{"Content-Type": []string{"application/json"}})
+	requester, ok := client.(v2.Requester)
+	s.Require().True(ok)
+
+	resource, err := v2.Do[customResource](
+		testCtx, requester, http.MethodPost, fmt.Sprintf("/zones/%s/custom", testID),
+		bytes.NewReader([]byte(`{"value": "v"}`)), nil, nil,
+	)
+
+	s.Require().NoError(err)
+	s.Equal(customResource{ID: "1", Value: "v"}, *resource)
+}
+
+func (s *TypedClientSuite) TestDo_custom_decoder_and_errors() {
+	httpmock.RegisterResponder(
+		http.MethodGet,
+		fmt.Sprintf("%s/plain", testAPIURL),
+		httpmock.NewStringResponder(http.StatusOK, "plain text"),
+	)
+	httpmock.RegisterResponder(
+		http.MethodGet,
+		fmt.Sprintf("%s/missing", testAPIURL),
+		httpmock.NewStringResponder(http.StatusNotFound, ""),
+	)
+	upper := func(body []byte) (*string, error) {
+		result := strings.ToUpper(string(body))
+
+		return &result, nil
+	}
+
+	result, err := v2.Do[string](context.Background(), s.client, http.MethodGet, "/plain", nil, nil, upper)
+
+	s.Require().NoError(err)
+	s.Equal("PLAIN TEXT", *result)
+
+	_, err = v2.Do[string](context.Background(), s.client, http.MethodGet, "/missing", nil, nil, upper)
+
+	s.ErrorIs(err, v2.ErrNotFound)
+}
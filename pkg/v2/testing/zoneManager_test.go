@@ -160,3 +160,75 @@ func (s *ZoneManageSuite) TestUpdateProtectionState_OK() {
 
 	s.Nil(err)
 }
+
+func (s *ZoneManageSuite) TestGetZone_full_model() {
+	path := fmt.Sprintf(zonePath, testID)
+	httpmock.RegisterResponder(
+		http.MethodGet,
+		fmt.Sprintf("%s%s", testAPIURL, path),
+		httpmock.NewStringResponder(http.StatusOK, fmt.Sprintf(`{
+			"id": "%v",
+			"name": "%v",
+			"comment": "managed by terraform",
+			"disabled": true,
+			"protected": true,
+			"nameservers": ["a.ns.selectel.ru.", "b.ns.selectel.ru."],
+			"last_check_status": true,
+			"delegation_checked_at": "2023-03-09T18:47:25Z",
+			"last_delegated_at": "2023-03-09T18:47:25Z"
+		}`, testID, testDomainName)),
+	)
+
+	zone, err := testClient.GetZone(testCtx, testID, nil)
+
+	s.Require().NoError(err)
+	s.Equal("managed by terraform", zone.Comment)
+	s.True(zone.Disabled)
+	s.True(zone.Protected)
+	s.Equal([]string{"a.ns.selectel.ru.", "b.ns.selectel.ru."}, zone.Nameservers)
+	s.True(zone.DelegationInfo.LastCheckStatus)
+	s.False(zone.DelegationInfo.DelegationCheckedAt.IsZero())
+}
+
+func (s *ZoneManageSuite) TestUpdateZone_only_needed_calls() {
+	for _, path := range []string{zonePath, zonePathUpdateState, zonePathUpdateProtection} {
+		httpmock.RegisterResponder(
+			http.MethodPatch,
+			fmt.Sprintf("%s%s", testAPIURL, fmt.Sprintf(path, testID)),
+			httpmock.NewBytesResponder(http.StatusNoContent, []byte{}),
+		)
+	}
+	comment := "new comment"
+	protected := false
+
+	//nolint: exhaustruct
+	err := v2.UpdateZone[v2.Zone](testCtx, testClient, testID, &v2.ZoneUpdateOpts{
+		Comment:   &comment,
+		Protected: &protected,
+	})
+
+	s.Require().NoError(err)
+	calls := httpmock.GetCallCountInfo()
+	s.Equal(1, calls[fmt.Sprintf("PATCH %s%s", testAPIURL, fmt.Sprintf(zonePath, testID))])
+	s.Equal(0, calls[fmt.Sprintf("PATCH %s%s", testAPIURL, fmt.Sprintf(zonePathUpdateState, testID))])
+	s.Equal(1, calls[fmt.Sprintf("PATCH %s%s", testAPIURL, fmt.Sprintf(zonePathUpdateProtection, testID))])
+}
+
+func (s *ZoneManageSuite) TestUpdateZone_stops_on_error() {
+	httpmock.RegisterResponder(
+		http.MethodPatch,
+		fmt.Sprintf("%s%s", testAPIURL, fmt.Sprintf(zonePathUpdateState, testID)),
+		httpmock.NewStringResponder(http.StatusNotFound, ""),
+	)
+	disabled := true
+	protected := true
+
+	//nolint: exhaustruct
+	err := v2.UpdateZone[v2.Zone](testCtx, testClient, testID, &v2.ZoneUpdateOpts{
+		Disabled:  &disabled,
+		Protected: &protected,
+	})
+
+	s.ErrorIs(err, v2.ErrNotFound)
+	s.Equal(1, httpmock.GetTotalCallCount())
+}
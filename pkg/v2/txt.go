@@ -0,0 +1,205 @@
+package v2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxTXTChunk represents the maximum length of a single character-string of a TXT record.
+const maxTXTChunk = 255
+
+// NewTXTRecordItem returns a TXT record item with the value encoded by EncodeTXT.
+func NewTXTRecordItem(value string) RecordItem {
+	return RecordItem{Content: EncodeTXT(value), Disabled: false}
+}
+
+// TXTValue returns the logical value of TXT record content, see DecodeTXT.
+func (r RecordItem) TXTValue() (string, error) {
+	return DecodeTXT(r.Content)
+}
+
+// EncodeTXT returns the value as quoted character-strings of at most 255 bytes,
+// e.g. a 300 bytes DKIM key becomes "<255 bytes>" "<45 bytes>".
+// Quotes and backslashes are escaped, control characters are written as \DDD.
+// Chunks aren't split inside of multibyte UTF-8 characters.
+func EncodeTXT(value string) string {
+	chunks := make([]string, 0, len(value)/maxTXTChunk+1)
+	for len(value) > maxTXTChunk {
+		end := maxTXTChunk
+		for end > 0 && !utf8.RuneStart(value[end]) {
+			end--
+		}
+		if end == 0 {
+			end = maxTXTChunk
+		}
+		chunks = append(chunks, quoteTXTChunk(value[:end]))
+		value = value[end:]
+	}
+	chunks = append(chunks, quoteTXTChunk(value))
+
+	return strings.Join(chunks, " ")
+}
+
+// DecodeTXT returns the character-strings of TXT content joined into the logical value,
+// e.g. `"v=DKIM1; " "p=MIIB..."` becomes "v=DKIM1; p=MIIB...".
+func DecodeTXT(content string) (string, error) {
+	chunks, err := SplitTXT(content)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(chunks, ""), nil
+}
+
+// TXTData returns the logical value of quoted TXT content for matching records by their data,
+// e.g. `"v=spf1 " "-all"` becomes "v=spf1 -all". Content that isn't quoted or fails
+// to decode is returned unchanged, without surrounding whitespace.
+func TXTData(content string) string {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, `"`) {
+		return content
+	}
+	data, err := DecodeTXT(content)
+	if err != nil {
+		return content
+	}
+
+	return data
+}
+
+// SplitTXT returns the unescaped character-strings of TXT content in the presentation format.
+// Character-strings are quoted or bare and separated by whitespace. Unterminated quotes,
+// broken escapes and character-strings longer than 255 bytes fail with ErrInvalidTXT.
+func SplitTXT(content string) ([]string, error) {
+	var chunks []string
+	for i := 0; i < len(content); {
+		switch content[i] {
+		case ' ', '\t':
+			i++
+
+			continue
+		}
+		chunk, next, err := readTXTChunk(content, i)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTXT, err)
+		}
+		if len(chunk) > maxTXTChunk {
+			return nil, fmt.Errorf("%w: character-string of %d bytes exceeds %d bytes",
+				ErrInvalidTXT, len(chunk), maxTXTChunk)
+		}
+		chunks = append(chunks, chunk)
+		i = next
+	}
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("%w: no character-strings", ErrInvalidTXT)
+	}
+
+	return chunks, nil
+}
+
+// ValidateTXT checks that TXT content is correctly quoted and chunked.
+func ValidateTXT(content string) error {
+	_, err := SplitTXT(content)
+
+	return err
+}
+
+// validateTXTRecords checks contents of records of TXT rrsets.
+func validateTXTRecords(recordType RecordType, records []RecordItem) error {
+	if recordType != TXT {
+		return nil
+	}
+	for _, item := range records {
+		if err := ValidateTXT(item.Content); err != nil {
+			return fmt.Errorf("%w: %q", err, item.Content)
+		}
+	}
+
+	return nil
+}
+
+// readTXTChunk reads a single character-string starting at i and returns it with the position after it.
+func readTXTChunk(content string, i int) (string, int, error) {
+	quoted := content[i] == '"'
+	if quoted {
+		i++
+	}
+	var chunk strings.Builder
+	for i < len(content) {
+		c := content[i]
+		switch {
+		case c == '\\':
+			value, next, err := readTXTEscape(content, i)
+			if err != nil {
+				return "", 0, err
+			}
+			chunk.WriteByte(value)
+			i = next
+
+			continue
+		case quoted && c == '"':
+			i++
+			if i < len(content) && content[i] != ' ' && content[i] != '\t' {
+				return "", 0, fmt.Errorf("no whitespace after the closing quote at %d", i)
+			}
+
+			return chunk.String(), i, nil
+		case !quoted && c == '"':
+			return "", 0, fmt.Errorf("unexpected quote at %d", i)
+		case !quoted && (c == ' ' || c == '\t'):
+			return chunk.String(), i, nil
+		}
+		chunk.WriteByte(c)
+		i++
+	}
+	if quoted {
+		return "", 0, fmt.Errorf("unterminated quote")
+	}
+
+	return chunk.String(), i, nil
+}
+
+// readTXTEscape reads an escape sequence, \X or \DDD, starting at i.
+func readTXTEscape(content string, i int) (byte, int, error) {
+	if i+1 >= len(content) {
+		return 0, 0, fmt.Errorf("dangling backslash at %d", i)
+	}
+	if !isDigit(content[i+1]) {
+		return content[i+1], i + 2, nil
+	}
+	if i+3 >= len(content) || !isDigit(content[i+2]) || !isDigit(content[i+3]) {
+		return 0, 0, fmt.Errorf("escape at %d must have three digits", i)
+	}
+	value, err := strconv.Atoi(content[i+1 : i+4])
+	if err != nil || value > 255 {
+		return 0, 0, fmt.Errorf("escape at %d is out of range", i)
+	}
+
+	return byte(value), i + 4, nil
+}
+
+func quoteTXTChunk(value string) string {
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == '"' || c == '\\':
+			quoted.WriteByte('\\')
+			quoted.WriteByte(c)
+		case c < ' ' || c == 0x7f:
+			fmt.Fprintf(&quoted, "\\%03d", c)
+		default:
+			quoted.WriteByte(c)
+		}
+	}
+	quoted.WriteByte('"')
+
+	return quoted.String()
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
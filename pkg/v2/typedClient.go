@@ -0,0 +1,116 @@
+package v2
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// TypedClient is a client of the Domains API V2 that decodes zones into Z and rrsets into S.
+// It allows using own models, e.g. with fields the package doesn't know about yet.
+type TypedClient[Z any, S any] struct {
+	client *Client
+}
+
+//nolint:exhaustruct
+var (
+	_ DNSClient[Zone, RRSet] = &TypedClient[Zone, RRSet]{}
+	_ Requester              = &TypedClient[Zone, RRSet]{}
+)
+
+// NewTypedClient returns a client that decodes zones into Z and rrsets into S.
+func NewTypedClient[Z any, S any](
+	apiURL string, httpClient *http.Client, defaultHeaders http.Header,
+) *TypedClient[Z, S] {
+	return &TypedClient[Z, S]{
+		client: &Client{
+			httpClient:     httpClient,
+			defaultHeaders: defaultHeaders,
+			BaseURL:        apiURL,
+			zoneNames:      nil,
+		},
+	}
+}
+
+// WithHeaders returns reference to a copy of the initial client
+// with extra headers passed in params. Conflicting headers are replaced
+// with new ones.
+func (t *TypedClient[Z, S]) WithHeaders(headers http.Header) DNSClient[Z, S] {
+	client, _ := t.client.WithHeaders(headers).(*Client)
+
+	return &TypedClient[Z, S]{client: client}
+}
+
+// NewRequest returns a request to the path relative to the API URL with the default headers of the client.
+func (t *TypedClient[Z, S]) NewRequest(
+	ctx context.Context, method, path string, body io.Reader, params *map[string]string,
+) (*http.Request, error) {
+	return t.client.NewRequest(ctx, method, path, body, params)
+}
+
+// HTTPClient returns the HTTP client used to send requests.
+func (t *TypedClient[Z, S]) HTTPClient() *http.Client {
+	return t.client.HTTPClient()
+}
+
+// GetZone returns a single zone by its id.
+func (t *TypedClient[Z, S]) GetZone(ctx context.Context, zoneID string, _ *map[string]string) (*Z, error) {
+	return getZone[Z](ctx, t.client, zoneID)
+}
+
+// ListZones returns a list of zones by options.
+func (t *TypedClient[Z, S]) ListZones(ctx context.Context, options *map[string]string) (Listable[Z], error) {
+	return listZones[Z](ctx, t.client, options)
+}
+
+// CreateZone request to create of a new zone.
+func (t *TypedClient[Z, S]) CreateZone(ctx context.Context, zone Creatable) (*Z, error) {
+	return createZone[Z](ctx, t.client, zone)
+}
+
+// DeleteZone request to delete of the zone by id.
+func (t *TypedClient[Z, S]) DeleteZone(ctx context.Context, zoneID string) error {
+	return t.client.DeleteZone(ctx, zoneID)
+}
+
+// UpdateZoneState request to enable/disable service for zone by zoneID.
+func (t *TypedClient[Z, S]) UpdateZoneState(ctx context.Context, zoneID string, disabled bool) error {
+	return t.client.UpdateZoneState(ctx, zoneID, disabled)
+}
+
+// UpdateZoneComment request to update the comment for zone by zoneID.
+func (t *TypedClient[Z, S]) UpdateZoneComment(ctx context.Context, zoneID string, comment string) error {
+	return t.client.UpdateZoneComment(ctx, zoneID, comment)
+}
+
+// UpdateProtectionState request to enable/disable zone protection from delete operation.
+func (t *TypedClient[Z, S]) UpdateProtectionState(ctx context.Context, zoneID string, protected bool) error {
+	return t.client.UpdateProtectionState(ctx, zoneID, protected)
+}
+
+// CreateRRSet request to create a new rrset for the zone.
+func (t *TypedClient[Z, S]) CreateRRSet(ctx context.Context, zoneID string, rrset Creatable) (*S, error) {
+	return createRRSet[S](ctx, t.client, zoneID, rrset)
+}
+
+// GetRRSet returns a single rrset from zone by zoneID and rrsetID.
+func (t *TypedClient[Z, S]) GetRRSet(ctx context.Context, zoneID, rrsetID string) (*S, error) {
+	return getRRSet[S](ctx, t.client, zoneID, rrsetID)
+}
+
+// ListRRSets returns a list of rrsets by zoneID and options.
+func (t *TypedClient[Z, S]) ListRRSets(
+	ctx context.Context, zoneID string, options *map[string]string,
+) (Listable[S], error) {
+	return listRRSets[S](ctx, t.client, zoneID, options)
+}
+
+// UpdateRRSet request to update the rrset for zone by zoneID and rrsetID.
+func (t *TypedClient[Z, S]) UpdateRRSet(ctx context.Context, zoneID, rrsetID string, rrset Updatable) error {
+	return t.client.UpdateRRSet(ctx, zoneID, rrsetID, rrset)
+}
+
+// DeleteRRSet request to delete the rrset from zone by zoneID and rrsetID.
+func (t *TypedClient[Z, S]) DeleteRRSet(ctx context.Context, zoneID, rrsetID string) error {
+	return t.client.DeleteRRSet(ctx, zoneID, rrsetID)
+}
@@ -0,0 +1,27 @@
+/*
+Package watch detects changes made to zones of the Selectel Domains API V2.
+
+The Watcher periodically lists rrsets of a zone, compares them with the
+previous snapshot and emits typed events for added, removed and modified
+rrsets as well as for zone state and protection changes.
+
+Polling intervals are jittered, failed polls are retried with exponential
+backoff and the last snapshot can be persisted, so a restarted watcher
+resumes without emitting events for changes it has already reported.
+
+Example of watching a zone
+
+  watcher := watch.NewWatcher(client)
+  watcher.OnSnapshot = func(snapshot watch.Snapshot) {
+    data, _ := json.Marshal(snapshot)
+    _ = os.WriteFile("snapshot.json", data, 0o600)
+  }
+  for event := range watcher.Watch(ctx, zoneID, time.Minute) {
+    if event.Type == watch.EventError {
+      log.Println(event.Err)
+      continue
+    }
+    fmt.Printf("%s %+v\n", event.Type, event.RRSet)
+  }
+*/
+package watch
@@ -0,0 +1,81 @@
+package watch
+
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	v2 "github.com/selectel/domains-go/pkg/v2"
+)
+
+// Snapshot represents the state of a zone observed by a single poll.
+// It can be marshalled to JSON to resume watching after a restart.
+type Snapshot struct {
+	ZoneID    string     `json:"zone_id"`
+	Disabled  bool       `json:"disabled"`
+	Protected bool       `json:"protected"`
+	RRSets    []v2.RRSet `json:"rrsets"`
+	TakenAt   time.Time  `json:"taken_at"`
+}
+
+func newSnapshot(zone *v2.Zone, rrsets []*v2.RRSet, takenAt time.Time) *Snapshot {
+	snapshot := &Snapshot{
+		ZoneID:    zone.ID,
+		Disabled:  zone.Disabled,
+		Protected: zone.Protected,
+		RRSets:    make([]v2.RRSet, 0, len(rrsets)),
+		TakenAt:   takenAt,
+	}
+	for _, rrset := range rrsets {
+		snapshot.RRSets = append(snapshot.RRSets, *rrset)
+	}
+	sort.Slice(snapshot.RRSets, func(i, j int) bool {
+		return snapshot.RRSets[i].ID < snapshot.RRSets[j].ID
+	})
+
+	return snapshot
+}
+
+// diff returns events that turn the previous snapshot into the current one.
+func diff(previous, current *Snapshot, zone *v2.Zone) []Event {
+	var events []Event
+	if previous.Disabled != current.Disabled {
+		eventType := EventZoneEnabled
+		if current.Disabled {
+			eventType = EventZoneDisabled
+		}
+		events = append(events, newZoneEvent(eventType, zone))
+	}
+	if previous.Protected != current.Protected {
+		eventType := EventZoneUnprotected
+		if current.Protected {
+			eventType = EventZoneProtected
+		}
+		events = append(events, newZoneEvent(eventType, zone))
+	}
+
+	previousIndex := make(map[string]v2.RRSet, len(previous.RRSets))
+	for _, rrset := range previous.RRSets {
+		previousIndex[rrset.ID] = rrset
+	}
+	currentIndex := make(map[string]bool, len(current.RRSets))
+	for i := range current.RRSets {
+		rrset := current.RRSets[i]
+		currentIndex[rrset.ID] = true
+		old, ok := previousIndex[rrset.ID]
+		switch {
+		case !ok:
+			events = append(events, newRRSetEvent(EventRRSetAdded, zone, &rrset, nil))
+		case !reflect.DeepEqual(old, rrset):
+			events = append(events, newRRSetEvent(EventRRSetModified, zone, &rrset, &old))
+		}
+	}
+	for i := range previous.RRSets {
+		rrset := previous.RRSets[i]
+		if !currentIndex[rrset.ID] {
+			events = append(events, newRRSetEvent(EventRRSetRemoved, zone, &rrset, nil))
+		}
+	}
+
+	return events
+}
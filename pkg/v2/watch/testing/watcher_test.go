@@ -0,0 +1,217 @@
+package testing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/selectel/domains-go/pkg/testutils"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/watch"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testZoneName = "bonnie-test.com."
+	testInterval = 10 * time.Millisecond
+	testTimeout  = 5 * time.Second
+)
+
+type (
+	WatcherSuite struct {
+		suite.Suite
+		api    *testutils.FakeAPI
+		client v2.DNSClient[v2.Zone, v2.RRSet]
+		zone   *v2.Zone
+		www    *v2.RRSet
+	}
+)
+
+//nolint:paralleltest
+func TestWatcher(t *testing.T) {
+	suite.Run(t, new(WatcherSuite))
+}
+
+func (s *WatcherSuite) SetupTest() {
+	s.api = testutils.NewFakeAPI()
+	s.client = s.api.Client()
+	s.zone = s.api.AddZone(testZoneName)
+	s.www = s.api.AddRRSet(s.zone.ID, *testutils.NewRRSet("www."+testZoneName, v2.A, "10.0.0.1"))
+}
+
+func (s *WatcherSuite) TearDownTest() {
+	s.api.Close()
+}
+
+// startWatcher starts watching the zone and waits for the first poll.
+func (s *WatcherSuite) startWatcher(ctx context.Context, snapshot *watch.Snapshot) (<-chan watch.Event, watch.Snapshot) {
+	watcher := watch.NewWatcher(s.client)
+	watcher.Jitter = 0
+	watcher.MaxBackoff = 4 * testInterval
+	watcher.Snapshot = snapshot
+	snapshots := make(chan watch.Snapshot, 100)
+	watcher.OnSnapshot = func(snapshot watch.Snapshot) {
+		snapshots <- snapshot
+	}
+	events := watcher.Watch(ctx, s.zone.ID, testInterval)
+
+	return events, s.nextSnapshot(snapshots)
+}
+
+func (s *WatcherSuite) nextEvent(events <-chan watch.Event) watch.Event {
+	select {
+	case event, ok := <-events:
+		s.Require().True(ok, "events channel closed")
+
+		return event
+	case <-time.After(testTimeout):
+		s.FailNow("no event received")
+	}
+
+	return watch.Event{}
+}
+
+func (s *WatcherSuite) nextSnapshot(snapshots <-chan watch.Snapshot) watch.Snapshot {
+	select {
+	case snapshot := <-snapshots:
+		return snapshot
+	case <-time.After(testTimeout):
+		s.FailNow("no snapshot received")
+	}
+
+	return watch.Snapshot{}
+}
+
+func (s *WatcherSuite) TestWatch_rrset_events() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, _ := s.startWatcher(ctx, nil)
+
+	created, err := s.client.CreateRRSet(ctx, s.zone.ID, &v2.RRSet{
+		Name: "api." + testZoneName, Type: v2.A, TTL: 60,
+		Records: []v2.RecordItem{{Content: "10.0.0.2", Disabled: false}},
+	})
+	s.Require().NoError(err)
+	event := s.nextEvent(events)
+	s.Equal(watch.EventRRSetAdded, event.Type)
+	s.Equal(created.ID, event.RRSet.ID)
+
+	update := testutils.NewRRSet(s.www.Name, v2.A, "10.0.0.3")
+	s.Require().NoError(s.client.UpdateRRSet(ctx, s.zone.ID, s.www.ID, update))
+	event = s.nextEvent(events)
+	s.Equal(watch.EventRRSetModified, event.Type)
+	s.Equal("10.0.0.3", event.RRSet.Records[0].Content)
+	s.Equal("10.0.0.1", event.Previous.Records[0].Content)
+
+	s.Require().NoError(s.client.DeleteRRSet(ctx, s.zone.ID, created.ID))
+	event = s.nextEvent(events)
+	s.Equal(watch.EventRRSetRemoved, event.Type)
+	s.Equal(created.ID, event.RRSet.ID)
+}
+
+func (s *WatcherSuite) TestWatch_zone_events() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, _ := s.startWatcher(ctx, nil)
+
+	s.Require().NoError(s.client.UpdateZoneState(ctx, s.zone.ID, true))
+	event := s.nextEvent(events)
+	s.Equal(watch.EventZoneDisabled, event.Type)
+	s.True(event.Zone.Disabled)
+
+	s.Require().NoError(s.client.UpdateProtectionState(ctx, s.zone.ID, true))
+	event = s.nextEvent(events)
+	s.Equal(watch.EventZoneProtected, event.Type)
+	s.True(event.Zone.Protected)
+}
+
+func (s *WatcherSuite) TestWatch_resumes_from_snapshot() {
+	ctx, cancel := context.WithCancel(context.Background())
+	_, snapshot := s.startWatcher(ctx, nil)
+	cancel()
+
+	s.api.AddRRSet(s.zone.ID, *testutils.NewRRSet("api."+testZoneName, v2.A, "10.0.0.2"))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	watcher := watch.NewWatcher(s.client)
+	watcher.Jitter = 0
+	watcher.Snapshot = &snapshot
+	events := watcher.Watch(ctx, s.zone.ID, testInterval)
+
+	// Only the change made while the watcher was stopped is reported.
+	event := s.nextEvent(events)
+	s.Equal(watch.EventRRSetAdded, event.Type)
+	s.Equal("api."+testZoneName, event.RRSet.Name)
+}
+
+func (s *WatcherSuite) TestWatch_reports_errors_and_recovers() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, _ := s.startWatcher(ctx, nil)
+
+	s.api.InjectFault(func(_ *http.Request) int { return http.StatusBadGateway })
+	event := s.nextEvent(events)
+	s.Equal(watch.EventError, event.Type)
+	s.Error(event.Err)
+
+	s.api.ClearFaults()
+	s.api.AddRRSet(s.zone.ID, *testutils.NewRRSet("api."+testZoneName, v2.A, "10.0.0.2"))
+	for event.Type == watch.EventError {
+		event = s.nextEvent(events)
+	}
+	s.Equal(watch.EventRRSetAdded, event.Type)
+}
+
+func (s *WatcherSuite) TestWatch_closes_channel_on_cancel() {
+	ctx, cancel := context.WithCancel(context.Background())
+	events, _ := s.startWatcher(ctx, nil)
+	cancel()
+
+	for range events {
+	}
+}
+
+func (s *WatcherSuite) TestWatch_zero_interval() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watcher := watch.NewWatcher(s.client)
+	snapshots := make(chan watch.Snapshot, 100)
+	watcher.OnSnapshot = func(snapshot watch.Snapshot) { snapshots <- snapshot }
+	watcher.Watch(ctx, s.zone.ID, 0)
+	s.nextSnapshot(snapshots)
+	requests := len(s.api.Requests())
+
+	// A zero interval must fall back to the default instead of polling continuously.
+	time.Sleep(5 * testInterval)
+	s.Len(s.api.Requests(), requests)
+	s.Empty(snapshots)
+}
+
+func (s *WatcherSuite) TestWatch_snapshot_after_delivery() {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	_, snapshot := s.startWatcher(ctx, nil)
+	s.api.AddRRSet(s.zone.ID, *testutils.NewRRSet("new."+testZoneName, v2.A, "10.0.0.2"))
+
+	watcher := watch.NewWatcher(s.client)
+	watcher.Jitter = 0
+	watcher.Snapshot = &snapshot
+	persisted := make(chan watch.Snapshot, 10)
+	watcher.OnSnapshot = func(snapshot watch.Snapshot) { persisted <- snapshot }
+	events := watcher.Watch(ctx, s.zone.ID, testInterval)
+
+	// The snapshot with the new rrset must not be persisted while its event is undelivered.
+	time.Sleep(5 * testInterval)
+	s.Empty(persisted)
+
+	event := <-events
+	s.Equal(watch.EventRRSetAdded, event.Type)
+	select {
+	case current := <-persisted:
+		s.Len(current.RRSets, 2)
+	case <-ctx.Done():
+		s.FailNow("snapshot wasn't persisted after the event was delivered")
+	}
+}
@@ -0,0 +1,173 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/internal/backoff"
+)
+
+const (
+	// defaultInterval represents the delay between polls used when Watch is given a non-positive interval.
+	defaultInterval = time.Minute
+
+	// defaultJitter represents the default fraction of the interval polls are randomly shifted by.
+	defaultJitter = 0.1
+
+	// defaultMaxBackoff represents the default upper limit of the delay between failed polls.
+	defaultMaxBackoff = 5 * time.Minute
+)
+
+// Types of events emitted by the Watcher.
+const (
+	EventRRSetAdded      EventType = "rrset_added"
+	EventRRSetRemoved    EventType = "rrset_removed"
+	EventRRSetModified   EventType = "rrset_modified"
+	EventZoneDisabled    EventType = "zone_disabled"
+	EventZoneEnabled     EventType = "zone_enabled"
+	EventZoneProtected   EventType = "zone_protected"
+	EventZoneUnprotected EventType = "zone_unprotected"
+	EventError           EventType = "error"
+)
+
+type (
+	// EventType represents the kind of change reported by an Event.
+	EventType string
+
+	// Event represents a single change of the watched zone.
+	Event struct {
+		Type   EventType
+		ZoneID string
+
+		// Zone contains the zone as observed by the poll that detected the change.
+		// It is nil for EventError.
+		Zone *v2.Zone
+
+		// RRSet contains the current rrset for added and modified rrsets
+		// and the last known rrset for removed ones.
+		RRSet *v2.RRSet
+
+		// Previous contains the rrset before the change for EventRRSetModified.
+		Previous *v2.RRSet
+
+		// Err contains the poll error for EventError.
+		Err error
+	}
+
+	// Watcher polls zones of the Domains API V2 and reports their changes.
+	Watcher struct {
+		// Snapshot is the state to resume watching from.
+		// If nil, the first poll only records the state without emitting events.
+		Snapshot *Snapshot
+
+		// OnSnapshot is called with the snapshot of every successful poll once its events
+		// are delivered, so it can be persisted and passed as Snapshot after a restart.
+		OnSnapshot func(snapshot Snapshot)
+
+		// Jitter represents the fraction of the interval polls are randomly shifted by.
+		Jitter float64
+
+		// MaxBackoff limits the delay between polls after consecutive errors, zero means no limit.
+		MaxBackoff time.Duration
+
+		manager v2.DNSManager[v2.Zone, v2.RRSet]
+	}
+)
+
+// NewWatcher returns a watcher with default jitter and backoff.
+func NewWatcher(manager v2.DNSManager[v2.Zone, v2.RRSet]) *Watcher {
+	return &Watcher{
+		Snapshot:   nil,
+		OnSnapshot: nil,
+		Jitter:     defaultJitter,
+		MaxBackoff: defaultMaxBackoff,
+		manager:    manager,
+	}
+}
+
+// Watch polls the zone every interval and emits its changes on the returned channel.
+// Poll errors are emitted as EventError and retried with exponential backoff.
+// The channel is closed once the context is done.
+// A non-positive interval falls back to the default of one minute.
+func (w *Watcher) Watch(ctx context.Context, zoneID string, interval time.Duration) <-chan Event {
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		previous := w.Snapshot
+		failures := 0
+		for {
+			current, changes, err := w.poll(ctx, zoneID, previous)
+			if err != nil {
+				failures++
+				changes = []Event{{Type: EventError, ZoneID: zoneID, Zone: nil, RRSet: nil, Previous: nil, Err: err}}
+			} else {
+				failures = 0
+				previous = current
+			}
+			for _, event := range changes {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+			// The snapshot is persisted only after its events are delivered,
+			// so a restart reports them again instead of losing them.
+			if err == nil && w.OnSnapshot != nil {
+				w.OnSnapshot(*current)
+			}
+
+			timer := time.NewTimer(w.delay(interval, failures))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+
+	return events
+}
+
+func (w *Watcher) poll(ctx context.Context, zoneID string, previous *Snapshot) (*Snapshot, []Event, error) {
+	zone, err := w.manager.GetZone(ctx, zoneID, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get zone: %w", err)
+	}
+	rrsets, err := v2.ListAllRRSets[v2.RRSet](ctx, w.manager, zoneID, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list rrsets: %w", err)
+	}
+	current := newSnapshot(zone, rrsets, time.Now())
+	if previous == nil || previous.ZoneID != zoneID {
+		return current, nil, nil
+	}
+
+	return current, diff(previous, current, zone), nil
+}
+
+// delay returns the jittered time to wait before the next poll.
+func (w *Watcher) delay(interval time.Duration, failures int) time.Duration {
+	delay := backoff.Delay(interval, w.MaxBackoff, failures)
+	if w.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * w.Jitter * float64(delay))
+	}
+
+	return delay
+}
+
+func newZoneEvent(eventType EventType, zone *v2.Zone) Event {
+	return Event{Type: eventType, ZoneID: zone.ID, Zone: zone, RRSet: nil, Previous: nil, Err: nil}
+}
+
+func newRRSetEvent(eventType EventType, zone *v2.Zone, rrset, previous *v2.RRSet) Event {
+	return Event{Type: eventType, ZoneID: zone.ID, Zone: zone, RRSet: rrset, Previous: previous, Err: nil}
+}
@@ -0,0 +1,23 @@
+/*
+Package xfr serves zones of the Selectel Domains API V2 to secondary
+nameservers over AXFR and IXFR.
+
+The Server keeps an authoritative view of every configured zone built from
+rrsets returned by the API. The view is refreshed periodically; whenever its
+contents change the SOA serial is incremented and the difference is stored in
+a journal that is used to answer IXFR requests. Transfers are allowed only to
+addresses from the AllowTransfer list and, optionally, only with a valid TSIG
+signature.
+
+Example of serving zones to secondaries
+
+  server := xfr.NewServer(client, map[string]string{"example.com.": zoneID})
+  server.AllowTransfer = []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")}
+  go server.Run(ctx)
+
+  dnsServer := &dns.Server{Addr: ":53", Net: "tcp", Handler: server}
+  if err := dnsServer.ListenAndServe(); err != nil {
+    log.Fatal(err)
+  }
+*/
+package xfr
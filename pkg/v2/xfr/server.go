@@ -0,0 +1,361 @@
+package xfr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/rrconv"
+)
+
+const (
+	// defaultRefreshInterval represents the default interval between zone refreshes.
+	defaultRefreshInterval = time.Minute
+
+	// defaultJournalSize represents the default number of zone versions kept for IXFR.
+	defaultJournalSize = 100
+
+	// envelopeSize represents the approximate size of a single message of a zone transfer.
+	envelopeSize = 16 * 1024
+)
+
+// Server serves zones of the Domains API V2 over DNS, including AXFR and IXFR.
+type Server struct {
+	// AllowTransfer lists networks allowed to transfer zones.
+	// Transfers are refused for everyone if the list is empty.
+	AllowTransfer []netip.Prefix
+
+	// RequireTSIG makes the server refuse transfers without a valid TSIG signature.
+	RequireTSIG bool
+
+	// RefreshInterval represents the interval between zone refreshes done by Run.
+	// If zero, defaultRefreshInterval is used.
+	RefreshInterval time.Duration
+
+	// JournalSize limits the number of zone versions kept to answer IXFR requests.
+	// If zero, defaultJournalSize is used.
+	JournalSize int
+
+	// ErrorLog specifies an optional logger for refresh errors and skipped rrsets.
+	ErrorLog *log.Logger
+
+	manager v2.RRSetManager[v2.RRSet]
+	mu      sync.RWMutex
+	zones   map[string]*zoneView
+}
+
+// NewServer returns a server for zones, which maps zone names to zone ids of the Domains API V2.
+// Zones are served once they have been loaded by Refresh or Run.
+func NewServer(manager v2.RRSetManager[v2.RRSet], zones map[string]string) *Server {
+	server := &Server{
+		AllowTransfer:   nil,
+		RequireTSIG:     false,
+		RefreshInterval: defaultRefreshInterval,
+		JournalSize:     defaultJournalSize,
+		ErrorLog:        nil,
+		manager:         manager,
+		mu:              sync.RWMutex{},
+		zones:           make(map[string]*zoneView, len(zones)),
+	}
+	for name, zoneID := range zones {
+		name = rrconv.CanonicalName(name)
+		//nolint: exhaustruct
+		server.zones[name] = &zoneView{name: name, id: zoneID}
+	}
+
+	return server
+}
+
+// Run refreshes zones every RefreshInterval until the context is done.
+func (s *Server) Run(ctx context.Context) error {
+	interval := s.RefreshInterval
+	if interval == 0 {
+		interval = defaultRefreshInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Refresh(ctx); err != nil {
+			s.logf("refresh zones: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh reloads all zones from the API.
+// Zones that fail to load keep serving their previous contents.
+func (s *Server) Refresh(ctx context.Context) error {
+	s.mu.RLock()
+	views := make([]*zoneView, 0, len(s.zones))
+	for _, view := range s.zones {
+		views = append(views, view)
+	}
+	s.mu.RUnlock()
+
+	var errs []error
+	for _, view := range views {
+		rrsets, err := v2.ListAllRRSets[v2.RRSet](ctx, s.manager, view.id, nil)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("zone %s: %w", view.name, err))
+
+			continue
+		}
+		soa, records, skipped := buildRecords(view.name, rrsets)
+		for _, err := range skipped {
+			s.logf("zone %s: skip %v", view.name, err)
+		}
+
+		journalSize := s.JournalSize
+		if journalSize == 0 {
+			journalSize = defaultJournalSize
+		}
+		s.mu.Lock()
+		view.update(soa, records, journalSize)
+		s.mu.Unlock()
+	}
+
+	return errors.Join(errs...)
+}
+
+// Serial returns the current SOA serial of the zone.
+// The second value is false if the zone isn't served or hasn't been loaded yet.
+func (s *Server) Serial(zoneName string) (uint32, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	view, ok := s.zones[rrconv.CanonicalName(zoneName)]
+	if !ok || !view.loaded {
+		return 0, false
+	}
+
+	return view.soa.Serial, true
+}
+
+// ServeDNS implements dns.Handler.
+func (s *Server) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	if len(r.Question) != 1 {
+		s.reply(w, r, dns.RcodeFormatError)
+
+		return
+	}
+	if r.IsTsig() != nil && w.TsigStatus() != nil {
+		s.reply(w, r, dns.RcodeNotAuth)
+
+		return
+	}
+	question := r.Question[0]
+
+	s.mu.RLock()
+	view := s.findZone(rrconv.CanonicalName(question.Name))
+	if view == nil || !view.loaded {
+		s.mu.RUnlock()
+		s.reply(w, r, dns.RcodeRefused)
+
+		return
+	}
+	switch question.Qtype {
+	case dns.TypeAXFR, dns.TypeIXFR:
+		rrs, rcode := s.transferRecords(w, r, view)
+		s.mu.RUnlock()
+		if rcode != dns.RcodeSuccess {
+			s.reply(w, r, rcode)
+
+			return
+		}
+		s.transfer(w, r, rrs)
+	default:
+		response := s.answer(r, view)
+		s.mu.RUnlock()
+		s.write(w, r, response)
+	}
+}
+
+// findZone returns the closest enclosing zone of the name.
+func (s *Server) findZone(name string) *zoneView {
+	for {
+		if view, ok := s.zones[name]; ok {
+			return view
+		}
+		_, parent, found := strings.Cut(name, ".")
+		if !found || parent == "" {
+			return s.zones["."]
+		}
+		name = parent
+	}
+}
+
+func (s *Server) transferRecords(w dns.ResponseWriter, r *dns.Msg, view *zoneView) ([]dns.RR, int) {
+	if !s.transferAllowed(w, r) {
+		return nil, dns.RcodeRefused
+	}
+	question := r.Question[0]
+	if rrconv.CanonicalName(question.Name) != view.name {
+		return nil, dns.RcodeNotAuth
+	}
+	if question.Qtype == dns.TypeAXFR {
+		if !isTCP(w) {
+			return nil, dns.RcodeFormatError
+		}
+
+		return view.axfr(), dns.RcodeSuccess
+	}
+
+	var clientSOA *dns.SOA
+	for _, rr := range r.Ns {
+		if soa, ok := rr.(*dns.SOA); ok {
+			clientSOA = soa
+		}
+	}
+	if clientSOA == nil {
+		return nil, dns.RcodeFormatError
+	}
+	rrs, ok := view.ixfr(clientSOA.Serial)
+	if !ok {
+		rrs = view.axfr()
+	}
+	// A response that doesn't fit a datagram is replaced with the current SOA,
+	// so the client retries over TCP as described in RFC 1995, section 2.
+	if !isTCP(w) && len(rrs) > 1 {
+		response := new(dns.Msg)
+		response.SetReply(r)
+		response.Answer = rrs
+		if response.Len() > dns.MinMsgSize {
+			rrs = []dns.RR{view.soa}
+		}
+	}
+
+	return rrs, dns.RcodeSuccess
+}
+
+func (s *Server) transferAllowed(w dns.ResponseWriter, r *dns.Msg) bool {
+	if s.RequireTSIG && r.IsTsig() == nil {
+		return false
+	}
+	addr := remoteAddr(w)
+	for _, prefix := range s.AllowTransfer {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Server) transfer(w dns.ResponseWriter, r *dns.Msg, rrs []dns.RR) {
+	envelopes := make(chan *dns.Envelope)
+	//nolint: exhaustruct
+	transfer := &dns.Transfer{}
+	done := make(chan error, 1)
+	go func() {
+		done <- transfer.Out(w, r, envelopes)
+	}()
+
+	// Out stops reading envelopes on the first write error, e.g. when the client goes away,
+	// so every send also waits for it to return.
+	var (
+		batch   []dns.RR
+		size    int
+		err     error
+		stopped bool
+	)
+	send := func(batch []dns.RR) {
+		select {
+		case envelopes <- &dns.Envelope{RR: batch, Error: nil}:
+		case err = <-done:
+			stopped = true
+		}
+	}
+	for _, rr := range rrs {
+		batch = append(batch, rr)
+		size += dns.Len(rr)
+		if size >= envelopeSize {
+			send(batch)
+			if stopped {
+				break
+			}
+			batch, size = nil, 0
+		}
+	}
+	if len(batch) > 0 && !stopped {
+		send(batch)
+	}
+	close(envelopes)
+	if !stopped {
+		err = <-done
+	}
+	if err != nil {
+		s.logf("transfer %s to %s: %v", r.Question[0].Name, w.RemoteAddr(), err)
+	}
+	_ = w.Close()
+}
+
+func (s *Server) answer(r *dns.Msg, view *zoneView) *dns.Msg {
+	response := new(dns.Msg)
+	response.SetReply(r)
+	response.Authoritative = true
+	question := r.Question[0]
+	answer, exists := view.lookup(rrconv.CanonicalName(question.Name), question.Qtype)
+	switch {
+	case len(answer) > 0:
+		response.Answer = answer
+	case !exists:
+		response.Rcode = dns.RcodeNameError
+		response.Ns = []dns.RR{view.soa}
+	default:
+		response.Ns = []dns.RR{view.soa}
+	}
+
+	return response
+}
+
+func (s *Server) reply(w dns.ResponseWriter, r *dns.Msg, rcode int) {
+	response := new(dns.Msg)
+	response.SetRcode(r, rcode)
+	s.write(w, r, response)
+}
+
+func (s *Server) write(w dns.ResponseWriter, r *dns.Msg, response *dns.Msg) {
+	if tsig := r.IsTsig(); tsig != nil && w.TsigStatus() == nil {
+		response.SetTsig(tsig.Hdr.Name, tsig.Algorithm, tsig.Fudge, time.Now().Unix())
+	}
+	if !isTCP(w) {
+		response.Truncate(dns.MinMsgSize)
+	}
+	_ = w.WriteMsg(response)
+}
+
+func (s *Server) logf(format string, args ...interface{}) {
+	if s.ErrorLog != nil {
+		s.ErrorLog.Printf(format, args...)
+	}
+}
+
+func isTCP(w dns.ResponseWriter) bool {
+	_, ok := w.RemoteAddr().(*net.TCPAddr)
+
+	return ok
+}
+
+func remoteAddr(w dns.ResponseWriter) netip.Addr {
+	var ip net.IP
+	switch addr := w.RemoteAddr().(type) {
+	case *net.TCPAddr:
+		ip = addr.IP
+	case *net.UDPAddr:
+		ip = addr.IP
+	}
+	result, _ := netip.AddrFromSlice(ip)
+
+	return result.Unmap()
+}
@@ -0,0 +1,244 @@
+package testing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/selectel/domains-go/pkg/testutils"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/xfr"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testZoneName   = "bonnie-test.com."
+	testTSIGKey    = "xfr-key."
+	testTSIGSecret = "c2VjcmV0LXRlc3Qta2V5LWZvci1ib25uaWU="
+)
+
+type (
+	// brokenWriter fails every write as if the client went away.
+	brokenWriter struct {
+		dns.ResponseWriter
+		writes int
+	}
+
+	ServerSuite struct {
+		suite.Suite
+		api    *testutils.FakeAPI
+		zone   *v2.Zone
+		server *xfr.Server
+		tcp    *dns.Server
+		udp    *dns.Server
+		addr   string
+	}
+)
+
+//nolint:paralleltest
+func TestServer(t *testing.T) {
+	suite.Run(t, new(ServerSuite))
+}
+
+func (s *ServerSuite) SetupTest() {
+	s.api = testutils.NewFakeAPI()
+	s.zone = s.api.AddZone(testZoneName)
+	s.addRRSet("bonnie-test.com.", v2.NS, "a.ns.selectel.ru.", "b.ns.selectel.ru.")
+	s.addRRSet("www.bonnie-test.com.", v2.A, "10.0.0.1")
+
+	s.server = xfr.NewServer(s.api.Client(), map[string]string{testZoneName: s.zone.ID})
+	s.server.AllowTransfer = []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}
+	s.Require().NoError(s.server.Refresh(context.Background()))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	conn, err := net.ListenPacket("udp", listener.Addr().String())
+	s.Require().NoError(err)
+	secrets := map[string]string{testTSIGKey: testTSIGSecret}
+	//nolint: exhaustruct
+	s.tcp = &dns.Server{Listener: listener, Handler: s.server, TsigSecret: secrets}
+	//nolint: exhaustruct
+	s.udp = &dns.Server{PacketConn: conn, Handler: s.server, TsigSecret: secrets}
+	for _, server := range []*dns.Server{s.tcp, s.udp} {
+		started := make(chan struct{})
+		server.NotifyStartedFunc = func() { close(started) }
+		go func(server *dns.Server) {
+			_ = server.ActivateAndServe()
+		}(server)
+		<-started
+	}
+	s.addr = listener.Addr().String()
+}
+
+func (s *ServerSuite) TearDownTest() {
+	_ = s.tcp.Shutdown()
+	_ = s.udp.Shutdown()
+	s.api.Close()
+}
+
+func (s *ServerSuite) addRRSet(name string, recordType v2.RecordType, contents ...string) {
+	s.api.AddRRSet(s.zone.ID, *testutils.NewRRSet(name, recordType, contents...))
+}
+
+func (w *brokenWriter) RemoteAddr() net.Addr {
+	//nolint: exhaustruct
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 53000}
+}
+
+func (w *brokenWriter) WriteMsg(*dns.Msg) error {
+	w.writes++
+
+	return errors.New("connection reset by peer")
+}
+
+func (w *brokenWriter) Close() error {
+	return nil
+}
+
+func (s *ServerSuite) transfer(msg *dns.Msg) ([]dns.RR, error) {
+	//nolint: exhaustruct
+	transfer := &dns.Transfer{TsigSecret: map[string]string{testTSIGKey: testTSIGSecret}}
+	envelopes, err := transfer.In(msg, s.addr)
+	if err != nil {
+		return nil, err
+	}
+	var rrs []dns.RR
+	for envelope := range envelopes {
+		if envelope.Error != nil {
+			return nil, envelope.Error
+		}
+		rrs = append(rrs, envelope.RR...)
+	}
+
+	return rrs, nil
+}
+
+func (s *ServerSuite) serial() uint32 {
+	serial, ok := s.server.Serial(testZoneName)
+	s.Require().True(ok)
+
+	return serial
+}
+
+func (s *ServerSuite) TestAXFR() {
+	msg := new(dns.Msg)
+	msg.SetAxfr(testZoneName)
+
+	rrs, err := s.transfer(msg)
+
+	s.Require().NoError(err)
+	s.Require().Len(rrs, 5)
+	s.Equal(dns.TypeSOA, rrs[0].Header().Rrtype)
+	s.Equal(dns.TypeSOA, rrs[4].Header().Rrtype)
+	s.Equal("a.ns.selectel.ru.", rrs[0].(*dns.SOA).Ns)
+	s.Equal(uint32(1), rrs[0].(*dns.SOA).Serial)
+}
+
+func (s *ServerSuite) TestIXFR_from_journal() {
+	oldSerial := s.serial()
+	s.addRRSet("mail.bonnie-test.com.", v2.MX, "10 mx.bonnie-test.com.")
+	s.Require().NoError(s.server.Refresh(context.Background()))
+	s.Equal(oldSerial+1, s.serial())
+
+	// A refresh without changes keeps the serial.
+	s.Require().NoError(s.server.Refresh(context.Background()))
+	s.Equal(oldSerial+1, s.serial())
+
+	msg := new(dns.Msg)
+	msg.SetIxfr(testZoneName, oldSerial, testZoneName, testZoneName)
+	rrs, err := s.transfer(msg)
+
+	s.Require().NoError(err)
+	// SOA(new), SOA(old), SOA(new), added MX, SOA(new).
+	s.Require().Len(rrs, 5)
+	s.Equal(oldSerial+1, rrs[0].(*dns.SOA).Serial)
+	s.Equal(oldSerial, rrs[1].(*dns.SOA).Serial)
+	s.Equal(oldSerial+1, rrs[2].(*dns.SOA).Serial)
+	s.Equal(dns.TypeMX, rrs[3].Header().Rrtype)
+}
+
+func (s *ServerSuite) TestIXFR_unknown_serial_falls_back_to_AXFR() {
+	msg := new(dns.Msg)
+	msg.SetIxfr(testZoneName, 12345, testZoneName, testZoneName)
+
+	rrs, err := s.transfer(msg)
+
+	s.Require().NoError(err)
+	s.Len(rrs, 5)
+}
+
+func (s *ServerSuite) TestTransfer_refused_by_acl() {
+	s.server.AllowTransfer = []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")}
+	msg := new(dns.Msg)
+	msg.SetAxfr(testZoneName)
+
+	_, err := s.transfer(msg)
+
+	s.Error(err)
+}
+
+func (s *ServerSuite) TestTransfer_requires_TSIG() {
+	s.server.RequireTSIG = true
+	msg := new(dns.Msg)
+	msg.SetAxfr(testZoneName)
+
+	_, err := s.transfer(msg)
+	s.Error(err)
+
+	msg = new(dns.Msg)
+	msg.SetAxfr(testZoneName)
+	msg.SetTsig(testTSIGKey, dns.HmacSHA256, 300, time.Now().Unix())
+	rrs, err := s.transfer(msg)
+
+	s.Require().NoError(err)
+	s.Len(rrs, 5)
+}
+
+func (s *ServerSuite) TestQuery_SOA_over_UDP() {
+	msg := new(dns.Msg)
+	msg.SetQuestion(testZoneName, dns.TypeSOA)
+
+	response, err := dns.Exchange(msg, s.addr)
+
+	s.Require().NoError(err)
+	s.True(response.Authoritative)
+	s.Require().Len(response.Answer, 1)
+	s.Equal(s.serial(), response.Answer[0].(*dns.SOA).Serial)
+
+	msg.SetQuestion("missing.bonnie-test.com.", dns.TypeA)
+	response, err = dns.Exchange(msg, s.addr)
+
+	s.Require().NoError(err)
+	s.Equal(dns.RcodeNameError, response.Rcode)
+}
+
+func (s *ServerSuite) TestAXFR_client_gone() {
+	// Enough records for several messages, so the transfer fails before all of them are produced.
+	for i := 0; i < 2000; i++ {
+		s.addRRSet(fmt.Sprintf("host%d.bonnie-test.com.", i), v2.TXT, `"`+strings.Repeat("x", 64)+`"`)
+	}
+	s.Require().NoError(s.server.Refresh(context.Background()))
+	msg := new(dns.Msg)
+	msg.SetAxfr(testZoneName)
+	//nolint: exhaustruct
+	writer := &brokenWriter{}
+
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		s.server.ServeDNS(writer, msg)
+	}()
+
+	select {
+	case <-served:
+	case <-time.After(5 * time.Second):
+		s.FailNow("transfer handler is stuck after the client went away")
+	}
+	s.Equal(1, writer.writes)
+}
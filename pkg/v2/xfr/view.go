@@ -0,0 +1,243 @@
+package xfr
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/miekg/dns"
+	v2 "github.com/selectel/domains-go/pkg/v2"
+	"github.com/selectel/domains-go/pkg/v2/rrconv"
+)
+
+// Default SOA timers used when the API doesn't return an SOA rrset for the zone.
+const (
+	defaultSOARefresh = 10800
+	defaultSOARetry   = 3600
+	defaultSOAExpire  = 604800
+	defaultSOAMinTTL  = 300
+	defaultSOATTL     = 3600
+)
+
+type (
+	// journalEntry represents the difference between two consecutive versions of a zone.
+	journalEntry struct {
+		from    uint32
+		to      uint32
+		deleted []dns.RR
+		added   []dns.RR
+	}
+
+	// zoneView represents the authoritative contents of a zone served by the Server.
+	zoneView struct {
+		name    string
+		id      string
+		loaded  bool
+		soa     *dns.SOA
+		records []dns.RR
+		journal []journalEntry
+	}
+)
+
+// buildRecords converts rrsets of the zone into the SOA and the sorted list of other records.
+// Rrsets that have no DNS representation are returned as skipped.
+func buildRecords(zoneName string, rrsets []*v2.RRSet) (*dns.SOA, []dns.RR, []error) {
+	var (
+		soa     *dns.SOA
+		records []dns.RR
+		skipped []error
+	)
+	for _, rrset := range rrsets {
+		rrs, err := rrconv.ToRRs(rrset)
+		if err != nil {
+			skipped = append(skipped, fmt.Errorf("rrset %s %s: %w", rrset.Name, rrset.Type, err))
+
+			continue
+		}
+		for _, rr := range rrs {
+			rr.Header().Name = rrconv.CanonicalName(rr.Header().Name)
+			if record, ok := rr.(*dns.SOA); ok {
+				if rr.Header().Name == zoneName && soa == nil {
+					soa = record
+				}
+
+				continue
+			}
+			records = append(records, rr)
+		}
+	}
+	if soa == nil {
+		soa = defaultSOA(zoneName, records)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].String() < records[j].String()
+	})
+
+	return soa, records, skipped
+}
+
+// defaultSOA synthesises an SOA record using the first apex NS record as the primary nameserver.
+func defaultSOA(zoneName string, records []dns.RR) *dns.SOA {
+	primary := zoneName
+	for _, rr := range records {
+		if ns, ok := rr.(*dns.NS); ok && ns.Hdr.Name == zoneName {
+			primary = ns.Ns
+
+			break
+		}
+	}
+
+	return &dns.SOA{
+		Hdr: dns.RR_Header{
+			Name: zoneName, Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: defaultSOATTL, Rdlength: 0,
+		},
+		Ns:      primary,
+		Mbox:    "hostmaster." + zoneName,
+		Serial:  0,
+		Refresh: defaultSOARefresh,
+		Retry:   defaultSOARetry,
+		Expire:  defaultSOAExpire,
+		Minttl:  defaultSOAMinTTL,
+	}
+}
+
+// update replaces the zone contents and bumps the serial if they have changed.
+// It reports whether the serial has been changed.
+func (v *zoneView) update(soa *dns.SOA, records []dns.RR, journalSize int) bool {
+	if !v.loaded {
+		if soa.Serial == 0 {
+			soa.Serial = 1
+		}
+		v.soa, v.records, v.loaded = soa, records, true
+
+		return true
+	}
+
+	deleted, added := diffRecords(v.records, records)
+	apiSerial := soa.Serial
+	soa.Serial = v.soa.Serial
+	if len(deleted) == 0 && len(added) == 0 && soa.String() == v.soa.String() {
+		return false
+	}
+
+	soa.Serial = nextSerial(v.soa.Serial, apiSerial)
+	v.journal = append(v.journal, journalEntry{
+		from:    v.soa.Serial,
+		to:      soa.Serial,
+		deleted: deleted,
+		added:   added,
+	})
+	if len(v.journal) > journalSize {
+		v.journal = v.journal[len(v.journal)-journalSize:]
+	}
+	v.soa, v.records = soa, records
+
+	return true
+}
+
+// axfr returns records of a full zone transfer.
+func (v *zoneView) axfr() []dns.RR {
+	rrs := make([]dns.RR, 0, len(v.records)+2)
+	rrs = append(rrs, v.soa)
+	rrs = append(rrs, v.records...)
+
+	return append(rrs, v.soa)
+}
+
+// ixfr returns records of an incremental zone transfer from the serial as described in RFC 1995.
+// The second value is false if the journal doesn't cover the serial.
+func (v *zoneView) ixfr(serial uint32) ([]dns.RR, bool) {
+	if serial == v.soa.Serial {
+		return []dns.RR{v.soa}, true
+	}
+	start := -1
+	for i, entry := range v.journal {
+		if entry.from == serial {
+			start = i
+
+			break
+		}
+	}
+	if start < 0 {
+		return nil, false
+	}
+
+	rrs := []dns.RR{v.soa}
+	for _, entry := range v.journal[start:] {
+		rrs = append(rrs, v.soaWithSerial(entry.from))
+		rrs = append(rrs, entry.deleted...)
+		rrs = append(rrs, v.soaWithSerial(entry.to))
+		rrs = append(rrs, entry.added...)
+	}
+
+	return append(rrs, v.soa), true
+}
+
+func (v *zoneView) soaWithSerial(serial uint32) *dns.SOA {
+	soa, _ := dns.Copy(v.soa).(*dns.SOA)
+	soa.Serial = serial
+
+	return soa
+}
+
+// lookup returns records of the name and type, following a CNAME at the name.
+// The second value is false if the name doesn't exist in the zone.
+func (v *zoneView) lookup(name string, rrType uint16) ([]dns.RR, bool) {
+	var (
+		answer []dns.RR
+		exists bool
+	)
+	if name == v.name {
+		exists = true
+		if rrType == dns.TypeSOA || rrType == dns.TypeANY {
+			answer = append(answer, v.soa)
+		}
+	}
+	for _, rr := range v.records {
+		if rr.Header().Name != name {
+			continue
+		}
+		exists = true
+		if rr.Header().Rrtype == rrType || rrType == dns.TypeANY || rr.Header().Rrtype == dns.TypeCNAME {
+			answer = append(answer, rr)
+		}
+	}
+
+	return answer, exists
+}
+
+func diffRecords(previous, current []dns.RR) ([]dns.RR, []dns.RR) {
+	previousIndex := make(map[string]bool, len(previous))
+	for _, rr := range previous {
+		previousIndex[rr.String()] = true
+	}
+	currentIndex := make(map[string]bool, len(current))
+	var added []dns.RR
+	for _, rr := range current {
+		currentIndex[rr.String()] = true
+		if !previousIndex[rr.String()] {
+			added = append(added, rr)
+		}
+	}
+	var deleted []dns.RR
+	for _, rr := range previous {
+		if !currentIndex[rr.String()] {
+			deleted = append(deleted, rr)
+		}
+	}
+
+	return deleted, added
+}
+
+// nextSerial returns the serial following the current one.
+// The serial returned by the API is used if it is newer in terms of RFC 1982.
+func nextSerial(current, api uint32) uint32 {
+	next := current + 1
+	if next == 0 {
+		next = 1
+	}
+	if api != 0 && int32(api-next) > 0 {
+		return api
+	}
+
+	return next
+}
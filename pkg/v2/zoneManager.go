@@ -8,27 +8,40 @@ import (
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/selectel/domains-go/pkg/idn"
 )
 
 type (
 	// Zone represents an unmarshalled zone body from API response.
 	Zone struct {
-		ID        string    `json:"id"`
-		ProjectID string    `json:"project_id"`
-		Name      string    `json:"name"`
-		Comment   string    `json:"comment"`
-		CreatedAt time.Time `json:"created_at"`
-		UpdatedAt time.Time `json:"updated_at"`
-		Disabled  bool      `json:"disabled"`
-		delegationInfo
+		ID          string    `json:"id"`
+		ProjectID   string    `json:"project_id"`
+		Name        string    `json:"name"`
+		Comment     string    `json:"comment"`
+		CreatedAt   time.Time `json:"created_at"`
+		UpdatedAt   time.Time `json:"updated_at"`
+		Disabled    bool      `json:"disabled"`
+		Protected   bool      `json:"protected"`
+		Nameservers []string  `json:"nameservers,omitempty"`
+		DelegationInfo
 	}
 
-	delegationInfo struct {
+	// DelegationInfo represents the result of the last delegation check of a zone.
+	DelegationInfo struct {
 		DelegationCheckedAt time.Time `json:"delegation_checked_at"`
 		LastDelegatedAt     time.Time `json:"last_delegated_at"`
 		LastCheckStatus     bool      `json:"last_check_status"`
 	}
 
+	// ZoneUpdateOpts represents changes applied by UpdateZone.
+	// Nil fields are left unchanged.
+	ZoneUpdateOpts struct {
+		Comment   *string
+		Disabled  *bool
+		Protected *bool
+	}
+
 	zoneCreateForm struct {
 		Name string `json:"name"`
 	}
@@ -46,7 +59,11 @@ type (
 )
 
 func (z *Zone) CreationForm() (io.Reader, error) {
-	form := zoneCreateForm{Name: z.Name}
+	name, err := idn.ToASCII(z.Name)
+	if err != nil {
+		return nil, err
+	}
+	form := zoneCreateForm{Name: name}
 	body, err := json.Marshal(form)
 
 	return bytes.NewReader(body), err
@@ -54,33 +71,17 @@ func (z *Zone) CreationForm() (io.Reader, error) {
 
 // GetZone returns a single zone by its id.
 func (c *Client) GetZone(ctx context.Context, zoneID string, _ *map[string]string) (*Zone, error) {
-	r, e := c.prepareRequest(
-		ctx, http.MethodGet, fmt.Sprintf(zonePath, zoneID), nil, nil, nil,
-	)
-
-	return processRequest[Zone](c.httpClient, r, e)
+	return getZone[Zone](ctx, c, zoneID)
 }
 
 // ListZones returns a list of zones by options.
 func (c *Client) ListZones(ctx context.Context, options *map[string]string) (Listable[Zone], error) {
-	r, e := c.prepareRequest(
-		ctx, http.MethodGet, rootPath, nil, options, nil,
-	)
-
-	return processRequest[List[Zone]](c.httpClient, r, e)
+	return listZones[Zone](ctx, c, options)
 }
 
 // CreateZone request to create of a new zone.
 func (c *Client) CreateZone(ctx context.Context, zone Creatable) (*Zone, error) {
-	body, err := zone.CreationForm()
-	if err != nil {
-		return nil, fmt.Errorf("create zone: %w", err)
-	}
-	r, e := c.prepareRequest(
-		ctx, http.MethodPost, rootPath, body, nil, nil,
-	)
-
-	return processRequest[Zone](c.httpClient, r, e)
+	return createZone[Zone](ctx, c, zone)
 }
 
 // DeleteZone request to delete of the zone by id.
@@ -143,3 +144,60 @@ func (c *Client) UpdateProtectionState(ctx context.Context, zoneID string, prote
 
 	return err
 }
+
+// UpdateZone applies comment, state and protection changes of the zone,
+// issuing a request only for the fields that are set in opts.
+func UpdateZone[Z any](ctx context.Context, manager ZoneManager[Z], zoneID string, opts *ZoneUpdateOpts) error {
+	if opts == nil {
+		return nil
+	}
+	if opts.Comment != nil {
+		if err := manager.UpdateZoneComment(ctx, zoneID, *opts.Comment); err != nil {
+			return fmt.Errorf("update zone comment: %w", err)
+		}
+	}
+	if opts.Disabled != nil {
+		if err := manager.UpdateZoneState(ctx, zoneID, *opts.Disabled); err != nil {
+			return fmt.Errorf("update zone state: %w", err)
+		}
+	}
+	if opts.Protected != nil {
+		if err := manager.UpdateProtectionState(ctx, zoneID, *opts.Protected); err != nil {
+			return fmt.Errorf("update zone protection: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func getZone[Z any](ctx context.Context, c *Client, zoneID string) (*Z, error) {
+	r, e := c.prepareRequest(
+		ctx, http.MethodGet, fmt.Sprintf(zonePath, zoneID), nil, nil, nil,
+	)
+
+	return processRequest[Z](c.httpClient, r, e)
+}
+
+func listZones[Z any](ctx context.Context, c *Client, options *map[string]string) (Listable[Z], error) {
+	options, err := asciiOptions(options, "filter")
+	if err != nil {
+		return nil, err
+	}
+	r, e := c.prepareRequest(
+		ctx, http.MethodGet, rootPath, nil, options, nil,
+	)
+
+	return processRequest[List[Z]](c.httpClient, r, e)
+}
+
+func createZone[Z any](ctx context.Context, c *Client, zone Creatable) (*Z, error) {
+	body, err := zone.CreationForm()
+	if err != nil {
+		return nil, fmt.Errorf("create zone: %w", err)
+	}
+	r, e := c.prepareRequest(
+		ctx, http.MethodPost, rootPath, body, nil, nil,
+	)
+
+	return processRequest[Z](c.httpClient, r, e)
+}